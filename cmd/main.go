@@ -4,6 +4,7 @@ import (
 	"codebase-api/config"
 	"codebase-api/config/rabbitmq"
 	"codebase-api/config/storage"
+	"codebase-api/internal/handler"
 	middleware "codebase-api/pkg/middlewares"
 	"codebase-api/router"
 	"log"
@@ -34,6 +35,10 @@ func main() {
 	// Initialize Fiber app
 	app := fiber.New(fiber.Config{
 		AppName: "expense-api v1.0.1",
+		// Dinaikkan dari default 4MB agar file bulk import user bisa diunggah
+		BodyLimit: 32 * 1024 * 1024,
+		// Error yang dikembalikan handler dipetakan ke status HTTP di satu tempat
+		ErrorHandler: handler.ErrorHandler,
 	})
 
 	// Initialize connecting and channel RabbitMQ
@@ -59,6 +64,8 @@ func main() {
 	// Cors
 	app.Use(cors.New(cors.Config{
 		AllowOrigins: "*",
+		// Client perlu membaca ETag untuk dikirim kembali sebagai If-Match
+		ExposeHeaders: fiber.HeaderETag + ", " + fiber.HeaderLastModified,
 	}))
 
 	app.Use(healthcheck.New(healthcheck.Config{
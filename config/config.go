@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strings"
 
+	"github.com/spf13/cast"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 )
@@ -22,8 +24,12 @@ func InitDB() *gorm.DB {
 	if err != nil {
 		log.Fatal("Failed to connect to database:", err)
 	}
+	// Migration data satu kali harus selesai sebelum AutoMigrate membuat unique index baru
+	if err := runMigrations(db, migrations); err != nil {
+		log.Fatal("Failed to migrate database: ", err)
+	}
 	// Optional: migrasikan schema jika perlu
-	db.Set("gorm:table_options", "ENGINE=InnoDB").AutoMigrate(&domain.User{})
+	db.Set("gorm:table_options", "ENGINE=InnoDB").AutoMigrate(&domain.User{}, &domain.Passkey{}, &domain.Group{}, &domain.GroupMember{}, &domain.ErasureRecord{})
 	return db
 }
 
@@ -34,3 +40,41 @@ func GetDBDSN() string {
 func GetJWTSecret() string {
 	return os.Getenv("JWT_SECRET")
 }
+
+// GetScimTokens membaca SCIM_TOKENS berformat "tenant:token,tenant2:token2" menjadi map token -> tenant
+func GetScimTokens() map[string]string {
+	tokens := make(map[string]string)
+	for _, pair := range strings.Split(os.Getenv("SCIM_TOKENS"), ",") {
+		tenant, token, ok := strings.Cut(strings.TrimSpace(pair), ":")
+		if !ok || tenant == "" || token == "" {
+			continue
+		}
+		tokens[token] = tenant
+	}
+	return tokens
+}
+
+// LegacyNumericIDs mengizinkan ID numerik pada path dan response selama masa migrasi ke UUID
+func LegacyNumericIDs() bool {
+	return cast.ToBool(os.Getenv("LEGACY_NUMERIC_IDS"))
+}
+
+// GetAppURL adalah URL frontend yang dipakai untuk link di notifikasi (konfirmasi email, dsb)
+func GetAppURL() string {
+	return strings.TrimRight(getEnv("APP_URL", "http://localhost:3000"), "/")
+}
+
+// GetNotificationQueue adalah queue RabbitMQ yang dikonsumsi worker pengirim email / SMS
+func GetNotificationQueue() string {
+	return getEnv("NOTIFICATION_QUEUE", "notifications")
+}
+
+// GetSMSLogFile adalah file tujuan LogSender; kosong berarti ditulis ke stdout
+func GetSMSLogFile() string {
+	return os.Getenv("SMS_LOG_FILE")
+}
+
+// GetTOTPIssuer adalah nama aplikasi yang tampil di aplikasi authenticator
+func GetTOTPIssuer() string {
+	return getEnv("TOTP_ISSUER", "codebase-api")
+}
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"os"
+	"strings"
+
+	"github.com/spf13/cast"
+)
+
+type LDAPConfig struct {
+	URL          string
+	StartTLS     bool
+	BindDN       string
+	BindPassword string
+	BaseDN       string
+	// UserFilter berisi %s yang diganti dengan username (sudah di-escape)
+	UserFilter string
+
+	UsernameAttribute  string
+	EmailAttribute     string
+	FirstNameAttribute string
+	LastNameAttribute  string
+	PhoneAttribute     string
+	GroupAttribute     string
+
+	// GroupRoles memetakan DN group LDAP (huruf kecil) ke role aplikasi
+	GroupRoles map[string]string
+}
+
+// GetLDAPConfig membaca konfigurasi LDAP; ok bernilai false jika LDAP_URL tidak di-set
+func GetLDAPConfig() (LDAPConfig, bool) {
+	cfg := LDAPConfig{
+		URL:                os.Getenv("LDAP_URL"),
+		StartTLS:           cast.ToBool(os.Getenv("LDAP_START_TLS")),
+		BindDN:             os.Getenv("LDAP_BIND_DN"),
+		BindPassword:       os.Getenv("LDAP_BIND_PASSWORD"),
+		BaseDN:             os.Getenv("LDAP_BASE_DN"),
+		UserFilter:         getEnv("LDAP_USER_FILTER", "(&(objectClass=person)(uid=%s))"),
+		UsernameAttribute:  getEnv("LDAP_ATTR_USERNAME", "uid"),
+		EmailAttribute:     getEnv("LDAP_ATTR_EMAIL", "mail"),
+		FirstNameAttribute: getEnv("LDAP_ATTR_FIRST_NAME", "givenName"),
+		LastNameAttribute:  getEnv("LDAP_ATTR_LAST_NAME", "sn"),
+		PhoneAttribute:     getEnv("LDAP_ATTR_PHONE", "telephoneNumber"),
+		GroupAttribute:     getEnv("LDAP_ATTR_GROUPS", "memberOf"),
+		GroupRoles:         make(map[string]string),
+	}
+
+	// LDAP_GROUP_ROLES berformat "cn=admins,ou=groups,dc=example,dc=com|admin;cn=staff,...|staff"
+	for _, pair := range strings.Split(os.Getenv("LDAP_GROUP_ROLES"), ";") {
+		group, role, ok := strings.Cut(strings.TrimSpace(pair), "|")
+		if !ok || group == "" || role == "" {
+			continue
+		}
+		cfg.GroupRoles[strings.ToLower(strings.TrimSpace(group))] = strings.TrimSpace(role)
+	}
+
+	return cfg, cfg.URL != ""
+}
+
+func getEnv(key string, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,175 @@
+package config
+
+import (
+	"codebase-api/internal/domain"
+	"codebase-api/pkg/identity"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// migration adalah perubahan schema / data yang hanya dijalankan sekali. Migration harus aman
+// dijalankan ulang karena DDL di MySQL tidak ikut di-rollback bersama transaksinya
+type migration struct {
+	ID  string
+	Run func(tx *gorm.DB) error
+}
+
+// schemaMigration mencatat migration yang sudah selesai
+type schemaMigration struct {
+	ID        string `gorm:"primaryKey;type:varchar(100)"`
+	AppliedAt time.Time
+}
+
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// migrations dijalankan berurutan sebelum AutoMigrate; tambahkan entry baru di akhir
+var migrations = []migration{
+	{ID: "20260601_user_identity_canonical", Run: migrateUserIdentity},
+	{ID: "20260615_purge_soft_deleted_passkeys", Run: purgeDeletedPasskeys},
+	{ID: "20260620_drop_legacy_user_unique_indexes", Run: dropLegacyUserUniqueIndexes},
+}
+
+// runMigrations menjalankan migration yang belum tercatat di schema_migrations
+func runMigrations(db *gorm.DB, migrations []migration) error {
+	if err := db.AutoMigrate(&schemaMigration{}); err != nil {
+		return err
+	}
+
+	for _, m := range migrations {
+		var applied int64
+		if err := db.Model(&schemaMigration{}).Where("id = ?", m.ID).Count(&applied).Error; err != nil {
+			return err
+		}
+		if applied > 0 {
+			continue
+		}
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := m.Run(tx); err != nil {
+				return err
+			}
+			return tx.Create(&schemaMigration{ID: m.ID, AppliedAt: time.Now()}).Error
+		})
+		if err != nil {
+			return fmt.Errorf("migration %s: %w", m.ID, err)
+		}
+	}
+	return nil
+}
+
+// migrateUserIdentity menyiapkan kolom kanonik untuk tabel users yang dibuat sebelum kolom tersebut ada:
+// menghapus kolom generated lama (unique per nilai mentah), menambah kolom kanonik lalu mengisinya.
+// Dijalankan sebelum AutoMigrate membuat unique index kanonik; jika ada user aktif yang bentrok
+// (mis. "Budi" dan "budi") migration gagal dan daftar bentrokannya harus dibereskan manual dulu
+func migrateUserIdentity(tx *gorm.DB) error {
+	migrator := tx.Migrator()
+	if !migrator.HasTable(&domain.User{}) {
+		// Database baru: AutoMigrate membuat schema lengkap
+		return nil
+	}
+
+	for _, column := range []string{"active_email", "active_phone"} {
+		if migrator.HasColumn(&domain.User{}, column) {
+			if err := migrator.DropColumn(&domain.User{}, column); err != nil {
+				return err
+			}
+		}
+	}
+	for _, field := range []string{"UsernameCanonical", "EmailCanonical", "PhoneCanonical"} {
+		if !migrator.HasColumn(&domain.User{}, field) {
+			if err := migrator.AddColumn(&domain.User{}, field); err != nil {
+				return err
+			}
+		}
+	}
+
+	var users []struct {
+		ID        uint
+		Username  string
+		Email     string
+		Phone     *string
+		DeletedAt gorm.DeletedAt
+	}
+	if err := tx.Table("users").Select("id, username, email, phone, deleted_at").Order("id").Scan(&users).Error; err != nil {
+		return err
+	}
+
+	// owners[field][nilai kanonik] berisi ID user aktif yang memakai nilai tersebut
+	owners := map[string]map[string][]uint{"username": {}, "email": {}, "phone": {}}
+	updates := make(map[uint]map[string]interface{}, len(users))
+	for _, user := range users {
+		values := map[string]interface{}{
+			"username_canonical": identity.Username(user.Username),
+			"email_canonical":    identity.Email(user.Email),
+			"phone_canonical":    nil,
+		}
+		if user.Phone != nil && identity.Phone(*user.Phone) != "" {
+			values["phone_canonical"] = identity.Phone(*user.Phone)
+		}
+		updates[user.ID] = values
+
+		// User yang sudah di-soft delete tidak ikut unique index
+		if user.DeletedAt.Valid {
+			continue
+		}
+		for field, value := range map[string]interface{}{"username": values["username_canonical"], "email": values["email_canonical"], "phone": values["phone_canonical"]} {
+			if value, ok := value.(string); ok && value != "" {
+				owners[field][value] = append(owners[field][value], user.ID)
+			}
+		}
+	}
+
+	var collisions []string
+	for field, values := range owners {
+		for value, ids := range values {
+			if len(ids) > 1 {
+				collisions = append(collisions, fmt.Sprintf("%s %q is used by users %v", field, value, ids))
+			}
+		}
+	}
+	if len(collisions) > 0 {
+		sort.Strings(collisions)
+		return fmt.Errorf("resolve duplicate identities before the unique indexes can be created:\n  %s", strings.Join(collisions, "\n  "))
+	}
+
+	for _, user := range users {
+		if err := tx.Table("users").Where("id = ?", user.ID).UpdateColumns(updates[user.ID]).Error; err != nil {
+			return fmt.Errorf("canonicalize user %d: %w", user.ID, err)
+		}
+	}
+	return nil
+}
+
+// purgeDeletedPasskeys menghapus permanen passkey yang dulu di-soft delete; baris tersebut
+// memblokir pendaftaran ulang authenticator yang sama lewat unique index credential_id
+func purgeDeletedPasskeys(tx *gorm.DB) error {
+	if !tx.Migrator().HasTable(&domain.Passkey{}) {
+		return nil
+	}
+	return tx.Unscoped().Where("deleted_at IS NOT NULL").Delete(&domain.Passkey{}).Error
+}
+
+// dropLegacyUserUniqueIndexes menghapus unique constraint dari schema awal pada email dan phone (di MySQL
+// index-nya bernama sama dengan kolom). Constraint itu juga berlaku untuk user yang di-soft delete sehingga
+// email / phone mereka tidak bisa dipakai ulang; keunikan kini dijaga unique index kanonik
+func dropLegacyUserUniqueIndexes(tx *gorm.DB) error {
+	migrator := tx.Migrator()
+	if !migrator.HasTable(&domain.User{}) {
+		return nil
+	}
+
+	for _, index := range []string{"email", "phone"} {
+		if migrator.HasIndex(&domain.User{}, index) {
+			if err := migrator.DropIndex(&domain.User{}, index); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
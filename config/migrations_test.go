@@ -0,0 +1,130 @@
+package config
+
+import (
+	"codebase-api/internal/testdb"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+// openLegacyUsers membuat tabel users seperti sebelum kolom kanonik ditambahkan
+func openLegacyUsers(t *testing.T, rows ...string) *gorm.DB {
+	t.Helper()
+
+	db := testdb.OpenEmpty(t)
+	statements := append([]string{"CREATE TABLE `users` (" +
+		"`id` integer PRIMARY KEY, `username` varchar(150), `email` varchar(100), `phone` varchar(100), " +
+		"`deleted_at` datetime, `active_email` varchar(100), `active_phone` varchar(100))"}, rows...)
+	for _, statement := range statements {
+		if err := db.Exec(statement).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	return db
+}
+
+type canonicalRow struct {
+	ID                uint
+	UsernameCanonical string
+	EmailCanonical    string
+	PhoneCanonical    *string
+}
+
+func TestMigrateUserIdentityBackfillsOnce(t *testing.T) {
+	db := openLegacyUsers(t,
+		`INSERT INTO users (id, username, email, phone) VALUES (1, ' Budi ', 'Budi@Example.com', '+62 811-1111-111')`,
+		`INSERT INTO users (id, username, email, phone) VALUES (2, 'siti', 'siti@example.com', NULL)`,
+		// User terhapus boleh memakai identitas yang sama dengan user aktif
+		`INSERT INTO users (id, username, email, phone, deleted_at) VALUES (3, 'BUDI', 'budi@example.com', NULL, '2026-01-01 00:00:00')`,
+	)
+
+	if err := runMigrations(db, migrations); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	var rows []canonicalRow
+	db.Table("users").Order("id").Find(&rows)
+	if len(rows) != 3 || rows[0].UsernameCanonical != "budi" || rows[0].EmailCanonical != "budi@example.com" ||
+		rows[0].PhoneCanonical == nil || rows[1].UsernameCanonical != "siti" || rows[1].PhoneCanonical != nil ||
+		rows[2].UsernameCanonical != "budi" {
+		t.Fatalf("unexpected canonical values: %+v", rows)
+	}
+	if db.Migrator().HasColumn("users", "active_email") || db.Migrator().HasColumn("users", "active_phone") {
+		t.Fatal("legacy generated columns were not dropped")
+	}
+
+	// Migration yang sudah tercatat tidak dijalankan lagi saat startup berikutnya
+	db.Exec("UPDATE users SET username_canonical = '' WHERE id = 2")
+	if err := runMigrations(db, migrations); err != nil {
+		t.Fatalf("second run: %v", err)
+	}
+	var canonical string
+	db.Table("users").Select("username_canonical").Where("id = 2").Scan(&canonical)
+	if canonical != "" {
+		t.Fatal("migration ran again after it was recorded")
+	}
+}
+
+func TestMigrateUserIdentityFailsOnCollision(t *testing.T) {
+	db := openLegacyUsers(t,
+		`INSERT INTO users (id, username, email) VALUES (1, 'Budi', 'budi@example.com')`,
+		`INSERT INTO users (id, username, email) VALUES (2, 'budi', 'other@example.com')`,
+	)
+
+	err := runMigrations(db, migrations)
+	if err == nil || !strings.Contains(err.Error(), `username "budi" is used by users [1 2]`) {
+		t.Fatalf("err = %v, want username collision report", err)
+	}
+
+	// Tidak ada baris yang setengah termigrasi dan migration belum tercatat
+	var filled int64
+	db.Table("users").Where("username_canonical <> ''").Count(&filled)
+	if filled != 0 {
+		t.Fatalf("%d users were canonicalized despite the collision", filled)
+	}
+	var applied int64
+	db.Model(&schemaMigration{}).Count(&applied)
+	if applied != 0 {
+		t.Fatalf("%d migrations recorded, want 0", applied)
+	}
+
+	// Setelah bentrokan dibereskan migration berhasil
+	db.Exec("UPDATE users SET username = 'budi2' WHERE id = 2")
+	if err := runMigrations(db, migrations); err != nil {
+		t.Fatalf("migrate after fixing collision: %v", err)
+	}
+}
+
+func TestMigrationsSkipFreshDatabase(t *testing.T) {
+	db := testdb.OpenEmpty(t)
+	if err := runMigrations(db, migrations); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	if db.Migrator().HasTable("users") {
+		t.Fatal("migration should leave a fresh database to AutoMigrate")
+	}
+}
+
+func TestMigrationsDropLegacyUniqueIndexes(t *testing.T) {
+	db := openLegacyUsers(t,
+		"CREATE UNIQUE INDEX `email` ON `users` (`email`)",
+		"CREATE UNIQUE INDEX `phone` ON `users` (`phone`)",
+		`INSERT INTO users (id, username, email, phone, deleted_at) VALUES (1, 'budi', 'budi@example.com', '+6281111111111', '2026-01-01 00:00:00')`,
+	)
+
+	if err := runMigrations(db, migrations); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	for _, index := range []string{"email", "phone"} {
+		if db.Migrator().HasIndex("users", index) {
+			t.Fatalf("legacy unique index %s was not dropped", index)
+		}
+	}
+
+	// Email dan phone milik user yang sudah dihapus bisa dipakai lagi
+	err := db.Exec(`INSERT INTO users (id, username, email, phone) VALUES (2, 'budi2', 'budi@example.com', '+6281111111111')`).Error
+	if err != nil {
+		t.Fatalf("reuse identity of deleted user: %v", err)
+	}
+}
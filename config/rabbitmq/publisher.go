@@ -0,0 +1,41 @@
+package rabbitmq
+
+import (
+	"github.com/streadway/amqp"
+)
+
+// Publisher mengirim pesan JSON ke satu queue; berbeda dengan PublishMessage, error dikembalikan
+// ke pemanggil dan tidak menghentikan proses
+type Publisher struct {
+	ch    *amqp.Channel
+	queue string
+}
+
+func NewPublisher(ch *amqp.Channel, queue string) *Publisher {
+	return &Publisher{ch: ch, queue: queue}
+}
+
+func (p *Publisher) Publish(body []byte) error {
+	q, err := p.ch.QueueDeclare(
+		p.queue, // Nama queue
+		false,   // Durable
+		false,   // Auto-delete
+		false,   // Exclusive
+		false,   // No-wait
+		nil,     // Arguments
+	)
+	if err != nil {
+		return err
+	}
+
+	return p.ch.Publish(
+		"",     // Exchange
+		q.Name, // Routing key (nama queue)
+		false,  // Mandatory
+		false,  // Immediate
+		amqp.Publishing{
+			ContentType: "application/json",
+			Body:        body,
+		},
+	)
+}
@@ -0,0 +1,13 @@
+package config
+
+import "strings"
+
+// GetUploadDir adalah direktori lokal untuk file yang diunggah user (avatar, dsb)
+func GetUploadDir() string {
+	return getEnv("UPLOAD_DIR", "./storage/uploads")
+}
+
+// UploadURL membentuk URL publik untuk key file upload; UPLOAD_BASE_URL bisa diarahkan ke CDN
+func UploadURL(key string) string {
+	return strings.TrimRight(getEnv("UPLOAD_BASE_URL", "/uploads"), "/") + "/" + key
+}
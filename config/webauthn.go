@@ -0,0 +1,42 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strings"
+
+	"github.com/go-webauthn/webauthn/webauthn"
+)
+
+// GetWebAuthnConfig membaca konfigurasi passkey; ok bernilai false jika WEBAUTHN_RP_ID atau
+// WEBAUTHN_RP_ORIGINS tidak di-set sehingga endpoint passkey tidak didaftarkan
+func GetWebAuthnConfig() (webauthn.Config, bool) {
+	displayName := os.Getenv("WEBAUTHN_RP_NAME")
+	if displayName == "" {
+		displayName = "codebase-api"
+	}
+
+	var origins []string
+	for _, origin := range strings.Split(os.Getenv("WEBAUTHN_RP_ORIGINS"), ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			origins = append(origins, origin)
+		}
+	}
+
+	cfg := webauthn.Config{
+		RPID:          os.Getenv("WEBAUTHN_RP_ID"),
+		RPDisplayName: displayName,
+		RPOrigins:     origins,
+	}
+	return cfg, cfg.RPID != "" && len(origins) > 0
+}
+
+// InitWebAuthn membuat relying party dari konfigurasi yang sudah di-set; konfigurasi yang
+// di-set tapi tidak valid menghentikan server
+func InitWebAuthn(cfg webauthn.Config) *webauthn.WebAuthn {
+	wa, err := webauthn.New(&cfg)
+	if err != nil {
+		log.Fatal("Failed to initialize WebAuthn:", err)
+	}
+	return wa
+}
@@ -8,11 +8,13 @@ import (
 )
 
 type BaseDomain struct {
-	ID        uint       `gorm:"primary_key;autoIncrement" json:"id"`
-	UUID      uuid.UUID  `gorm:"type:char(36)" json:"uuid"`
-	CreatedAt time.Time  `gorm:"autoCreateTime" json:"created_at"`
-	UpdatedAt time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
-	DeletedAt *time.Time `gorm:"index" json:"deleted_at,omitempty"`
+	ID        uint           `gorm:"primary_key;autoIncrement" json:"id"`
+	UUID      uuid.UUID      `gorm:"type:char(36)" json:"uuid"`
+	CreatedAt time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+	// Version naik setiap kali entity diubah; dipakai untuk optimistic locking dan ETag
+	Version uint `gorm:"not null;default:1" json:"version"`
 }
 
 // Hook BeforeCreate untuk menggenerate UUID sebelum entri data
@@ -21,5 +23,19 @@ func (b *BaseDomain) BeforeCreate(tx *gorm.DB) (err error) {
 	if b.UUID == uuid.Nil {
 		b.UUID = uuid.New()
 	}
+	// Default kolom di database tidak terbaca balik oleh GORM, jadi version awal di-set di sini
+	if b.Version == 0 {
+		b.Version = 1
+	}
 	return
 }
+
+// SetVersion dipakai repository untuk menaikkan (atau mengembalikan) version saat update
+func (b *BaseDomain) SetVersion(version uint) {
+	b.Version = version
+}
+
+// Base mengembalikan BaseDomain dari domain yang meng-embed-nya (dipakai untuk cursor pagination)
+func (b BaseDomain) Base() BaseDomain {
+	return b
+}
@@ -0,0 +1,18 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErasureRecord mencatat bahwa data pribadi user telah dihapus (right-to-erasure).
+// Tidak berisi PII; UserUUID tetap disimpan agar permintaan bisa ditelusuri
+type ErasureRecord struct {
+	BaseDomain
+	UserID      uint      `gorm:"column:user_id;index;not null" json:"user_id"`
+	UserUUID    uuid.UUID `gorm:"type:char(36);column:user_uuid;not null" json:"user_uuid"`
+	RequestedBy uint      `gorm:"column:requested_by;not null" json:"requested_by"`
+	Reason      string    `gorm:"type:text;column:reason" json:"reason"`
+	ErasedAt    time.Time `gorm:"column:erased_at;not null" json:"erased_at"`
+}
@@ -0,0 +1,92 @@
+package domain
+
+import "errors"
+
+// Kategori error domain; handler memetakan setiap kategori ke satu HTTP status
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrConflict     = errors.New("conflict")
+	ErrValidation   = errors.New("validation failed")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrPrecondition = errors.New("precondition failed")
+	ErrRateLimited  = errors.New("too many requests")
+	ErrTooLarge     = errors.New("payload too large")
+	ErrUnsupported  = errors.New("unsupported media type")
+)
+
+// Error adalah error domain dengan kategori (salah satu Err* di atas) dan pesan yang aman dikirim ke client.
+// Fields berisi pesan per field untuk Conflict / Validation; Err adalah penyebab asli (tidak dikirim ke client)
+type Error struct {
+	Kind    error
+	Message string
+	Fields  map[string]string
+	Err     error
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Is membuat errors.Is(err, domain.ErrNotFound) bernilai true untuk setiap error NotFound
+func (e *Error) Is(target error) bool {
+	return target == e.Kind
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func NotFound(message string) *Error {
+	return &Error{Kind: ErrNotFound, Message: message}
+}
+
+// Conflict menandakan nilai unik sudah dipakai; field boleh kosong bila konflik bukan pada satu field
+func Conflict(field string, message string) *Error {
+	err := &Error{Kind: ErrConflict, Message: message}
+	if field != "" {
+		err.Fields = map[string]string{field: message}
+	}
+	return err
+}
+
+func Invalid(field string, message string) *Error {
+	return InvalidFields(message, map[string]string{field: message})
+}
+
+// InvalidFields dipakai bila beberapa field sekaligus tidak valid
+func InvalidFields(message string, fields map[string]string) *Error {
+	return &Error{Kind: ErrValidation, Message: message, Fields: fields}
+}
+
+func Unauthorized(message string) *Error {
+	return &Error{Kind: ErrUnauthorized, Message: message}
+}
+
+func Forbidden(message string) *Error {
+	return &Error{Kind: ErrForbidden, Message: message}
+}
+
+func RateLimited(message string) *Error {
+	return &Error{Kind: ErrRateLimited, Message: message}
+}
+
+func TooLarge(message string) *Error {
+	return &Error{Kind: ErrTooLarge, Message: message}
+}
+
+// Unsupported menandakan isi upload bukan tipe media yang diterima
+func Unsupported(message string) *Error {
+	return &Error{Kind: ErrUnsupported, Message: message}
+}
+
+// ErrStaleVersion dikembalikan repository bila baris sudah diubah request lain sejak dibaca
+var ErrStaleVersion = Conflict("", "resource was modified by another request")
+
+// ErrVersionMismatch dikembalikan bila version dari client (If-Match) bukan version terbaru
+var ErrVersionMismatch = &Error{Kind: ErrPrecondition, Message: "resource version does not match If-Match"}
+
+// FieldConflict adalah Conflict standar untuk username, email atau phone yang sudah dipakai
+func FieldConflict(field string) *Error {
+	return Conflict(field, field+" is already in use")
+}
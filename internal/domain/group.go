@@ -0,0 +1,29 @@
+package domain
+
+const (
+	GroupRoleOwner  = "owner"
+	GroupRoleMember = "member"
+)
+
+// Group adalah tim / kelompok user untuk permission dan reporting
+type Group struct {
+	BaseDomain
+	Name        string `gorm:"type:varchar(150);column:name;not null" json:"name"`
+	Description string `gorm:"type:text;column:description" json:"description"`
+}
+
+// Nama tabel "groups" adalah reserved word di MySQL 8
+func (Group) TableName() string {
+	return "user_groups"
+}
+
+// GroupMember adalah keanggotaan user di sebuah group beserta perannya (owner, member).
+// Keanggotaan dihapus permanen agar user bisa ditambahkan kembali tanpa bentrok unique index
+type GroupMember struct {
+	BaseDomain
+	GroupID uint   `gorm:"column:group_id;not null;uniqueIndex:idx_group_members_group_user" json:"group_id"`
+	UserID  uint   `gorm:"column:user_id;not null;uniqueIndex:idx_group_members_group_user;index" json:"user_id"`
+	Role    string `gorm:"type:varchar(20);column:role;not null;default:member" json:"role"`
+	Group   *Group `gorm:"foreignKey:GroupID" json:"-"`
+	User    *User  `gorm:"foreignKey:UserID" json:"-"`
+}
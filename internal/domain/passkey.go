@@ -0,0 +1,19 @@
+package domain
+
+import "time"
+
+// Passkey adalah credential WebAuthn milik user
+type Passkey struct {
+	BaseDomain
+	UserID          uint       `gorm:"column:user_id;index;not null" json:"user_id"`
+	Name            string     `gorm:"type:varchar(100);column:name;not null" json:"name"`
+	CredentialID    []byte     `gorm:"type:varbinary(255);column:credential_id;uniqueIndex;not null" json:"-"`
+	PublicKey       []byte     `gorm:"type:blob;column:public_key;not null" json:"-"`
+	AttestationType string     `gorm:"type:varchar(50);column:attestation_type" json:"attestation_type"`
+	AAGUID          []byte     `gorm:"type:varbinary(16);column:aaguid" json:"-"`
+	SignCount       uint32     `gorm:"column:sign_count;not null;default:0" json:"sign_count"`
+	Transports      string     `gorm:"type:varchar(255);column:transports" json:"transports"`
+	BackupEligible  bool       `gorm:"column:backup_eligible" json:"backup_eligible"`
+	BackupState     bool       `gorm:"column:backup_state" json:"backup_state"`
+	LastUsedAt      *time.Time `gorm:"column:last_used_at" json:"last_used_at"`
+}
@@ -1,13 +1,103 @@
 package domain
 
+import (
+	"codebase-api/pkg/identity"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+
+	AuthSourceLocal = "local"
+	AuthSourceLDAP  = "ldap"
+)
+
+// AvatarSizes adalah ukuran thumbnail persegi (pixel) yang dibuat untuk setiap avatar
+var AvatarSizes = []int{64, 128, 256, 512}
+
 type User struct {
 	BaseDomain
-	FirstName    string `gorm:"type:varchar(150);column:first_name;not null" json:"first_name"`
-	LastName     string `gorm:"type:varchar(150);column:last_name;not null" json:"last_name"`
-	Username     string `gorm:"type:varchar(150);column:username;not null" json:"username"`
-	Email        string `gorm:"type:varchar(100);unique;not null" json:"email"`
-	Password     string `gorm:"type:varchar(150);column:password;not null" json:"password"`
-	Phone        string `gorm:"type:varchar(100);unique" json:"phone"`
-	IsActive     bool   `gorm:"default:true;column:is_active" json:"is_active"`
-	RefreshToken string `gorm:"type:text;column:refresh_token" json:"refresh_token"`
+	// idx_users_search adalah index FULLTEXT untuk pencarian user
+	FirstName string  `gorm:"type:varchar(150);column:first_name;not null;index:idx_users_search,class:FULLTEXT" json:"first_name"`
+	LastName  string  `gorm:"type:varchar(150);column:last_name;not null;index:idx_users_search,class:FULLTEXT" json:"last_name"`
+	Username  string  `gorm:"type:varchar(150);column:username;not null;index:idx_users_search,class:FULLTEXT" json:"username"`
+	Email     string  `gorm:"type:varchar(100);not null;index:idx_users_search,class:FULLTEXT" json:"email"`
+	Password  string  `gorm:"type:varchar(150);column:password;not null" json:"password"`
+	Phone     *string `gorm:"type:varchar(100);index:idx_users_search,class:FULLTEXT" json:"phone"`
+	IsActive  bool    `gorm:"default:true;column:is_active" json:"is_active"`
+	// EmailVerifiedAt nil berarti email belum diverifikasi
+	EmailVerifiedAt *time.Time `gorm:"column:email_verified_at" json:"email_verified_at"`
+	// PhoneVerifiedAt nil berarti nomor telepon belum diverifikasi lewat OTP
+	PhoneVerifiedAt *time.Time `gorm:"column:phone_verified_at" json:"phone_verified_at"`
+	RefreshToken    string     `gorm:"type:text;column:refresh_token" json:"refresh_token"`
+	Role            string     `gorm:"type:varchar(50);column:role;not null;default:user" json:"role"`
+	// AuthSource menentukan authenticator yang memverifikasi password user (local, ldap)
+	AuthSource string `gorm:"type:varchar(20);column:auth_source;not null;default:local" json:"auth_source"`
+	// Diisi saat user dibuat lewat SCIM provisioning
+	ExternalID         *string `gorm:"type:varchar(255);column:external_id" json:"external_id"`
+	ProvisioningTenant *string `gorm:"type:varchar(100);column:provisioning_tenant;index" json:"provisioning_tenant"`
+	// AvatarPath adalah prefix key file avatar; setiap ukuran disimpan di <AvatarPath>/<size>.jpg
+	AvatarPath *string `gorm:"type:varchar(255);column:avatar_path" json:"avatar_path"`
+	// ErasedAt terisi setelah PII user dianonimkan; baris user tetap ada untuk integritas referensi
+	ErasedAt *time.Time `gorm:"column:erased_at" json:"erased_at"`
+	// Preferences menyimpan preferensi yang berbeda dari default dalam bentuk JSON
+	Preferences []byte `gorm:"type:json;column:preferences" json:"-"`
+	// TOTPSecret adalah secret authenticator (base32); TOTPEnabledAt nil berarti enrollment belum dikonfirmasi
+	TOTPSecret    *string    `gorm:"type:varchar(64);column:totp_secret" json:"-"`
+	TOTPEnabledAt *time.Time `gorm:"column:totp_enabled_at" json:"totp_enabled_at"`
+	// SessionVersion ikut disimpan di JWT; menaikkannya membatalkan semua token yang sudah diterbitkan
+	SessionVersion uint `gorm:"column:session_version;not null;default:0" json:"-"`
+
+	// Bentuk kanonik (trim, NFKC, case folding) untuk pencarian dan keunikan; diisi oleh BeforeSave
+	UsernameCanonical string  `gorm:"type:varchar(150);column:username_canonical;not null;default:''" json:"-"`
+	EmailCanonical    string  `gorm:"type:varchar(100);column:email_canonical;not null;default:''" json:"-"`
+	PhoneCanonical    *string `gorm:"type:varchar(100);column:phone_canonical" json:"-"`
+
+	// Kolom generated yang bernilai NULL setelah soft delete, sehingga unique index hanya
+	// berlaku untuk user aktif dan identitas milik user terhapus bisa dipakai ulang
+	ActiveUsername *string `gorm:"->;type:varchar(150) GENERATED ALWAYS AS (CASE WHEN deleted_at IS NULL THEN NULLIF(username_canonical, '') END) STORED;uniqueIndex:uq_users_username" json:"-"`
+	ActiveEmail    *string `gorm:"->;column:active_email_canonical;type:varchar(100) GENERATED ALWAYS AS (CASE WHEN deleted_at IS NULL THEN NULLIF(email_canonical, '') END) STORED;uniqueIndex:uq_users_email" json:"-"`
+	ActivePhone    *string `gorm:"->;column:active_phone_canonical;type:varchar(100) GENERATED ALWAYS AS (CASE WHEN deleted_at IS NULL THEN phone_canonical END) STORED;uniqueIndex:uq_users_phone" json:"-"`
+}
+
+// UniqueIndexFields memetakan nama unique index user ke field yang bentrok (untuk error duplicate key)
+var UniqueIndexFields = map[string]string{
+	"uq_users_username": "username",
+	"uq_users_email":    "email",
+	"uq_users_phone":    "phone",
+}
+
+// BeforeSave merapikan username, email dan phone lalu mengisi bentuk kanoniknya
+func (u *User) BeforeSave(tx *gorm.DB) error {
+	u.Username = identity.Clean(u.Username)
+	u.Email = identity.Clean(u.Email)
+	u.UsernameCanonical = identity.Username(u.Username)
+	u.EmailCanonical = identity.Email(u.Email)
+
+	u.PhoneCanonical = nil
+	if u.Phone != nil {
+		phone := identity.Clean(*u.Phone)
+		u.Phone = &phone
+		if canonical := identity.Phone(phone); canonical != "" {
+			u.PhoneCanonical = &canonical
+		}
+	}
+	return nil
+}
+
+// AvatarKeys mengembalikan key file avatar per ukuran, atau nil jika user belum punya avatar
+func (u User) AvatarKeys() map[int]string {
+	if u.AvatarPath == nil {
+		return nil
+	}
+
+	keys := make(map[int]string, len(AvatarSizes))
+	for _, size := range AvatarSizes {
+		keys[size] = fmt.Sprintf("%s/%d.jpg", *u.AvatarPath, size)
+	}
+	return keys
 }
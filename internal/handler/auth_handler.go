@@ -1,9 +1,9 @@
 package handler
 
 import (
-	"codebase-api/config"
 	"codebase-api/internal/domain"
 	"codebase-api/internal/usecase"
+	"codebase-api/pkg/auth"
 	helper "codebase-api/pkg/helpers"
 	middleware "codebase-api/pkg/middlewares"
 	"errors"
@@ -11,29 +11,42 @@ import (
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
-	"github.com/golang-jwt/jwt/v4"
-	"github.com/spf13/cast"
 )
 
 type AuthHandler struct {
 	usecase  *usecase.UserUseCase
+	totp     *usecase.TOTPUseCase
 	validate *validator.Validate
 }
 
-func NewAuthHandler(usecase *usecase.UserUseCase) *AuthHandler {
-	return &AuthHandler{usecase: usecase, validate: validator.New()}
+func NewAuthHandler(usecase *usecase.UserUseCase, totp *usecase.TOTPUseCase) *AuthHandler {
+	return &AuthHandler{usecase: usecase, totp: totp, validate: validator.New()}
 }
 
-func (h *AuthHandler) Register(c *fiber.Ctx) error {
-	var input struct {
-		FirstName       string `json:"first_name" validate:"required"`
-		LastName        string `json:"last_name" validate:"required"`
-		Username        string `json:"username" validate:"required"`
-		Email           string `json:"email" validate:"required,email"`
-		Password        string `json:"password" validate:"required,min=8"`
-		PasswordConfirm string `json:"password_confirm" validate:"eqfield=Password"`
-		Phone           string `json:"phone" validate:"required,e164"`
+// RegisterInput adalah aturan validasi pendaftaran user, dipakai juga oleh bulk import
+type RegisterInput struct {
+	FirstName       string `json:"first_name" validate:"required"`
+	LastName        string `json:"last_name" validate:"required"`
+	Username        string `json:"username" validate:"required"`
+	Email           string `json:"email" validate:"required,email"`
+	Password        string `json:"password" validate:"required,min=8"`
+	PasswordConfirm string `json:"password_confirm" validate:"eqfield=Password"`
+	Phone           string `json:"phone" validate:"required,e164"`
+}
+
+func (input RegisterInput) ToUser() *domain.User {
+	return &domain.User{
+		FirstName: input.FirstName,
+		LastName:  input.LastName,
+		Username:  input.Username,
+		Email:     input.Email,
+		Password:  input.Password,
+		Phone:     &input.Phone,
 	}
+}
+
+func (h *AuthHandler) Register(c *fiber.Ctx) error {
+	var input RegisterInput
 
 	if err := c.BodyParser(&input); err != nil {
 		return helper.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request", err)
@@ -44,18 +57,9 @@ func (h *AuthHandler) Register(c *fiber.Ctx) error {
 		return helper.ErrorResponse(c, fiber.StatusBadRequest, errorFields, nil)
 	}
 
-	user := &domain.User{
-		FirstName: input.FirstName,
-		LastName:  input.LastName,
-		Username:  input.Username,
-		Email:     input.Email,
-		Password:  input.Password,
-		Phone:     input.Phone,
-	}
-
-	err := h.usecase.Register(user)
+	err := h.usecase.Register(input.ToUser())
 	if err != nil {
-		return helper.ErrorResponse(c, fiber.StatusInternalServerError, "Internal servel error", nil)
+		return errorResponse(c, err)
 	}
 
 	return helper.SuccessResponse(c, nil, "Register successful")
@@ -75,33 +79,20 @@ func (h *AuthHandler) Login(c *fiber.Ctx) error {
 		return helper.ErrorResponse(c, fiber.StatusBadRequest, "username and password are required", nil)
 	}
 
+	// Hanya kredensial salah yang menjadi 401; user nonaktif 403, error database 500
 	user, err := h.usecase.Login(input.Username, input.Password)
 	if err != nil {
-		return helper.ErrorResponse(c, fiber.StatusUnauthorized, "Unauthorized", nil)
+		return errorResponse(c, err)
 	}
 
-	token, err := middleware.GenerateJWT(*user)
+	token, err := middleware.GenerateJWT(*user, time.Now())
 	if err != nil {
 		return helper.ErrorResponse(c, fiber.StatusInternalServerError, "Could not login", nil)
 	}
 
-	c.Cookie(&fiber.Cookie{
-		Name:     "jwt",
-		Value:    token,
-		Expires:  time.Now().Add(time.Hour * 24),
-		HTTPOnly: true,
-	})
+	setJWTCookie(c, token)
 
-	dto := UserResponseDto{
-		ID:        int(user.ID),
-		UUID:      user.UUID.String(),
-		FirstName: user.FirstName,
-		LastName:  user.LastName,
-		Username:  user.Username,
-		Email:     user.Email,
-		Phone:     user.Phone,
-		IsActive:  user.IsActive,
-	}
+	dto := ToUserResponseDto(user)
 
 	// Return JWT token (for simplicity, skipped token creation)
 	return helper.SuccessResponse(c, dto, "Login successful")
@@ -119,45 +110,80 @@ func (h *AuthHandler) Logout(c *fiber.Ctx) error {
 }
 
 func (h *AuthHandler) RefreshToken(c *fiber.Ctx) error {
-	cookie := c.Cookies("jwt")
-	if cookie == "" {
-		return helper.ErrorResponse(c, fiber.StatusUnauthorized, "Unauthorized", nil)
+	claims, _ := auth.CurrentUser(c)
+	user, ok := auth.User(c)
+	if !ok || !user.IsActive {
+		return helper.ErrorResponse(c, fiber.StatusUnauthorized, "User not found", nil)
 	}
 
-	token, err := jwt.Parse(cookie, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("invalid token")
-		}
-		return config.GetJWTSecret(), nil
-	})
+	// Refresh tidak boleh memperbarui auth_time, hanya login / reauthenticate
+	var authTime time.Time
+	if claims.AuthTime != nil {
+		authTime = claims.AuthTime.Time
+	}
 
+	newToken, err := middleware.GenerateJWT(*user, authTime)
 	if err != nil {
-		return helper.ErrorResponse(c, fiber.StatusUnauthorized, "Unauthorized", nil)
+		return helper.ErrorResponse(c, fiber.StatusInternalServerError, "Could not refresh token", nil)
 	}
 
-	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		userID := claims["id"].(float64)
+	// Set new token to cookies
+	setJWTCookie(c, newToken)
 
-		user, err := h.usecase.FindById(cast.ToUint(userID))
-		if err != nil {
-			return helper.ErrorResponse(c, fiber.StatusUnauthorized, "User not found", nil)
-		}
+	return helper.SuccessResponse(c, nil, "Token refreshed successful")
+}
 
-		newToken, err := middleware.GenerateJWT(*user)
-		if err != nil {
-			return helper.ErrorResponse(c, fiber.StatusInternalServerError, "Could not refresh token", nil)
-		}
+// Reauthenticate menerima password atau kode TOTP (bila TOTP sudah diaktifkan)
+func (h *AuthHandler) Reauthenticate(c *fiber.Ctx) error {
+	var input struct {
+		Password string `json:"password" validate:"required_without=TOTP"`
+		TOTP     string `json:"totp" validate:"omitempty,len=6,numeric"`
+	}
+
+	if err := c.BodyParser(&input); err != nil {
+		return helper.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request", err)
+	}
 
-		// Set new token to cookies
-		c.Cookie(&fiber.Cookie{
-			Name:     "jwt",
-			Value:    newToken,
-			Expires:  time.Now().Add(time.Hour * 24),
-			HTTPOnly: true,
-		})
+	if err := h.validate.Struct(&input); err != nil {
+		return helper.ErrorResponse(c, fiber.StatusBadRequest, "password or a 6 digit totp code is required", nil)
+	}
 
-		return helper.SuccessResponse(c, nil, "Token refreshed successful")
+	user, ok := auth.User(c)
+	if !ok {
+		return helper.ErrorResponse(c, fiber.StatusUnauthorized, "User not found", nil)
 	}
 
-	return helper.SuccessResponse(c, nil, "Token refreshed successful")
+	if input.TOTP != "" {
+		if err := h.totp.Verify(user, input.TOTP); err != nil {
+			// Kode salah adalah kredensial salah; lockout dan TOTP yang belum aktif memakai status domainnya
+			if errors.Is(err, usecase.ErrTOTPInvalid) {
+				return errorResponse(c, usecase.ErrInvalidCredentials)
+			}
+			return errorResponse(c, err)
+		}
+	} else if err := h.usecase.VerifyPassword(user, input.Password); err != nil {
+		return errorResponse(c, err)
+	}
+
+	authTime := time.Now()
+	token, err := middleware.GenerateJWT(*user, authTime)
+	if err != nil {
+		return helper.ErrorResponse(c, fiber.StatusInternalServerError, "Could not reauthenticate", nil)
+	}
+
+	setJWTCookie(c, token)
+
+	return helper.SuccessResponse(c, fiber.Map{
+		"auth_time":      authTime.Unix(),
+		"elevated_until": authTime.Add(middleware.DefaultRecentAuthMaxAge).Unix(),
+	}, "Reauthenticate successful")
+}
+
+func setJWTCookie(c *fiber.Ctx, token string) {
+	c.Cookie(&fiber.Cookie{
+		Name:     "jwt",
+		Value:    token,
+		Expires:  time.Now().Add(time.Hour * 24),
+		HTTPOnly: true,
+	})
 }
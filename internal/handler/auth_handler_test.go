@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"codebase-api/internal/domain"
+	"codebase-api/internal/repository"
+	"codebase-api/internal/testdb"
+	"codebase-api/internal/usecase"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestLoginStatus(t *testing.T) {
+	db := testdb.Open(t)
+	userRepo := repository.NewUserRepository(db)
+	users := usecase.NewUserUseCase(userRepo, usecase.NewLocalAuthenticator(userRepo))
+	h := NewAuthHandler(users, nil)
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret123"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inactive := newTestUser(t, userRepo, "budi", domain.RoleUser)
+	inactive.Password, inactive.IsActive = string(hash), false
+	if err := userRepo.Update(inactive); err != nil {
+		t.Fatal(err)
+	}
+
+	app := fiber.New()
+	app.Post("/auth/login", h.Login)
+	login := func(username, password string) int {
+		req := httptest.NewRequest(fiber.MethodPost, "/auth/login", strings.NewReader(`{"username":"`+username+`","password":"`+password+`"}`))
+		req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+		resp, _ := doRequest(t, app, req)
+		return resp.StatusCode
+	}
+
+	if got := login("budi", "wrong-password"); got != fiber.StatusUnauthorized {
+		t.Fatalf("wrong password: status = %d, want 401", got)
+	}
+	if got := login("nobody", "secret123"); got != fiber.StatusUnauthorized {
+		t.Fatalf("unknown user: status = %d, want 401", got)
+	}
+	if got := login("budi", "secret123"); got != fiber.StatusForbidden {
+		t.Fatalf("inactive user: status = %d, want 403", got)
+	}
+
+	// Database yang tidak bisa diakses bukan kredensial salah
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.Close()
+	if got := login("budi", "secret123"); got != fiber.StatusInternalServerError {
+		t.Fatalf("database down: status = %d, want 500", got)
+	}
+}
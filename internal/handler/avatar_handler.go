@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"codebase-api/internal/usecase"
+	"codebase-api/pkg/auth"
+	helper "codebase-api/pkg/helpers"
+	"io"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type AvatarHandler struct {
+	usecase   *usecase.AvatarUseCase
+	userCache *auth.UserCache
+}
+
+func NewAvatarHandler(usecase *usecase.AvatarUseCase, userCache *auth.UserCache) *AvatarHandler {
+	return &AvatarHandler{usecase: usecase, userCache: userCache}
+}
+
+// Upload menerima multipart field "avatar"
+func (h *AvatarHandler) Upload(c *fiber.Ctx) error {
+	claims, _ := auth.CurrentUser(c)
+
+	header, err := c.FormFile("avatar")
+	if err != nil {
+		return helper.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request", err)
+	}
+	if header.Size > usecase.AvatarMaxBytes {
+		return errorResponse(c, usecase.ErrAvatarTooLarge)
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return helper.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request", err)
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, usecase.AvatarMaxBytes+1))
+	if err != nil {
+		return helper.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request", err)
+	}
+
+	user, err := h.usecase.Upload(claims.ID, data)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	h.userCache.Invalidate(user.ID)
+	return helper.SuccessResponse(c, ToProfileResponseDto(user), "Upload avatar success")
+}
+
+func (h *AvatarHandler) Delete(c *fiber.Ctx) error {
+	claims, _ := auth.CurrentUser(c)
+
+	user, err := h.usecase.Remove(claims.ID)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	h.userCache.Invalidate(user.ID)
+	return helper.SuccessResponse(c, ToProfileResponseDto(user), "Delete avatar success")
+}
@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"codebase-api/internal/usecase"
+	"codebase-api/pkg/auth"
+	helper "codebase-api/pkg/helpers"
+	"errors"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type EmailChangeHandler struct {
+	usecase   *usecase.EmailChangeUseCase
+	userCache *auth.UserCache
+	validate  *validator.Validate
+}
+
+func NewEmailChangeHandler(usecase *usecase.EmailChangeUseCase, userCache *auth.UserCache) *EmailChangeHandler {
+	return &EmailChangeHandler{usecase: usecase, userCache: userCache, validate: validator.New()}
+}
+
+// Request (POST /users/me/email) memulai perubahan email; email baru berlaku setelah dikonfirmasi
+func (h *EmailChangeHandler) Request(c *fiber.Ctx) error {
+	user, ok := auth.User(c)
+	if !ok {
+		return helper.ErrorResponse(c, fiber.StatusUnauthorized, "User not found", nil)
+	}
+
+	var input struct {
+		Email string `json:"email" validate:"required,email"`
+	}
+
+	if err := c.BodyParser(&input); err != nil {
+		return helper.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request", err)
+	}
+
+	if err := h.validate.Struct(&input); err != nil {
+		errorFields := helper.ValidationErrorFormatter(err, input)
+		return helper.ErrorResponse(c, fiber.StatusUnprocessableEntity, errorFields, nil)
+	}
+
+	if err := h.usecase.Request(user, input.Email); err != nil {
+		return errorResponse(c, err)
+	}
+
+	return helper.AcceptedResponse(c, nil, "Confirmation sent to the new email address")
+}
+
+func (h *EmailChangeHandler) tokenInput(c *fiber.Ctx) (string, error) {
+	var input struct {
+		Token string `json:"token" validate:"required"`
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return "", err
+	}
+	if err := h.validate.Struct(&input); err != nil {
+		return "", errors.New("token is required")
+	}
+	return input.Token, nil
+}
+
+// Confirm tidak butuh session: token yang dikirim ke alamat baru sudah membuktikan kepemilikan
+func (h *EmailChangeHandler) Confirm(c *fiber.Ctx) error {
+	token, err := h.tokenInput(c)
+	if err != nil {
+		return helper.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request", err)
+	}
+
+	user, err := h.usecase.Confirm(token)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	h.userCache.Invalidate(user.ID)
+	return helper.SuccessResponse(c, ToUserResponseDto(user), "Email changed successful")
+}
+
+func (h *EmailChangeHandler) Revert(c *fiber.Ctx) error {
+	token, err := h.tokenInput(c)
+	if err != nil {
+		return helper.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request", err)
+	}
+
+	user, err := h.usecase.Revert(token)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	h.userCache.Invalidate(user.ID)
+	return helper.SuccessResponse(c, ToUserResponseDto(user), "Email change reverted successful")
+}
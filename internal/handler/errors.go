@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"codebase-api/internal/domain"
+	"codebase-api/internal/repository"
+	helper "codebase-api/pkg/helpers"
+	"codebase-api/pkg/query"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// errorStatus adalah satu-satunya pemetaan kategori error domain ke HTTP status
+var errorStatus = map[error]int{
+	domain.ErrNotFound:     fiber.StatusNotFound,
+	domain.ErrConflict:     fiber.StatusConflict,
+	domain.ErrValidation:   fiber.StatusUnprocessableEntity,
+	domain.ErrUnauthorized: fiber.StatusUnauthorized,
+	domain.ErrForbidden:    fiber.StatusForbidden,
+	domain.ErrPrecondition: fiber.StatusPreconditionFailed,
+	domain.ErrRateLimited:  fiber.StatusTooManyRequests,
+	domain.ErrTooLarge:     fiber.StatusRequestEntityTooLarge,
+	domain.ErrUnsupported:  fiber.StatusUnsupportedMediaType,
+}
+
+// errorResponse menulis response untuk error dari use case. Error domain memakai status dari errorStatus
+// dan pesan per field bila ada; error lain (mis. database down) dicatat dan dikembalikan sebagai 500
+func errorResponse(c *fiber.Ctx, err error) error {
+	var queryErr *query.Error
+	if errors.As(err, &queryErr) || errors.Is(err, repository.ErrInvalidCursor) {
+		return helper.ErrorResponse(c, fiber.StatusBadRequest, "Invalid query parameters", err)
+	}
+
+	var domainErr *domain.Error
+	if errors.As(err, &domainErr) {
+		if status, ok := errorStatus[domainErr.Kind]; ok {
+			var message interface{} = domainErr.Message
+			if len(domainErr.Fields) > 0 {
+				message = domainErr.Fields
+			}
+			return helper.ErrorResponse(c, status, message, domainErr)
+		}
+	}
+
+	logrus.WithError(err).WithField("path", c.Path()).Error("unhandled error")
+	return helper.ErrorResponse(c, fiber.StatusInternalServerError, "Internal server error", nil)
+}
+
+// ErrorHandler dipasang sebagai fiber.Config.ErrorHandler sehingga error yang dikembalikan langsung oleh
+// handler atau middleware memakai format dan pemetaan status yang sama
+func ErrorHandler(c *fiber.Ctx, err error) error {
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return helper.ErrorResponse(c, fiberErr.Code, fiberErr.Message, nil)
+	}
+	return errorResponse(c, err)
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"codebase-api/internal/usecase"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestErrorResponseStatus(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "otp attempts", err: usecase.ErrOTPTooManyAttempts, want: fiber.StatusTooManyRequests},
+		{name: "otp rate limit", err: &usecase.RateLimitError{RetryAfter: 1500 * time.Millisecond}, want: fiber.StatusTooManyRequests},
+		{name: "otp invalid", err: usecase.ErrOTPInvalid, want: fiber.StatusUnprocessableEntity},
+		{name: "passkey login", err: usecase.ErrPasskeyLogin, want: fiber.StatusUnauthorized},
+		{name: "passkey response", err: usecase.ErrPasskeyResponse, want: fiber.StatusUnprocessableEntity},
+		{name: "passkey session", err: usecase.ErrPasskeySession, want: fiber.StatusUnprocessableEntity},
+		{name: "passkey not found", err: usecase.ErrPasskeyNotFound, want: fiber.StatusNotFound},
+		{name: "ldap entry", err: usecase.ErrLDAPEntryIncomplete, want: fiber.StatusForbidden},
+		{name: "invalid credentials", err: usecase.ErrInvalidCredentials, want: fiber.StatusUnauthorized},
+		{name: "inactive user", err: usecase.ErrUserInactive, want: fiber.StatusForbidden},
+		{name: "avatar too large", err: usecase.ErrAvatarTooLarge, want: fiber.StatusRequestEntityTooLarge},
+		{name: "avatar unsupported", err: usecase.ErrAvatarUnsupported, want: fiber.StatusUnsupportedMediaType},
+		{name: "avatar invalid", err: usecase.ErrAvatarInvalid, want: fiber.StatusUnsupportedMediaType},
+		{name: "infrastructure", err: fmt.Errorf("store passkey session: %w", errors.New("redis down")), want: fiber.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Get("/", func(c *fiber.Ctx) error {
+				return errorResponse(c, tt.err)
+			})
+
+			resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/", nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != tt.want {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.want)
+			}
+		})
+	}
+}
+
+func TestPhoneVerificationErrorResponseSetsRetryAfter(t *testing.T) {
+	app := fiber.New()
+	app.Get("/", func(c *fiber.Ctx) error {
+		return phoneVerificationErrorResponse(c, &usecase.RateLimitError{RetryAfter: 1500 * time.Millisecond})
+	})
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != fiber.StatusTooManyRequests || resp.Header.Get(fiber.HeaderRetryAfter) != "2" {
+		t.Fatalf("status = %d, Retry-After = %q", resp.StatusCode, resp.Header.Get(fiber.HeaderRetryAfter))
+	}
+}
@@ -0,0 +1,292 @@
+package handler
+
+import (
+	"codebase-api/internal/domain"
+	"codebase-api/internal/usecase"
+	"codebase-api/pkg/auth"
+	helper "codebase-api/pkg/helpers"
+	middleware "codebase-api/pkg/middlewares"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type GroupResponseDto struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func ToGroupResponseDto(group domain.Group) GroupResponseDto {
+	return GroupResponseDto{
+		ID:          group.UUID.String(),
+		Name:        group.Name,
+		Description: group.Description,
+		CreatedAt:   group.CreatedAt,
+		UpdatedAt:   group.UpdatedAt,
+	}
+}
+
+type GroupMemberResponseDto struct {
+	User     UserResponseDto `json:"user"`
+	Role     string          `json:"role"`
+	JoinedAt time.Time       `json:"joined_at"`
+	// Version dikirim kembali sebagai If-Match saat mengubah peran
+	Version uint `json:"version"`
+}
+
+func ToGroupMemberResponseDto(member domain.GroupMember) GroupMemberResponseDto {
+	dto := GroupMemberResponseDto{Role: member.Role, JoinedAt: member.CreatedAt, Version: member.Version}
+	if member.User != nil {
+		dto.User = ToUserResponseDto(member.User)
+	}
+	return dto
+}
+
+type GroupHandler struct {
+	usecase  *usecase.GroupUseCase
+	validate *validator.Validate
+}
+
+func NewGroupHandler(usecase *usecase.GroupUseCase) *GroupHandler {
+	return &GroupHandler{usecase: usecase, validate: validator.New()}
+}
+
+// authorizedGroup memuat group dari path :id dan memastikan user login punya salah satu roles
+func (h *GroupHandler) authorizedGroup(c *fiber.Ctx, roles ...string) (*domain.Group, *domain.User, error) {
+	user, ok := auth.User(c)
+	if !ok {
+		return nil, nil, usecase.ErrGroupForbidden
+	}
+
+	group, err := h.usecase.FindVisible(user, c.Params("id"))
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := h.usecase.Authorize(user, group.ID, roles...); err != nil {
+		return nil, nil, err
+	}
+	return group, user, nil
+}
+
+func (h *GroupHandler) groupPage(c *fiber.Ctx, list func() ([]domain.Group, helper.Pagination, error)) error {
+	groups, pagination, err := list()
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	var data []interface{}
+	for _, group := range groups {
+		data = append(data, ToGroupResponseDto(group))
+	}
+	return helper.PaginationResponse(c, data, pagination, "Fetch groups success")
+}
+
+// List: admin melihat semua group, user lain hanya group yang diikutinya
+func (h *GroupHandler) List(c *fiber.Ctx) error {
+	user, ok := auth.User(c)
+	if !ok {
+		return helper.ErrorResponse(c, fiber.StatusUnauthorized, "User not found", nil)
+	}
+
+	req := parsePageRequest(c)
+	return h.groupPage(c, func() ([]domain.Group, helper.Pagination, error) {
+		result, err := h.usecase.List(user, req)
+		if err != nil {
+			return nil, helper.Pagination{}, err
+		}
+		return result.Items, toPagination(result, req), nil
+	})
+}
+
+func (h *GroupHandler) listForUser(c *fiber.Ctx, userID uint) error {
+	req := parsePageRequest(c)
+	return h.groupPage(c, func() ([]domain.Group, helper.Pagination, error) {
+		result, err := h.usecase.ListForUser(userID, req)
+		if err != nil {
+			return nil, helper.Pagination{}, err
+		}
+		return result.Items, toPagination(result, req), nil
+	})
+}
+
+func (h *GroupHandler) MyGroups(c *fiber.Ctx) error {
+	claims, _ := auth.CurrentUser(c)
+	return h.listForUser(c, claims.ID)
+}
+
+// UserGroups menampilkan group milik user lain; hanya untuk dirinya sendiri atau admin
+func (h *GroupHandler) UserGroups(c *fiber.Ctx) error {
+	userID, err := h.usecase.ResolveMember(c.Params("id"))
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	if !canManageUser(c, userID) {
+		return helper.ErrorResponse(c, fiber.StatusForbidden, "Forbidden", nil)
+	}
+	return h.listForUser(c, userID)
+}
+
+func (h *GroupHandler) Create(c *fiber.Ctx) error {
+	user, ok := auth.User(c)
+	if !ok {
+		return helper.ErrorResponse(c, fiber.StatusUnauthorized, "User not found", nil)
+	}
+
+	var input struct {
+		Name        string `json:"name" validate:"required"`
+		Description string `json:"description"`
+	}
+
+	if err := c.BodyParser(&input); err != nil {
+		return helper.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request", err)
+	}
+
+	if err := h.validate.Struct(&input); err != nil {
+		errorFields := helper.ValidationErrorFormatter(err, input)
+		return helper.ErrorResponse(c, fiber.StatusUnprocessableEntity, errorFields, nil)
+	}
+
+	group, err := h.usecase.Create(user, input.Name, input.Description)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return helper.SuccessResponse(c, ToGroupResponseDto(*group), "Create group success")
+}
+
+func (h *GroupHandler) Detail(c *fiber.Ctx) error {
+	group, _, err := h.authorizedGroup(c)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	if helper.NotModified(c, helper.VersionETag(group.Version), group.UpdatedAt) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+	return helper.SuccessResponse(c, ToGroupResponseDto(*group), "Fetch group success")
+}
+
+func (h *GroupHandler) Update(c *fiber.Ctx) error {
+	group, _, err := h.authorizedGroup(c, domain.GroupRoleOwner)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	var input struct {
+		Name        *string `json:"name" validate:"omitempty,min=1"`
+		Description *string `json:"description"`
+	}
+
+	if err := c.BodyParser(&input); err != nil {
+		return helper.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request", err)
+	}
+
+	if err := h.validate.Struct(&input); err != nil {
+		errorFields := helper.ValidationErrorFormatter(err, input)
+		return helper.ErrorResponse(c, fiber.StatusUnprocessableEntity, errorFields, nil)
+	}
+
+	group, err = h.usecase.Update(group, usecase.GroupUpdate{
+		Name:        input.Name,
+		Description: input.Description,
+		Version:     middleware.IfMatchVersion(c),
+	})
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	helper.SetVersionETag(c, group.Version)
+	return helper.SuccessResponse(c, ToGroupResponseDto(*group), "Update group success")
+}
+
+func (h *GroupHandler) Delete(c *fiber.Ctx) error {
+	group, _, err := h.authorizedGroup(c, domain.GroupRoleOwner)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	if err := h.usecase.Delete(group); err != nil {
+		return errorResponse(c, err)
+	}
+
+	return helper.SuccessResponse(c, nil, "Delete group success")
+}
+
+func (h *GroupHandler) Members(c *fiber.Ctx) error {
+	group, _, err := h.authorizedGroup(c)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	req := parsePageRequest(c)
+	result, err := h.usecase.Members(group, req)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	var data []interface{}
+	for _, member := range result.Items {
+		data = append(data, ToGroupMemberResponseDto(member))
+	}
+	return helper.PaginationResponse(c, data, toPagination(result, req), "Fetch group members success")
+}
+
+// SetMember (PUT /groups/:id/members/:userId) menambahkan anggota atau mengubah perannya
+func (h *GroupHandler) SetMember(c *fiber.Ctx) error {
+	group, _, err := h.authorizedGroup(c, domain.GroupRoleOwner)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	userID, err := h.usecase.ResolveMember(c.Params("userId"))
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	var input struct {
+		Role string `json:"role"`
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return helper.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request", err)
+	}
+	if input.Role == "" {
+		input.Role = domain.GroupRoleMember
+	}
+
+	member, err := h.usecase.SetMember(group, userID, input.Role, middleware.IfMatchVersion(c))
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	helper.SetVersionETag(c, member.Version)
+	return helper.SuccessResponse(c, ToGroupMemberResponseDto(*member), "Update group member success")
+}
+
+// RemoveMember boleh dilakukan owner group, admin, atau anggota itu sendiri (keluar dari group)
+func (h *GroupHandler) RemoveMember(c *fiber.Ctx) error {
+	userID, err := h.usecase.ResolveMember(c.Params("userId"))
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	claims, _ := auth.CurrentUser(c)
+	roles := []string{domain.GroupRoleOwner}
+	if claims.ID == userID {
+		roles = nil
+	}
+
+	group, _, err := h.authorizedGroup(c, roles...)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	if err := h.usecase.RemoveMember(group, userID); err != nil {
+		return errorResponse(c, err)
+	}
+
+	return helper.SuccessResponse(c, nil, "Remove group member success")
+}
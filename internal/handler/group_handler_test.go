@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"codebase-api/internal/domain"
+	"codebase-api/internal/repository"
+	"codebase-api/internal/testdb"
+	"codebase-api/internal/usecase"
+	helper "codebase-api/pkg/helpers"
+	middleware "codebase-api/pkg/middlewares"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestSetMemberChecksIfMatch(t *testing.T) {
+	db := testdb.Open(t)
+	userRepo := repository.NewUserRepository(db)
+	groups := usecase.NewGroupUseCase(repository.NewGroupRepository(db), repository.NewGroupMemberRepository(db), userRepo)
+	owner := newTestUser(t, userRepo, "owner", domain.RoleUser)
+	member := newTestUser(t, userRepo, "siti", domain.RoleUser)
+	group, err := groups.Create(owner, "Finance", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app := fiber.New()
+	app.Put("/groups/:id/members/:userId", middleware.RequireIfMatch(), asUser(owner), NewGroupHandler(groups).SetMember)
+	setMember := func(ifMatch string, role string) (int, string, GroupMemberResponseDto) {
+		req := httptest.NewRequest(fiber.MethodPut, "/groups/"+group.UUID.String()+"/members/"+member.UUID.String(), strings.NewReader(`{"role":"`+role+`"}`))
+		req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+		if ifMatch != "" {
+			req.Header.Set(fiber.HeaderIfMatch, ifMatch)
+		}
+		resp, env := doRequest(t, app, req)
+		var dto GroupMemberResponseDto
+		json.Unmarshal(env.Data, &dto)
+		return resp.StatusCode, resp.Header.Get(fiber.HeaderETag), dto
+	}
+
+	if status, _, _ := setMember("", domain.GroupRoleMember); status != fiber.StatusPreconditionRequired {
+		t.Fatalf("without If-Match: status = %d, want 428", status)
+	}
+	// Version tertentu untuk anggota yang belum ada berarti client memegang state yang salah
+	if status, _, _ := setMember(`"1"`, domain.GroupRoleMember); status != fiber.StatusPreconditionFailed {
+		t.Fatalf("version for a new member: status = %d, want 412", status)
+	}
+
+	status, etag, added := setMember("*", domain.GroupRoleMember)
+	if status != fiber.StatusOK || etag != helper.VersionETag(added.Version) {
+		t.Fatalf("add: status = %d, ETag = %q, dto = %+v", status, etag, added)
+	}
+
+	status, next, promoted := setMember(etag, domain.GroupRoleOwner)
+	if status != fiber.StatusOK || promoted.Role != domain.GroupRoleOwner || promoted.Version != added.Version+1 || next != helper.VersionETag(promoted.Version) {
+		t.Fatalf("promote: status = %d, ETag = %q, dto = %+v", status, next, promoted)
+	}
+
+	if status, _, _ := setMember(etag, domain.GroupRoleMember); status != fiber.StatusPreconditionFailed {
+		t.Fatalf("stale If-Match: status = %d, want 412", status)
+	}
+}
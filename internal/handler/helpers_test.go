@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"codebase-api/internal/domain"
+	"codebase-api/internal/repository"
+	"codebase-api/pkg/auth"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func newTestUser(t *testing.T, repo *repository.UserRepository, username string, role string) *domain.User {
+	t.Helper()
+
+	user := &domain.User{
+		FirstName: "Test",
+		LastName:  "User",
+		Username:  username,
+		Email:     username + "@example.com",
+		Password:  "not-a-hash",
+		IsActive:  true,
+		Role:      role,
+	}
+	if err := repo.Create(user); err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+// asUser menggantikan JwtProtected + LoadUser: claims dan user langsung dipasang di context
+func asUser(user *domain.User) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claims := auth.NewClaims(*user, time.Now(), time.Hour)
+		auth.SetClaims(c, &claims)
+		auth.SetUser(c, user)
+		return c.Next()
+	}
+}
+
+// envelope adalah bentuk response helpers.*Response
+type envelope struct {
+	StatusCode int             `json:"statusCode"`
+	Message    string          `json:"message"`
+	Error      string          `json:"error"`
+	Data       json.RawMessage `json:"data"`
+}
+
+func doRequest(t *testing.T, app *fiber.App, req *http.Request) (*http.Response, envelope) {
+	t.Helper()
+
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var env envelope
+	if len(body) > 0 && json.Unmarshal(body, &env) != nil {
+		env.Data = body
+	}
+	return resp, env
+}
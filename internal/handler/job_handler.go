@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"codebase-api/internal/domain"
+	"codebase-api/pkg/auth"
+	helper "codebase-api/pkg/helpers"
+	"codebase-api/pkg/jobs"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type JobResponseDto struct {
+	jobs.Info
+	DownloadURL string `json:"download_url,omitempty"`
+}
+
+func ToJobResponseDto(job *jobs.Job) JobResponseDto {
+	dto := JobResponseDto{Info: job.Info()}
+	if dto.Status == jobs.StatusSucceeded && dto.ResultName != "" {
+		dto.DownloadURL = "/api/v1/jobs/" + dto.ID + "/download"
+	}
+	return dto
+}
+
+type JobHandler struct {
+	registry *jobs.Registry
+}
+
+func NewJobHandler(registry *jobs.Registry) *JobHandler {
+	return &JobHandler{registry: registry}
+}
+
+// findOwnedJob hanya mengembalikan job milik user yang login; admin boleh melihat job user lain
+// kecuali job private seperti export data pribadi
+func (h *JobHandler) findOwnedJob(c *fiber.Ctx) (*jobs.Job, bool) {
+	user, ok := auth.User(c)
+	if !ok {
+		return nil, false
+	}
+
+	job, ok := h.registry.Get(c.Params("id"))
+	if !ok {
+		return nil, false
+	}
+	info := job.Info()
+	if info.OwnerID != user.ID && (user.Role != domain.RoleAdmin || info.Private) {
+		return nil, false
+	}
+	return job, true
+}
+
+func (h *JobHandler) Status(c *fiber.Ctx) error {
+	job, ok := h.findOwnedJob(c)
+	if !ok {
+		return helper.ErrorResponse(c, fiber.StatusNotFound, "Job not found", nil)
+	}
+
+	return helper.SuccessResponse(c, ToJobResponseDto(job), "Fetch job success")
+}
+
+func (h *JobHandler) Download(c *fiber.Ctx) error {
+	job, ok := h.findOwnedJob(c)
+	if !ok {
+		return helper.ErrorResponse(c, fiber.StatusNotFound, "Job not found", nil)
+	}
+
+	path, name, contentType, err := job.Result()
+	if err != nil {
+		return helper.ErrorResponse(c, fiber.StatusConflict, "Job result is not available", err)
+	}
+
+	c.Set(fiber.HeaderContentType, contentType)
+	return c.Download(path, name)
+}
@@ -0,0 +1,185 @@
+package handler
+
+import (
+	"codebase-api/internal/domain"
+	"codebase-api/internal/usecase"
+	"codebase-api/pkg/auth"
+	helper "codebase-api/pkg/helpers"
+	middleware "codebase-api/pkg/middlewares"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+const passkeyLoginCookie = "webauthn_login"
+
+type PasskeyResponseDto struct {
+	ID             string     `json:"id"`
+	Name           string     `json:"name"`
+	Transports     []string   `json:"transports"`
+	BackupEligible bool       `json:"backup_eligible"`
+	BackupState    bool       `json:"backup_state"`
+	CreatedAt      time.Time  `json:"created_at"`
+	LastUsedAt     *time.Time `json:"last_used_at"`
+	// Version dikirim kembali sebagai If-Match saat rename
+	Version uint `json:"version"`
+}
+
+func ToPasskeyResponseDto(p domain.Passkey) PasskeyResponseDto {
+	transports := []string{}
+	if p.Transports != "" {
+		transports = strings.Split(p.Transports, ",")
+	}
+
+	return PasskeyResponseDto{
+		ID:             p.UUID.String(),
+		Name:           p.Name,
+		Transports:     transports,
+		BackupEligible: p.BackupEligible,
+		BackupState:    p.BackupState,
+		CreatedAt:      p.CreatedAt,
+		LastUsedAt:     p.LastUsedAt,
+		Version:        p.Version,
+	}
+}
+
+type PasskeyHandler struct {
+	usecase  *usecase.PasskeyUseCase
+	validate *validator.Validate
+}
+
+func NewPasskeyHandler(usecase *usecase.PasskeyUseCase) *PasskeyHandler {
+	return &PasskeyHandler{usecase: usecase, validate: validator.New()}
+}
+
+func (h *PasskeyHandler) BeginRegistration(c *fiber.Ctx) error {
+	user, ok := auth.User(c)
+	if !ok {
+		return helper.ErrorResponse(c, fiber.StatusUnauthorized, "User not found", nil)
+	}
+
+	creation, err := h.usecase.BeginRegistration(user)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return helper.SuccessResponse(c, creation, "Passkey registration started")
+}
+
+// FinishRegistration menerima PublicKeyCredential dari navigator.credentials.create() apa adanya sebagai body
+func (h *PasskeyHandler) FinishRegistration(c *fiber.Ctx) error {
+	user, ok := auth.User(c)
+	if !ok {
+		return helper.ErrorResponse(c, fiber.StatusUnauthorized, "User not found", nil)
+	}
+
+	name := strings.TrimSpace(c.Query("name"))
+	if len(name) > 100 {
+		return helper.ErrorResponse(c, fiber.StatusBadRequest, "Passkey name is too long", nil)
+	}
+
+	passkey, err := h.usecase.FinishRegistration(user, name, c.Body())
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return helper.SuccessResponse(c, ToPasskeyResponseDto(*passkey), "Passkey registered successful")
+}
+
+// BeginLogin selalu mengembalikan challenge discoverable; username di body (jika ada) diabaikan
+// agar response tidak membedakan username yang terdaftar dan yang tidak
+func (h *PasskeyHandler) BeginLogin(c *fiber.Ctx) error {
+	assertion, ceremonyID, err := h.usecase.BeginLogin()
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	c.Cookie(&fiber.Cookie{
+		Name:     passkeyLoginCookie,
+		Value:    ceremonyID,
+		Expires:  time.Now().Add(5 * time.Minute),
+		HTTPOnly: true,
+	})
+
+	return helper.SuccessResponse(c, assertion, "Passkey login started")
+}
+
+// FinishLogin menerima PublicKeyCredential dari navigator.credentials.get() apa adanya sebagai body
+func (h *PasskeyHandler) FinishLogin(c *fiber.Ctx) error {
+	ceremonyID := c.Cookies(passkeyLoginCookie)
+	if ceremonyID == "" {
+		return helper.ErrorResponse(c, fiber.StatusBadRequest, "Passkey login was not started", nil)
+	}
+
+	c.Cookie(&fiber.Cookie{
+		Name:     passkeyLoginCookie,
+		Value:    "",
+		Expires:  time.Now().Add(-time.Hour),
+		HTTPOnly: true,
+	})
+
+	user, err := h.usecase.FinishLogin(ceremonyID, c.Body())
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	token, err := middleware.GenerateJWT(*user, time.Now())
+	if err != nil {
+		return helper.ErrorResponse(c, fiber.StatusInternalServerError, "Could not login", nil)
+	}
+
+	setJWTCookie(c, token)
+
+	return helper.SuccessResponse(c, ToUserResponseDto(user), "Login successful")
+}
+
+func (h *PasskeyHandler) List(c *fiber.Ctx) error {
+	claims, _ := auth.CurrentUser(c)
+
+	passkeys, err := h.usecase.List(claims.ID)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	dto := []PasskeyResponseDto{}
+	for _, passkey := range passkeys {
+		dto = append(dto, ToPasskeyResponseDto(passkey))
+	}
+
+	return helper.SuccessResponse(c, dto, "Fetch passkeys success")
+}
+
+func (h *PasskeyHandler) Rename(c *fiber.Ctx) error {
+	var input struct {
+		Name string `json:"name" validate:"required,max=100"`
+	}
+
+	if err := c.BodyParser(&input); err != nil {
+		return helper.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request", err)
+	}
+
+	if err := h.validate.Struct(&input); err != nil {
+		return helper.ErrorResponse(c, fiber.StatusBadRequest, "name is required (max 100 characters)", nil)
+	}
+
+	claims, _ := auth.CurrentUser(c)
+	passkey, err := h.usecase.Rename(claims.ID, c.Params("id"), strings.TrimSpace(input.Name), middleware.IfMatchVersion(c))
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	helper.SetVersionETag(c, passkey.Version)
+	return helper.SuccessResponse(c, ToPasskeyResponseDto(*passkey), "Passkey renamed successful")
+}
+
+func (h *PasskeyHandler) Delete(c *fiber.Ctx) error {
+	claims, _ := auth.CurrentUser(c)
+
+	if err := h.usecase.Delete(claims.ID, c.Params("id")); err != nil {
+		return errorResponse(c, err)
+	}
+
+	return helper.SuccessResponse(c, nil, "Passkey deleted successful")
+}
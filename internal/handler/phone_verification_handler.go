@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"codebase-api/internal/usecase"
+	"codebase-api/pkg/auth"
+	helper "codebase-api/pkg/helpers"
+	"errors"
+	"math"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type PhoneVerificationHandler struct {
+	usecase   *usecase.PhoneVerificationUseCase
+	userCache *auth.UserCache
+	validate  *validator.Validate
+}
+
+func NewPhoneVerificationHandler(usecase *usecase.PhoneVerificationUseCase, userCache *auth.UserCache) *PhoneVerificationHandler {
+	return &PhoneVerificationHandler{usecase: usecase, userCache: userCache, validate: validator.New()}
+}
+
+// phoneVerificationErrorResponse menambahkan Retry-After untuk RateLimitError; statusnya tetap dari errorResponse
+func phoneVerificationErrorResponse(c *fiber.Ctx, err error) error {
+	var rateLimit *usecase.RateLimitError
+	if errors.As(err, &rateLimit) {
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(rateLimit.RetryAfter.Seconds()))))
+	}
+	return errorResponse(c, err)
+}
+
+// Send (POST /users/me/phone/verification) mengirim kode OTP ke nomor telepon user
+func (h *PhoneVerificationHandler) Send(c *fiber.Ctx) error {
+	user, ok := auth.User(c)
+	if !ok {
+		return helper.ErrorResponse(c, fiber.StatusUnauthorized, "User not found", nil)
+	}
+
+	if err := h.usecase.Send(user); err != nil {
+		return phoneVerificationErrorResponse(c, err)
+	}
+
+	return helper.AcceptedResponse(c, fiber.Map{"expires_in": int(usecase.PhoneOTPTTL.Seconds())}, "Verification code sent")
+}
+
+// Verify (POST /users/me/phone/verify) mencocokkan kode OTP dan menandai nomor sebagai terverifikasi
+func (h *PhoneVerificationHandler) Verify(c *fiber.Ctx) error {
+	user, ok := auth.User(c)
+	if !ok {
+		return helper.ErrorResponse(c, fiber.StatusUnauthorized, "User not found", nil)
+	}
+
+	var input struct {
+		Code string `json:"code" validate:"required,len=6,numeric"`
+	}
+
+	if err := c.BodyParser(&input); err != nil {
+		return helper.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request", err)
+	}
+
+	if err := h.validate.Struct(&input); err != nil {
+		return helper.ErrorResponse(c, fiber.StatusUnprocessableEntity, map[string]string{"code": "Code must be 6 digits"}, nil)
+	}
+
+	verified, err := h.usecase.Verify(user, input.Code)
+	if err != nil {
+		return phoneVerificationErrorResponse(c, err)
+	}
+
+	h.userCache.Invalidate(verified.ID)
+	return helper.SuccessResponse(c, ToProfileResponseDto(verified), "Phone verified successful")
+}
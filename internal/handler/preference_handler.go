@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"codebase-api/internal/usecase"
+	"codebase-api/pkg/auth"
+	helper "codebase-api/pkg/helpers"
+	middleware "codebase-api/pkg/middlewares"
+	"encoding/json"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type PreferenceHandler struct {
+	usecase *usecase.PreferenceUseCase
+}
+
+func NewPreferenceHandler(usecase *usecase.PreferenceUseCase) *PreferenceHandler {
+	return &PreferenceHandler{usecase: usecase}
+}
+
+func (h *PreferenceHandler) Get(c *fiber.Ctx) error {
+	claims, _ := auth.CurrentUser(c)
+
+	values, version, err := h.usecase.Get(claims.ID)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	// Preferensi disimpan di baris user, jadi ETag-nya adalah version user
+	helper.SetVersionETag(c, version)
+	return helper.SuccessResponse(c, values, "Fetch preferences success")
+}
+
+// Patch menerima object {"theme": "dark", "notifications.email": false}; null mengembalikan ke default
+func (h *PreferenceHandler) Patch(c *fiber.Ctx) error {
+	claims, _ := auth.CurrentUser(c)
+
+	var patch map[string]interface{}
+	if err := json.Unmarshal(c.Body(), &patch); err != nil || patch == nil {
+		return helper.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request", errors.New("body must be a JSON object"))
+	}
+
+	values, version, err := h.usecase.Update(claims.ID, middleware.IfMatchVersion(c), patch)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	helper.SetVersionETag(c, version)
+	return helper.SuccessResponse(c, values, "Update preferences success")
+}
@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"codebase-api/internal/domain"
+	"codebase-api/internal/repository"
+	"codebase-api/internal/testdb"
+	"codebase-api/internal/usecase"
+	helper "codebase-api/pkg/helpers"
+	middleware "codebase-api/pkg/middlewares"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func newPreferenceApp(t *testing.T) (*fiber.App, *domain.User, *repository.UserRepository) {
+	t.Helper()
+
+	userRepo := repository.NewUserRepository(testdb.Open(t))
+	user := newTestUser(t, userRepo, "budi", domain.RoleUser)
+	h := NewPreferenceHandler(usecase.NewPreferenceUseCase(userRepo, time.Minute))
+
+	app := fiber.New()
+	app.Get("/users/me/preferences", asUser(user), h.Get)
+	app.Patch("/users/me/preferences", middleware.RequireIfMatch(), asUser(user), h.Patch)
+	return app, user, userRepo
+}
+
+func patchPreferences(t *testing.T, app *fiber.App, ifMatch string, body string) (int, string, envelope) {
+	t.Helper()
+
+	req := httptest.NewRequest(fiber.MethodPatch, "/users/me/preferences", strings.NewReader(body))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	if ifMatch != "" {
+		req.Header.Set(fiber.HeaderIfMatch, ifMatch)
+	}
+	resp, env := doRequest(t, app, req)
+	return resp.StatusCode, resp.Header.Get(fiber.HeaderETag), env
+}
+
+func TestPatchPreferencesRequiresIfMatch(t *testing.T) {
+	app, _, _ := newPreferenceApp(t)
+
+	if status, _, _ := patchPreferences(t, app, "", `{"theme":"dark"}`); status != fiber.StatusPreconditionRequired {
+		t.Fatalf("status = %d, want 428", status)
+	}
+}
+
+func TestPatchPreferencesChecksVersion(t *testing.T) {
+	app, user, _ := newPreferenceApp(t)
+
+	resp, _ := doRequest(t, app, httptest.NewRequest(fiber.MethodGet, "/users/me/preferences", nil))
+	etag := resp.Header.Get(fiber.HeaderETag)
+	if etag != helper.VersionETag(user.Version) {
+		t.Fatalf("ETag = %q, want %q", etag, helper.VersionETag(user.Version))
+	}
+
+	status, next, env := patchPreferences(t, app, etag, `{"theme":"dark"}`)
+	if status != fiber.StatusOK || next != helper.VersionETag(user.Version+1) {
+		t.Fatalf("status = %d, ETag = %q", status, next)
+	}
+	var values map[string]interface{}
+	if err := json.Unmarshal(env.Data, &values); err != nil || values["theme"] != "dark" {
+		t.Fatalf("values = %s", env.Data)
+	}
+
+	// Request kedua yang membaca ETag lama tidak boleh menimpa perubahan pertama
+	if status, _, _ := patchPreferences(t, app, etag, `{"language":"id"}`); status != fiber.StatusPreconditionFailed {
+		t.Fatalf("stale If-Match: status = %d, want 412", status)
+	}
+	if status, _, _ := patchPreferences(t, app, next, `{"language":"id"}`); status != fiber.StatusOK {
+		t.Fatalf("fresh If-Match: status = %d, want 200", status)
+	}
+}
+
+func TestPatchPreferencesDoesNotLoseConcurrentUpdates(t *testing.T) {
+	app, user, userRepo := newPreferenceApp(t)
+	if status, _, _ := patchPreferences(t, app, "*", `{"theme":"dark"}`); status != fiber.StatusOK {
+		t.Fatalf("status = %d", status)
+	}
+
+	// Penulis lain yang masih memegang version lama ditolak, bukan menimpa theme=dark
+	if err := userRepo.UpdatePreferences(user.ID, user.Version, []byte(`{"language":"id"}`)); err != domain.ErrStaleVersion {
+		t.Fatalf("err = %v, want ErrStaleVersion", err)
+	}
+
+	resp, env := doRequest(t, app, httptest.NewRequest(fiber.MethodGet, "/users/me/preferences", nil))
+	var values map[string]interface{}
+	if err := json.Unmarshal(env.Data, &values); err != nil || values["theme"] != "dark" || values["language"] != "en" {
+		t.Fatalf("values = %s", env.Data)
+	}
+	if resp.Header.Get(fiber.HeaderETag) != helper.VersionETag(user.Version+1) {
+		t.Fatalf("ETag = %q", resp.Header.Get(fiber.HeaderETag))
+	}
+}
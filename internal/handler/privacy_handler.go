@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"bufio"
+	"codebase-api/internal/usecase"
+	"codebase-api/pkg/auth"
+	helper "codebase-api/pkg/helpers"
+	"codebase-api/pkg/jobs"
+	"context"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type PrivacyHandler struct {
+	usecase     *usecase.PrivacyUseCase
+	userUseCase *usecase.UserUseCase
+	jobs        *jobs.Registry
+	userCache   *auth.UserCache
+	validate    *validator.Validate
+}
+
+func NewPrivacyHandler(usecase *usecase.PrivacyUseCase, userUseCase *usecase.UserUseCase, registry *jobs.Registry, userCache *auth.UserCache) *PrivacyHandler {
+	return &PrivacyHandler{usecase: usecase, userUseCase: userUseCase, jobs: registry, userCache: userCache, validate: validator.New()}
+}
+
+// DataExport (POST /users/me/data-export) menyiapkan ZIP berisi seluruh data user di background
+func (h *PrivacyHandler) DataExport(c *fiber.Ctx) error {
+	user, ok := auth.User(c)
+	if !ok {
+		return helper.ErrorResponse(c, fiber.StatusUnauthorized, "User not found", nil)
+	}
+
+	userID := user.ID
+	// ZIP berisi seluruh data pribadi, hanya pemiliknya yang boleh mengunduh
+	job := h.jobs.SubmitPrivate("data_export", userID, func(ctx context.Context, job *jobs.Job) error {
+		file, err := job.CreateResult("data-export.zip", "application/zip")
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		buffered := bufio.NewWriter(file)
+		if err := h.usecase.Export(ctx, userID, buffered); err != nil {
+			return err
+		}
+		return buffered.Flush()
+	})
+
+	return helper.AcceptedResponse(c, ToJobResponseDto(job), "Data export queued")
+}
+
+// Erase (POST /users/:id/erase) menganonimkan data pribadi user; hanya untuk admin
+func (h *PrivacyHandler) Erase(c *fiber.Ctx) error {
+	admin, ok := auth.User(c)
+	if !ok {
+		return helper.ErrorResponse(c, fiber.StatusUnauthorized, "User not found", nil)
+	}
+
+	id, err := h.userUseCase.ResolveID(c.Params("id"))
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	var input struct {
+		Reason string `json:"reason" validate:"max=255"`
+	}
+
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&input); err != nil {
+			return helper.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request", err)
+		}
+	}
+
+	if err := h.validate.Struct(&input); err != nil {
+		return helper.ErrorResponse(c, fiber.StatusUnprocessableEntity, map[string]string{"reason": "Reason must be at most 255 characters"}, nil)
+	}
+
+	user, err := h.usecase.Erase(id, admin.ID, input.Reason)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	h.userCache.Invalidate(id)
+	// Export data pribadi yang belum kedaluwarsa ikut dihapus
+	h.jobs.RemoveOwned(id)
+	return helper.SuccessResponse(c, ToUserResponseDto(user), "Erase user success")
+}
@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"codebase-api/internal/domain"
+	"codebase-api/internal/repository"
+	"codebase-api/internal/testdb"
+	"codebase-api/internal/usecase"
+	"codebase-api/pkg/auth"
+	"codebase-api/pkg/filestore"
+	"codebase-api/pkg/jobs"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestDataExportIsOnlyAvailableToItsOwner(t *testing.T) {
+	db := testdb.Open(t)
+	userRepo := repository.NewUserRepository(db)
+	owner := newTestUser(t, userRepo, "budi", domain.RoleUser)
+	admin := newTestUser(t, userRepo, "root", domain.RoleAdmin)
+
+	registry := jobs.NewRegistry(time.Hour)
+	t.Cleanup(registry.Close)
+	prefs := usecase.NewPreferenceUseCase(userRepo, time.Minute)
+	privacy := usecase.NewPrivacyUseCase(userRepo, repository.NewPasskeyRepository(db), repository.NewGroupMemberRepository(db), prefs, filestore.NewLocal(t.TempDir()))
+	h := NewPrivacyHandler(privacy, usecase.NewUserUseCase(userRepo), registry, auth.NewUserCache(time.Minute))
+	jobHandler := NewJobHandler(registry)
+
+	app := fiber.New()
+	app.Post("/users/me/data-export", asUser(owner), h.DataExport)
+	app.Post("/users/:id/erase", asUser(admin), h.Erase)
+	for _, user := range []*domain.User{owner, admin} {
+		app.Get("/"+user.Username+"/jobs/:id/download", asUser(user), jobHandler.Download)
+	}
+
+	resp, env := doRequest(t, app, httptest.NewRequest(fiber.MethodPost, "/users/me/data-export", nil))
+	var job JobResponseDto
+	if resp.StatusCode != fiber.StatusAccepted || json.Unmarshal(env.Data, &job) != nil {
+		t.Fatalf("status = %d, data = %s", resp.StatusCode, env.Data)
+	}
+	queued, _ := registry.Get(job.ID)
+	for deadline := time.Now().Add(5 * time.Second); queued.Info().FinishedAt == nil; {
+		if time.Now().After(deadline) {
+			t.Fatal("export did not finish")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	download := func(user *domain.User) int {
+		resp, _ := doRequest(t, app, httptest.NewRequest(fiber.MethodGet, "/"+user.Username+"/jobs/"+job.ID+"/download", nil))
+		return resp.StatusCode
+	}
+	if status := download(owner); status != fiber.StatusOK {
+		t.Fatalf("owner download: status = %d, want 200", status)
+	}
+	if status := download(admin); status != fiber.StatusNotFound {
+		t.Fatalf("admin download: status = %d, want 404", status)
+	}
+
+	if resp, env := doRequest(t, app, httptest.NewRequest(fiber.MethodPost, "/users/"+owner.UUID.String()+"/erase", nil)); resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("erase: status = %d, body = %s", resp.StatusCode, env.Data)
+	}
+	if status := download(owner); status != fiber.StatusNotFound {
+		t.Fatalf("download after erase: status = %d, want 404", status)
+	}
+}
@@ -0,0 +1,227 @@
+package handler
+
+import (
+	"codebase-api/internal/domain"
+	"codebase-api/internal/usecase"
+	"codebase-api/pkg/auth"
+	helper "codebase-api/pkg/helpers"
+	middleware "codebase-api/pkg/middlewares"
+	"codebase-api/pkg/scim"
+	"encoding/json"
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+const scimMaxCount = 200
+
+type ScimHandler struct {
+	usecase   *usecase.ScimUseCase
+	userCache *auth.UserCache
+}
+
+func NewScimHandler(usecase *usecase.ScimUseCase, userCache *auth.UserCache) *ScimHandler {
+	return &ScimHandler{usecase: usecase, userCache: userCache}
+}
+
+func scimResponse(c *fiber.Ctx, status int, data interface{}) error {
+	return c.Status(status).JSON(data, scim.ContentType)
+}
+
+// scimErrorResponse menulis error dalam format SCIM. Error domain memakai status dari errorStatus,
+// kecuali validation yang di SCIM selalu 400 invalidValue
+func scimErrorResponse(c *fiber.Ctx, err error) error {
+	var scimErr *scim.Error
+	if errors.As(err, &scimErr) {
+		return scimResponse(c, scimErr.StatusCode(), scimErr)
+	}
+
+	var domainErr *domain.Error
+	if errors.As(err, &domainErr) {
+		if status, ok := errorStatus[domainErr.Kind]; ok {
+			var scimType string
+			switch {
+			case domainErr.Kind == domain.ErrValidation:
+				status, scimType = fiber.StatusBadRequest, "invalidValue"
+			case domainErr.Kind == domain.ErrConflict && len(domainErr.Fields) > 0:
+				scimType = "uniqueness"
+			}
+			return scimResponse(c, status, scim.NewError(status, scimType, domainErr.Message))
+		}
+	}
+
+	logrus.WithError(err).WithField("path", c.Path()).Error("unhandled scim error")
+	return scimResponse(c, fiber.StatusInternalServerError, scim.NewError(fiber.StatusInternalServerError, "", "Internal server error"))
+}
+
+func (h *ScimHandler) toResource(c *fiber.Ctx, user domain.User) scim.User {
+	resource := usecase.ToScimUser(user)
+	resource.Meta.Location = c.BaseURL() + "/scim/v2/Users/" + resource.ID
+	resource.Meta.Version = helper.VersionETag(user.Version)
+	return resource
+}
+
+// resourceResponse membalas satu resource User beserta ETag-nya (sama dengan meta.version)
+func (h *ScimHandler) resourceResponse(c *fiber.Ctx, status int, user domain.User) error {
+	resource := h.toResource(c, user)
+	c.Set(fiber.HeaderETag, resource.Meta.Version)
+	return scimResponse(c, status, resource)
+}
+
+// scimIfMatch membaca If-Match bila dikirim; berbeda dengan API biasa, SCIM tidak mewajibkannya (RFC 7644 3.14)
+func scimIfMatch(c *fiber.Ctx) (uint, error) {
+	header := c.Get(fiber.HeaderIfMatch)
+	if header == "" {
+		return 0, nil
+	}
+	version, err := helper.ParseIfMatch(header)
+	if err != nil {
+		return 0, scim.NewError(fiber.StatusPreconditionFailed, "", err.Error())
+	}
+	return version, nil
+}
+
+func parseScimUser(c *fiber.Ctx) (*scim.User, error) {
+	var resource scim.User
+	if err := json.Unmarshal(c.Body(), &resource); err != nil {
+		return nil, scim.NewError(fiber.StatusBadRequest, "invalidSyntax", "Invalid request body")
+	}
+	return &resource, nil
+}
+
+func (h *ScimHandler) ListUsers(c *fiber.Ctx) error {
+	startIndex, err := strconv.Atoi(c.Query("startIndex", "1"))
+	if err != nil || startIndex < 1 {
+		startIndex = 1
+	}
+
+	count, err := strconv.Atoi(c.Query("count", "100"))
+	if err != nil || count < 0 {
+		count = 100
+	}
+	if count > scimMaxCount {
+		count = scimMaxCount
+	}
+
+	users, total, err := h.usecase.List(middleware.ScimTenant(c), c.Query("filter"), startIndex, count)
+	if err != nil {
+		return scimErrorResponse(c, err)
+	}
+
+	resources := []scim.User{}
+	for _, user := range users {
+		resources = append(resources, h.toResource(c, user))
+	}
+
+	return scimResponse(c, fiber.StatusOK, scim.ListResponse{
+		Schemas:      []string{scim.ListResponseSchema},
+		TotalResults: total,
+		StartIndex:   startIndex,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	})
+}
+
+func (h *ScimHandler) GetUser(c *fiber.Ctx) error {
+	user, err := h.usecase.Get(middleware.ScimTenant(c), c.Params("id"))
+	if err != nil {
+		return scimErrorResponse(c, err)
+	}
+	return h.resourceResponse(c, fiber.StatusOK, *user)
+}
+
+func (h *ScimHandler) CreateUser(c *fiber.Ctx) error {
+	resource, err := parseScimUser(c)
+	if err != nil {
+		return scimErrorResponse(c, err)
+	}
+
+	user, err := h.usecase.Create(middleware.ScimTenant(c), resource)
+	if err != nil {
+		return scimErrorResponse(c, err)
+	}
+
+	c.Location(h.toResource(c, *user).Meta.Location)
+	return h.resourceResponse(c, fiber.StatusCreated, *user)
+}
+
+func (h *ScimHandler) ReplaceUser(c *fiber.Ctx) error {
+	version, err := scimIfMatch(c)
+	if err != nil {
+		return scimErrorResponse(c, err)
+	}
+	resource, err := parseScimUser(c)
+	if err != nil {
+		return scimErrorResponse(c, err)
+	}
+
+	user, err := h.usecase.Replace(middleware.ScimTenant(c), c.Params("id"), resource, version)
+	if err != nil {
+		return scimErrorResponse(c, err)
+	}
+	// active=false harus langsung berlaku untuk sesi yang sedang berjalan
+	h.userCache.Invalidate(user.ID)
+	return h.resourceResponse(c, fiber.StatusOK, *user)
+}
+
+func (h *ScimHandler) PatchUser(c *fiber.Ctx) error {
+	version, err := scimIfMatch(c)
+	if err != nil {
+		return scimErrorResponse(c, err)
+	}
+	var input scim.PatchRequest
+	if err := json.Unmarshal(c.Body(), &input); err != nil {
+		return scimErrorResponse(c, scim.NewError(fiber.StatusBadRequest, "invalidSyntax", "Invalid request body"))
+	}
+
+	user, err := h.usecase.Patch(middleware.ScimTenant(c), c.Params("id"), input.Operations, version)
+	if err != nil {
+		return scimErrorResponse(c, err)
+	}
+	h.userCache.Invalidate(user.ID)
+	return h.resourceResponse(c, fiber.StatusOK, *user)
+}
+
+func (h *ScimHandler) DeleteUser(c *fiber.Ctx) error {
+	user, err := h.usecase.Delete(middleware.ScimTenant(c), c.Params("id"))
+	if err != nil {
+		return scimErrorResponse(c, err)
+	}
+	h.userCache.Invalidate(user.ID)
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *ScimHandler) ServiceProviderConfig(c *fiber.Ctx) error {
+	return scimResponse(c, fiber.StatusOK, fiber.Map{
+		"schemas":        []string{scim.ServiceProviderConfigSchema},
+		"patch":          fiber.Map{"supported": true},
+		"bulk":           fiber.Map{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+		"filter":         fiber.Map{"supported": true, "maxResults": scimMaxCount},
+		"changePassword": fiber.Map{"supported": true},
+		"sort":           fiber.Map{"supported": false},
+		"etag":           fiber.Map{"supported": true},
+		"authenticationSchemes": []fiber.Map{{
+			"type":        "oauthbearertoken",
+			"name":        "Bearer Token",
+			"description": "Dedicated bearer token per tenant",
+		}},
+	})
+}
+
+func (h *ScimHandler) ResourceTypes(c *fiber.Ctx) error {
+	return scimResponse(c, fiber.StatusOK, scim.ListResponse{
+		Schemas:      []string{scim.ListResponseSchema},
+		TotalResults: 1,
+		StartIndex:   1,
+		ItemsPerPage: 1,
+		Resources: []fiber.Map{{
+			"schemas":  []string{scim.ResourceTypeSchema},
+			"id":       "User",
+			"name":     "User",
+			"endpoint": "/Users",
+			"schema":   scim.UserSchema,
+		}},
+	})
+}
@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"codebase-api/internal/domain"
+	"codebase-api/internal/repository"
+	"codebase-api/internal/testdb"
+	"codebase-api/internal/usecase"
+	"codebase-api/pkg/auth"
+	helper "codebase-api/pkg/helpers"
+	"codebase-api/pkg/scim"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestScimErrorResponse(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		status   int
+		scimType string
+	}{
+		{name: "scim error", err: scim.NewError(fiber.StatusBadRequest, "invalidFilter", "bad filter"), status: fiber.StatusBadRequest, scimType: "invalidFilter"},
+		{name: "stale version", err: domain.ErrStaleVersion, status: fiber.StatusConflict},
+		{name: "field conflict", err: domain.FieldConflict("email"), status: fiber.StatusConflict, scimType: "uniqueness"},
+		{name: "version mismatch", err: domain.ErrVersionMismatch, status: fiber.StatusPreconditionFailed},
+		{name: "not found", err: domain.NotFound("user not found"), status: fiber.StatusNotFound},
+		{name: "validation", err: domain.Invalid("email", "email is invalid"), status: fiber.StatusBadRequest, scimType: "invalidValue"},
+		{name: "infrastructure", err: errors.New("database down"), status: fiber.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Get("/", func(c *fiber.Ctx) error {
+				return scimErrorResponse(c, tt.err)
+			})
+
+			resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/", nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var body scim.Error
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != tt.status || body.StatusCode() != tt.status || body.ScimType != tt.scimType {
+				t.Fatalf("status = %d (%s), scimType = %q, want %d %q", resp.StatusCode, body.Status, body.ScimType, tt.status, tt.scimType)
+			}
+			if resp.Header.Get(fiber.HeaderContentType) != scim.ContentType {
+				t.Fatalf("content type = %q", resp.Header.Get(fiber.HeaderContentType))
+			}
+		})
+	}
+}
+
+func TestScimReplaceAndPatchHonorIfMatch(t *testing.T) {
+	scimUseCase := usecase.NewScimUseCase(repository.NewUserRepository(testdb.Open(t)))
+	user, err := scimUseCase.Create("", &scim.User{UserName: "budi", Emails: []scim.MultiValue{{Value: "budi@example.com"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := NewScimHandler(scimUseCase, auth.NewUserCache(time.Minute))
+	app := fiber.New()
+	app.Put("/scim/v2/Users/:id", h.ReplaceUser)
+	app.Patch("/scim/v2/Users/:id", h.PatchUser)
+	send := func(method string, ifMatch string, body string) (int, string) {
+		req := httptest.NewRequest(method, "/scim/v2/Users/"+user.UUID.String(), strings.NewReader(body))
+		req.Header.Set(fiber.HeaderContentType, scim.ContentType)
+		if ifMatch != "" {
+			req.Header.Set(fiber.HeaderIfMatch, ifMatch)
+		}
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp.StatusCode, resp.Header.Get(fiber.HeaderETag)
+	}
+	replace := `{"userName":"budi","name":{"givenName":"Budi"},"emails":[{"value":"budi@example.com"}]}`
+	patch := `{"Operations":[{"op":"replace","path":"name.familyName","value":"Santoso"}]}`
+
+	status, etag := send(fiber.MethodPut, helper.VersionETag(user.Version), replace)
+	if status != fiber.StatusOK || etag != helper.VersionETag(user.Version+1) {
+		t.Fatalf("replace: status = %d, ETag = %q", status, etag)
+	}
+	if status, _ := send(fiber.MethodPatch, helper.VersionETag(user.Version), patch); status != fiber.StatusPreconditionFailed {
+		t.Fatalf("patch with stale If-Match: status = %d, want 412", status)
+	}
+	if status, _ := send(fiber.MethodPut, `W/"2"`, replace); status != fiber.StatusPreconditionFailed {
+		t.Fatalf("weak If-Match: status = %d, want 412", status)
+	}
+	if status, next := send(fiber.MethodPatch, etag, patch); status != fiber.StatusOK || next != helper.VersionETag(user.Version+2) {
+		t.Fatalf("patch: status = %d, ETag = %q", status, next)
+	}
+	// If-Match opsional bagi client SCIM
+	if status, _ := send(fiber.MethodPatch, "", patch); status != fiber.StatusOK {
+		t.Fatalf("patch without If-Match: status = %d, want 200", status)
+	}
+}
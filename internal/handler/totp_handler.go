@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"codebase-api/internal/usecase"
+	"codebase-api/pkg/auth"
+	helper "codebase-api/pkg/helpers"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type TOTPHandler struct {
+	usecase   *usecase.TOTPUseCase
+	userCache *auth.UserCache
+	validate  *validator.Validate
+}
+
+func NewTOTPHandler(usecase *usecase.TOTPUseCase, userCache *auth.UserCache) *TOTPHandler {
+	return &TOTPHandler{usecase: usecase, userCache: userCache, validate: validator.New()}
+}
+
+// Enroll (POST /users/me/totp) membuat secret baru; secret dan otpauth URL hanya ditampilkan sekali
+func (h *TOTPHandler) Enroll(c *fiber.Ctx) error {
+	user, ok := auth.User(c)
+	if !ok {
+		return helper.ErrorResponse(c, fiber.StatusUnauthorized, "User not found", nil)
+	}
+
+	secret, uri, err := h.usecase.Enroll(user)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	h.userCache.Invalidate(user.ID)
+	return helper.SuccessResponse(c, fiber.Map{"secret": secret, "otpauth_url": uri}, "Scan the code with your authenticator app and confirm it")
+}
+
+// Confirm (POST /users/me/totp/confirm) mengaktifkan TOTP dengan kode pertama dari authenticator
+func (h *TOTPHandler) Confirm(c *fiber.Ctx) error {
+	user, ok := auth.User(c)
+	if !ok {
+		return helper.ErrorResponse(c, fiber.StatusUnauthorized, "User not found", nil)
+	}
+
+	var input struct {
+		Code string `json:"code" validate:"required,len=6,numeric"`
+	}
+
+	if err := c.BodyParser(&input); err != nil {
+		return helper.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request", err)
+	}
+
+	if err := h.validate.Struct(&input); err != nil {
+		return helper.ErrorResponse(c, fiber.StatusUnprocessableEntity, map[string]string{"code": "Code must be 6 digits"}, nil)
+	}
+
+	enabled, err := h.usecase.Confirm(user, input.Code)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	h.userCache.Invalidate(enabled.ID)
+	return helper.SuccessResponse(c, ToProfileResponseDto(enabled), "Two-factor authentication enabled")
+}
+
+// Disable (DELETE /users/me/totp) mematikan TOTP
+func (h *TOTPHandler) Disable(c *fiber.Ctx) error {
+	user, ok := auth.User(c)
+	if !ok {
+		return helper.ErrorResponse(c, fiber.StatusUnauthorized, "User not found", nil)
+	}
+
+	disabled, err := h.usecase.Disable(user)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	h.userCache.Invalidate(disabled.ID)
+	return helper.SuccessResponse(c, ToProfileResponseDto(disabled), "Two-factor authentication disabled")
+}
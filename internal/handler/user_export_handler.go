@@ -0,0 +1,292 @@
+package handler
+
+import (
+	"bufio"
+	"codebase-api/internal/domain"
+	"codebase-api/internal/usecase"
+	"codebase-api/pkg/auth"
+	helper "codebase-api/pkg/helpers"
+	"codebase-api/pkg/jobs"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/sirupsen/logrus"
+	"github.com/xuri/excelize/v2"
+)
+
+// Export dengan jumlah baris di atas ini dijalankan sebagai background job
+const exportSyncLimit = 10000
+
+// Kolom default export mengikuti urutan UserResponseDto
+var userExportColumns = []string{"id", "first_name", "last_name", "username", "email", "phone", "is_active"}
+
+var exportContentTypes = map[string]string{
+	"csv":    "text/csv",
+	"ndjson": "application/x-ndjson",
+	"xlsx":   "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+type UserExportHandler struct {
+	usecase *usecase.UserUseCase
+	jobs    *jobs.Registry
+}
+
+func NewUserExportHandler(usecase *usecase.UserUseCase, registry *jobs.Registry) *UserExportHandler {
+	return &UserExportHandler{usecase: usecase, jobs: registry}
+}
+
+// Export menerima filter yang sama dengan Searching ditambah format=csv|ndjson|xlsx, fields dan async=true
+func (h *UserExportHandler) Export(c *fiber.Ctx) error {
+	user, ok := auth.User(c)
+	if !ok {
+		return helper.ErrorResponse(c, fiber.StatusUnauthorized, "User not found", nil)
+	}
+
+	format := utils.CopyString(c.Query("format", "csv"))
+	contentType, ok := exportContentTypes[format]
+	if !ok {
+		return helper.ErrorResponse(c, fiber.StatusBadRequest, "Invalid export format", errors.New("format must be csv, ndjson or xlsx"))
+	}
+
+	params, err := parseUserListParams(c)
+	if err != nil {
+		return helper.ErrorResponse(c, fiber.StatusBadRequest, "Invalid query parameters", err)
+	}
+
+	columns := params.projection.Fields
+	if len(columns) == 0 {
+		columns = userExportColumns
+	}
+	columns = exportColumns(columns)
+	filename := fmt.Sprintf("users-%s.%s", time.Now().Format("20060102-150405"), format)
+
+	export := func(ctx context.Context, w io.Writer) error {
+		writer, err := newExportWriter(format, w, columns)
+		if err != nil {
+			return err
+		}
+
+		err = h.usecase.Export(ctx, params.filter, params.query, func(users []domain.User) error {
+			for _, u := range users {
+				if err := writer.WriteRow(exportValues(ToUserResponseDto(u), columns)); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		return writer.Close()
+	}
+
+	async := c.QueryBool("async")
+	if !async {
+		total, err := h.usecase.CountMatching(params.filter, params.query)
+		if err != nil {
+			return errorResponse(c, err)
+		}
+		async = total > exportSyncLimit
+	}
+
+	if async {
+		job := h.jobs.Submit("user_export", user.ID, func(ctx context.Context, job *jobs.Job) error {
+			file, err := job.CreateResult(filename, contentType)
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+
+			buffered := bufio.NewWriter(file)
+			if err := export(ctx, buffered); err != nil {
+				return err
+			}
+			return buffered.Flush()
+		})
+		return helper.AcceptedResponse(c, ToJobResponseDto(job), "Export queued")
+	}
+
+	c.Set(fiber.HeaderContentType, contentType)
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="`+filename+`"`)
+	// Baris dikirim ke client sambil dibaca dari database; error di tengah stream hanya bisa dicatat
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		if err := export(context.Background(), w); err != nil {
+			logrus.WithError(err).Error("user export failed")
+		}
+		w.Flush()
+	})
+	return nil
+}
+
+// exportColumns mengganti field avatar (map ukuran -> URL) dengan satu kolom per ukuran, mis. avatar_64,
+// karena CSV dan XLSX hanya bisa menampung nilai skalar
+func exportColumns(fields []string) []string {
+	columns := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if field != "avatar" {
+			columns = append(columns, field)
+			continue
+		}
+		for _, size := range domain.AvatarSizes {
+			columns = append(columns, "avatar_"+strconv.Itoa(size))
+		}
+	}
+	return columns
+}
+
+// exportValues mengambil nilai kolom langsung dari field DTO; nama kolom sama dengan field JSON API.
+// Pointer di-dereference sehingga nilai kosong menjadi nil (sel kosong / null)
+func exportValues(dto UserResponseDto, columns []string) []interface{} {
+	values := make([]interface{}, len(columns))
+	for i, column := range columns {
+		switch column {
+		case "id":
+			values[i] = dto.ID
+		case "legacy_id":
+			if dto.LegacyID != nil {
+				values[i] = *dto.LegacyID
+			}
+		case "first_name":
+			values[i] = dto.FirstName
+		case "last_name":
+			values[i] = dto.LastName
+		case "username":
+			values[i] = dto.Username
+		case "email":
+			values[i] = dto.Email
+		case "phone":
+			if dto.Phone != nil {
+				values[i] = *dto.Phone
+			}
+		case "is_active":
+			values[i] = dto.IsActive
+		case "deleted_at":
+			if dto.DeletedAt != nil {
+				values[i] = *dto.DeletedAt
+			}
+		default:
+			if size, ok := strings.CutPrefix(column, "avatar_"); ok {
+				if url, ok := dto.Avatar[size]; ok {
+					values[i] = url
+				}
+			}
+		}
+	}
+	return values
+}
+
+type exportWriter interface {
+	WriteRow(values []interface{}) error
+	Close() error
+}
+
+func newExportWriter(format string, w io.Writer, columns []string) (exportWriter, error) {
+	switch format {
+	case "ndjson":
+		return &ndjsonExportWriter{encoder: json.NewEncoder(w), columns: columns}, nil
+	case "xlsx":
+		return newXLSXExportWriter(w, columns)
+	}
+	return newCSVExportWriter(w, columns)
+}
+
+type csvExportWriter struct {
+	writer *csv.Writer
+}
+
+func newCSVExportWriter(w io.Writer, columns []string) (*csvExportWriter, error) {
+	writer := csv.NewWriter(w)
+	return &csvExportWriter{writer: writer}, writer.Write(columns)
+}
+
+func (e *csvExportWriter) WriteRow(values []interface{}) error {
+	record := make([]string, len(values))
+	for i, value := range values {
+		switch v := value.(type) {
+		case nil:
+		case string:
+			record[i] = v
+		case bool:
+			record[i] = strconv.FormatBool(v)
+		case uint:
+			record[i] = strconv.FormatUint(uint64(v), 10)
+		case time.Time:
+			record[i] = v.UTC().Format(time.RFC3339)
+		default:
+			record[i] = fmt.Sprint(v)
+		}
+	}
+	return e.writer.Write(record)
+}
+
+func (e *csvExportWriter) Close() error {
+	e.writer.Flush()
+	return e.writer.Error()
+}
+
+type ndjsonExportWriter struct {
+	encoder *json.Encoder
+	columns []string
+}
+
+func (e *ndjsonExportWriter) WriteRow(values []interface{}) error {
+	row := make(map[string]interface{}, len(values))
+	for i, value := range values {
+		row[e.columns[i]] = value
+	}
+	return e.encoder.Encode(row)
+}
+
+func (e *ndjsonExportWriter) Close() error {
+	return nil
+}
+
+// xlsxExportWriter memakai StreamWriter excelize yang menyimpan baris ke file sementara, bukan memori
+type xlsxExportWriter struct {
+	file   *excelize.File
+	stream *excelize.StreamWriter
+	out    io.Writer
+	row    int
+}
+
+func newXLSXExportWriter(w io.Writer, columns []string) (*xlsxExportWriter, error) {
+	file := excelize.NewFile()
+	stream, err := file.NewStreamWriter("Sheet1")
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	e := &xlsxExportWriter{file: file, stream: stream, out: w}
+	header := make([]interface{}, len(columns))
+	for i, column := range columns {
+		header[i] = column
+	}
+	return e, e.WriteRow(header)
+}
+
+func (e *xlsxExportWriter) WriteRow(values []interface{}) error {
+	e.row++
+	cell, err := excelize.CoordinatesToCellName(1, e.row)
+	if err != nil {
+		return err
+	}
+	return e.stream.SetRow(cell, values)
+}
+
+func (e *xlsxExportWriter) Close() error {
+	defer e.file.Close()
+	if err := e.stream.Flush(); err != nil {
+		return err
+	}
+	return e.file.Write(e.out)
+}
@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"bytes"
+	"codebase-api/config"
+	"codebase-api/internal/domain"
+	"codebase-api/internal/repository"
+	"codebase-api/internal/testdb"
+	"codebase-api/internal/usecase"
+	"codebase-api/pkg/jobs"
+	"encoding/csv"
+	"io"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/xuri/excelize/v2"
+)
+
+func newExportApp(t *testing.T) (*fiber.App, *domain.User) {
+	t.Helper()
+
+	userRepo := repository.NewUserRepository(testdb.Open(t))
+	admin := newTestUser(t, userRepo, "root", domain.RoleAdmin)
+
+	budi := newTestUser(t, userRepo, "budi", domain.RoleUser)
+	phone, avatar := "+6281234567890", "avatars/budi/abc"
+	budi.Phone, budi.AvatarPath = &phone, &avatar
+	if err := userRepo.Update(budi); err != nil {
+		t.Fatal(err)
+	}
+
+	registry := jobs.NewRegistry(time.Hour)
+	t.Cleanup(registry.Close)
+
+	h := NewUserExportHandler(usecase.NewUserUseCase(userRepo), registry)
+	app := fiber.New()
+	app.Get("/users/export", asUser(admin), h.Export)
+	return app, budi
+}
+
+func exportBody(t *testing.T, app *fiber.App, query string) []byte {
+	t.Helper()
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/users/export"+query, nil), -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("status = %d, body = %s", resp.StatusCode, body)
+	}
+	return body
+}
+
+// expectedExportRows adalah isi export fields=username,phone,avatar,is_active yang diurutkan per username
+func expectedExportRows(budi *domain.User) [][]string {
+	return [][]string{
+		{"username", "phone", "avatar_64", "avatar_128", "avatar_256", "avatar_512", "is_active"},
+		{"budi", "+6281234567890",
+			config.UploadURL(budi.AvatarKeys()[64]), config.UploadURL(budi.AvatarKeys()[128]),
+			config.UploadURL(budi.AvatarKeys()[256]), config.UploadURL(budi.AvatarKeys()[512]),
+			"true"},
+		{"root", "", "", "", "", "", "true"},
+	}
+}
+
+const exportQuery = "?fields=username,phone,avatar,is_active&sort=username"
+
+func TestExportCSVRoundTrip(t *testing.T) {
+	app, budi := newExportApp(t)
+
+	rows, err := csv.NewReader(bytes.NewReader(exportBody(t, app, exportQuery+"&format=csv"))).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := expectedExportRows(budi); !reflect.DeepEqual(rows, want) {
+		t.Fatalf("csv rows = %q, want %q", rows, want)
+	}
+}
+
+func TestExportXLSXRoundTrip(t *testing.T) {
+	app, budi := newExportApp(t)
+
+	file, err := excelize.OpenReader(bytes.NewReader(exportBody(t, app, exportQuery+"&format=xlsx")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	rows, err := file.GetRows(file.GetSheetName(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// GetRows memotong sel kosong di akhir baris, ratakan dulu sebelum dibandingkan.
+	// is_active disimpan sebagai sel boolean sehingga terbaca TRUE
+	want := expectedExportRows(budi)
+	for i := range rows {
+		for len(rows[i]) < len(want[0]) {
+			rows[i] = append(rows[i], "")
+		}
+	}
+	for _, row := range want[1:] {
+		row[len(row)-1] = "TRUE"
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Fatalf("xlsx rows = %q, want %q", rows, want)
+	}
+}
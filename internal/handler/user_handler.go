@@ -1,25 +1,54 @@
 package handler
 
 import (
+	"codebase-api/config"
 	"codebase-api/internal/domain"
+	"codebase-api/internal/repository"
 	"codebase-api/internal/usecase"
+	"codebase-api/pkg/auth"
 	helper "codebase-api/pkg/helpers"
+	"codebase-api/pkg/identity"
+	middleware "codebase-api/pkg/middlewares"
+	"codebase-api/pkg/query"
 	"strconv"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
-	"github.com/spf13/cast"
+	"github.com/gofiber/fiber/v2/utils"
 )
 
 type UserResponseDto struct {
-	ID        int    `json:"id"`
-	UUID      string `json:"uuid"`
-	FirstName string `json:"first_name"`
-	LastName  string `json:"last_name"`
-	Username  string `json:"username"`
-	Email     string `json:"email"`
-	Phone     string `json:"phone"`
-	IsActive  bool   `json:"is_active"`
+	ID string `json:"id"`
+	// LegacyID hanya dikirim saat LEGACY_NUMERIC_IDS aktif
+	LegacyID  *uint   `json:"legacy_id,omitempty"`
+	FirstName string  `json:"first_name"`
+	LastName  string  `json:"last_name"`
+	Username  string  `json:"username"`
+	Email     string  `json:"email"`
+	Phone     *string `json:"phone"`
+	IsActive  bool    `json:"is_active"`
+	// Avatar berisi URL thumbnail per ukuran (pixel), kosong jika belum ada avatar
+	Avatar map[string]string `json:"avatar,omitempty"`
+	// Hanya terisi pada daftar user di trash
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+// UserProjection adalah allow-list ?fields= untuk UserResponseDto beserta kolom sumbernya
+var UserProjection = helper.ProjectionSchema{
+	Fields: map[string][]string{
+		"id":         {"uuid"},
+		"legacy_id":  {"id"},
+		"first_name": {"first_name"},
+		"last_name":  {"last_name"},
+		"username":   {"username"},
+		"email":      {"email"},
+		"phone":      {"phone"},
+		"is_active":  {"is_active"},
+		"avatar":     {"avatar_path"},
+		"deleted_at": {"deleted_at"},
+	},
+	Required: []string{"id", "created_at"},
 }
 
 func ToUserResponseDto(user interface{}) UserResponseDto {
@@ -33,9 +62,8 @@ func ToUserResponseDto(user interface{}) UserResponseDto {
 		u = v // Langsung gunakan nilai
 	}
 
-	return UserResponseDto{
-		ID:        int(u.ID),
-		UUID:      u.UUID.String(),
+	dto := UserResponseDto{
+		ID:        u.UUID.String(),
 		FirstName: u.FirstName,
 		LastName:  u.LastName,
 		Username:  u.Username,
@@ -43,32 +71,153 @@ func ToUserResponseDto(user interface{}) UserResponseDto {
 		Phone:     u.Phone,
 		IsActive:  u.IsActive,
 	}
+	if keys := u.AvatarKeys(); keys != nil {
+		dto.Avatar = make(map[string]string, len(keys))
+		for size, key := range keys {
+			dto.Avatar[strconv.Itoa(size)] = config.UploadURL(key)
+		}
+	}
+	if u.DeletedAt.Valid {
+		dto.DeletedAt = &u.DeletedAt.Time
+	}
+	if config.LegacyNumericIDs() {
+		id := u.ID
+		dto.LegacyID = &id
+	}
+	return dto
+}
+
+// ProfileResponseDto adalah UserResponseDto ditambah field yang hanya ditampilkan ke pemilik akun
+type ProfileResponseDto struct {
+	UserResponseDto
+	Role          string    `json:"role"`
+	EmailVerified bool      `json:"email_verified"`
+	PhoneVerified bool      `json:"phone_verified"`
+	TOTPEnabled   bool      `json:"totp_enabled"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// ProfileProjection adalah allow-list ?fields= untuk ProfileResponseDto
+var ProfileProjection = UserProjection.Extend(map[string][]string{
+	"role":           {"role"},
+	"email_verified": {"email_verified_at"},
+	"phone_verified": {"phone_verified_at"},
+	"totp_enabled":   {"totp_enabled_at"},
+	"created_at":     {"created_at"},
+	"updated_at":     {"updated_at"},
+})
+
+func ToProfileResponseDto(user *domain.User) ProfileResponseDto {
+	return ProfileResponseDto{
+		UserResponseDto: ToUserResponseDto(user),
+		Role:            user.Role,
+		EmailVerified:   user.EmailVerifiedAt != nil,
+		PhoneVerified:   user.PhoneVerifiedAt != nil,
+		TOTPEnabled:     user.TOTPEnabledAt != nil,
+		CreatedAt:       user.CreatedAt,
+		UpdatedAt:       user.UpdatedAt,
+	}
 }
 
+// userETagVariant membedakan representasi user dengan version yang sama: field dari ?fields= dan
+// legacy_id yang hanya muncul bila LEGACY_NUMERIC_IDS aktif. projection nil berarti representasi penuh
+func userETagVariant(projection *helper.Projection) []string {
+	var variant []string
+	if projection != nil {
+		variant = append(variant, projection.Variant())
+	}
+	if config.LegacyNumericIDs() {
+		variant = append(variant, "legacy_id")
+	}
+	return variant
+}
+
+// etagColumns selalu ikut di-select pada GET satu user karena ETag dan Last-Modified dibangun darinya
+var etagColumns = []string{"version", "updated_at"}
+
 type UserHandler struct {
-	usecase  *usecase.UserUseCase
-	validate *validator.Validate
+	usecase   *usecase.UserUseCase
+	groups    *usecase.GroupUseCase
+	userCache *auth.UserCache
+	validate  *validator.Validate
 }
 
-func NewUserHandler(usecase *usecase.UserUseCase) *UserHandler {
-	return &UserHandler{usecase: usecase, validate: validator.New()}
+func NewUserHandler(usecase *usecase.UserUseCase, groups *usecase.GroupUseCase, userCache *auth.UserCache) *UserHandler {
+	return &UserHandler{usecase: usecase, groups: groups, userCache: userCache, validate: validator.New()}
 }
 
 func (h *UserHandler) All(c *fiber.Ctx) error {
-	users, err := h.usecase.FinAll()
+	projection, err := helper.ParseProjection(c, UserProjection)
 	if err != nil {
-		return helper.ErrorResponse(c, fiber.StatusBadRequest, "Failed to fetch users", err)
+		return helper.ErrorResponse(c, fiber.StatusBadRequest, "Invalid query parameters", err)
+	}
 
+	stamp, err := h.usecase.Stamp()
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	if helper.NotModified(c, helper.CollectionETag(stamp.Count, stamp.LastModified), stamp.LastModified) {
+		return c.SendStatus(fiber.StatusNotModified)
 	}
-	var dto []UserResponseDto
+
+	users, err := h.usecase.FinAll()
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	var dto []interface{}
 	for _, user := range users {
 		dto = append(dto, ToUserResponseDto(user))
 	}
 
+	dto, err = projection.ApplyAll(dto)
+	if err != nil {
+		return helper.ErrorResponse(c, fiber.StatusInternalServerError, "Internal server error", nil)
+	}
 	return helper.SuccessResponse(c, dto, "Fetch all data users success")
 }
 
-func (h *UserHandler) Searching(c *fiber.Ctx) error {
+// userListParams adalah filter list user yang dipakai bersama oleh Searching dan export
+type userListParams struct {
+	filter     repository.UserFilter
+	query      query.ListQuery
+	projection *helper.Projection
+}
+
+// Semua nilai disalin karena params bisa dipakai export di luar umur request
+func parseUserListParams(c *fiber.Ctx) (*userListParams, error) {
+	queries := make(map[string]string)
+	for key, value := range c.Queries() {
+		queries[utils.CopyString(key)] = utils.CopyString(value)
+	}
+
+	params := &userListParams{filter: repository.UserFilter{
+		Search:  queries["search"],
+		GroupID: queries["group"],
+	}}
+
+	if isActiveParam := queries["status"]; isActiveParam != "" {
+		active := isActiveParam == "true"
+		params.filter.IsActive = &active
+	}
+
+	listQuery, err := query.Parse(queries, repository.UserListSchema)
+	if err != nil {
+		return nil, err
+	}
+
+	projection, err := helper.ParseProjection(c, UserProjection)
+	if err != nil {
+		return nil, err
+	}
+	listQuery.Select = projection.Columns()
+
+	params.query, params.projection = listQuery, projection
+	return params, nil
+}
+
+// parsePageRequest membaca page, pageSize (maks 100), after dan before dari query string
+func parsePageRequest(c *fiber.Ctx) repository.PageRequest {
 	page, err := strconv.Atoi(c.Query("page", "1"))
 	if err != nil || page < 1 {
 		page = 1
@@ -78,54 +227,361 @@ func (h *UserHandler) Searching(c *fiber.Ctx) error {
 	if err != nil || pageSize < 1 {
 		pageSize = 10
 	}
+	if pageSize > 100 {
+		pageSize = 100
+	}
 
-	search := c.Query("search", "")
-	isActiveParam := c.Query("status", "")
+	return repository.PageRequest{
+		Page:     page,
+		PageSize: pageSize,
+		After:    c.Query("after"),
+		Before:   c.Query("before"),
+	}
+}
 
-	var isActive *bool
-	if isActiveParam != "" {
-		active := isActiveParam == "true"
-		isActive = &active
+// toPagination mengisi total hanya pada mode offset; mode cursor tidak menjalankan COUNT
+func toPagination[T any](result *repository.Page[T], req repository.PageRequest) helper.Pagination {
+	pagination := helper.Pagination{
+		PageSize:   result.PageSize,
+		NextCursor: result.NextCursor,
+		PrevCursor: result.PrevCursor,
+	}
+	if !req.IsCursor() {
+		pagination.Page = result.Page
+		pagination.TotalItems = &result.TotalItems
+		pagination.TotalPages = &result.TotalPages
 	}
+	return pagination
+}
+
+func (h *UserHandler) Searching(c *fiber.Ctx) error {
+	req := parsePageRequest(c)
 
-	users, err := h.usecase.Searching(isActive, search)
+	params, err := parseUserListParams(c)
 	if err != nil {
-		return helper.ErrorResponse(c, fiber.StatusBadRequest, "Failed to fetch users", err)
+		return helper.ErrorResponse(c, fiber.StatusBadRequest, "Invalid query parameters", err)
 	}
 
-	totalItems := len(users)
-	totalPages := (totalItems + pageSize - 1) / pageSize
+	// Filter group hanya boleh dipakai admin atau anggota group tersebut
+	if params.filter.GroupID != "" {
+		user, ok := auth.User(c)
+		if !ok {
+			return helper.ErrorResponse(c, fiber.StatusUnauthorized, "User not found", nil)
+		}
+		if _, err := h.groups.FindVisible(user, params.filter.GroupID); err != nil {
+			return errorResponse(c, err)
+		}
+	}
 
-	start := (page - 1) * pageSize
-	end := start + pageSize
-	if start > totalItems {
-		start = totalItems
+	// Collection yang tidak berubah dijawab 304 sebelum halaman dimuat dan diserialisasi
+	stamp, err := h.usecase.StampMatching(params.filter, params.query)
+	if err != nil {
+		return errorResponse(c, err)
 	}
-	if end > totalItems {
-		end = totalItems
+	if helper.NotModified(c, helper.CollectionETag(stamp.Count, stamp.LastModified), stamp.LastModified) {
+		return c.SendStatus(fiber.StatusNotModified)
 	}
 
-	paginatedUsers := users[start:end]
-
-	var dto []UserResponseDto
-	for _, user := range paginatedUsers {
-		dto = append(dto, ToUserResponseDto(user))
+	result, err := h.usecase.Searching(params.filter, params.query, req)
+	if err != nil {
+		return errorResponse(c, err)
 	}
 
 	var data []interface{}
-	for _, user := range dto {
-		data = append(data, user)
+	for _, user := range result.Items {
+		data = append(data, ToUserResponseDto(user))
 	}
 
-	return helper.PaginationResponse(c, data, page, pageSize, totalItems, totalPages, "Fetch all data users success")
+	data, err = params.projection.ApplyAll(data)
+	if err != nil {
+		return helper.ErrorResponse(c, fiber.StatusInternalServerError, "Internal server error", nil)
+	}
+
+	return helper.PaginationResponse(c, data, toPagination(result, req), "Fetch all data users success")
 }
 
 func (h *UserHandler) Detail(c *fiber.Ctx) error {
-	user, err := h.usecase.FindById(cast.ToUint(c.Params("id")))
+	projection, err := helper.ParseProjection(c, UserProjection)
+	if err != nil {
+		return helper.ErrorResponse(c, fiber.StatusBadRequest, "Invalid query parameters", err)
+	}
+
+	id, err := h.usecase.ResolveID(c.Params("id"))
 	if err != nil {
-		return helper.ErrorResponse(c, fiber.StatusNotFound, "User not found", err)
+		return errorResponse(c, err)
 	}
 
-	dto := ToUserResponseDto(user)
+	user, err := h.usecase.FindProjected(id, projection.Columns(etagColumns...))
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	if helper.NotModified(c, helper.VersionETag(user.Version, userETagVariant(projection)...), user.UpdatedAt) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+
+	dto, err := projection.Apply(ToUserResponseDto(user))
+	if err != nil {
+		return helper.ErrorResponse(c, fiber.StatusInternalServerError, "Internal server error", nil)
+	}
 	return helper.SuccessResponse(c, dto, "Fetch data users success")
 }
+
+func (h *UserHandler) Me(c *fiber.Ctx) error {
+	cached, ok := auth.User(c)
+	if !ok {
+		return helper.ErrorResponse(c, fiber.StatusUnauthorized, "User not found", nil)
+	}
+
+	projection, err := helper.ParseProjection(c, ProfileProjection)
+	if err != nil {
+		return helper.ErrorResponse(c, fiber.StatusBadRequest, "Invalid query parameters", err)
+	}
+
+	// ETag dibangun dari baris terbaru, bukan dari cache LoadUser yang bisa tertinggal sampai 30 detik
+	user, err := h.usecase.FindProjected(cached.ID, projection.Columns(etagColumns...))
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	if helper.NotModified(c, helper.VersionETag(user.Version, userETagVariant(projection)...), user.UpdatedAt) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+
+	dto, err := projection.Apply(ToProfileResponseDto(user))
+	if err != nil {
+		return helper.ErrorResponse(c, fiber.StatusInternalServerError, "Internal server error", nil)
+	}
+	return helper.SuccessResponse(c, dto, "Fetch profile success")
+}
+
+// UpdateMe mengubah profil milik sendiri; email diubah lewat alur terpisah
+func (h *UserHandler) UpdateMe(c *fiber.Ctx) error {
+	claims, _ := auth.CurrentUser(c)
+
+	var input struct {
+		FirstName *string `json:"first_name" validate:"omitempty,min=1"`
+		LastName  *string `json:"last_name" validate:"omitempty,min=1"`
+		Username  *string `json:"username" validate:"omitempty,min=1"`
+		Phone     *string `json:"phone" validate:"omitempty,e164"`
+	}
+
+	if err := c.BodyParser(&input); err != nil {
+		return helper.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request", err)
+	}
+
+	if err := h.validate.Struct(&input); err != nil {
+		errorFields := helper.ValidationErrorFormatter(err, input)
+		return helper.ErrorResponse(c, fiber.StatusUnprocessableEntity, errorFields, nil)
+	}
+
+	user, err := h.usecase.Update(claims.ID, usecase.UserUpdate{
+		FirstName: input.FirstName,
+		LastName:  input.LastName,
+		Username:  input.Username,
+		Phone:     input.Phone,
+		Version:   middleware.IfMatchVersion(c),
+	})
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	h.userCache.Invalidate(user.ID)
+	helper.SetVersionETag(c, user.Version, userETagVariant(nil)...)
+	return helper.SuccessResponse(c, ToProfileResponseDto(user), "Update profile success")
+}
+
+// canManageUser: admin boleh mengelola semua user, user biasa hanya dirinya sendiri
+func canManageUser(c *fiber.Ctx, id uint) bool {
+	user, ok := auth.User(c)
+	if !ok {
+		return false
+	}
+	return user.ID == id || user.Role == domain.RoleAdmin
+}
+
+// emailChangeAllowed: hanya admin yang boleh mengganti email langsung, user lain wajib lewat
+// alur konfirmasi POST /users/me/email
+func emailChangeAllowed(c *fiber.Ctx, email *string) bool {
+	user, ok := auth.User(c)
+	if !ok {
+		return false
+	}
+	return email == nil || identity.Email(*email) == identity.Email(user.Email) || user.Role == domain.RoleAdmin
+}
+
+// Update (PUT) mengganti seluruh field profil dengan aturan validasi yang sama seperti Register
+func (h *UserHandler) Update(c *fiber.Ctx) error {
+	id, err := h.usecase.ResolveID(c.Params("id"))
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	if !canManageUser(c, id) {
+		return helper.ErrorResponse(c, fiber.StatusForbidden, "Forbidden", nil)
+	}
+
+	var input struct {
+		FirstName string `json:"first_name" validate:"required"`
+		LastName  string `json:"last_name" validate:"required"`
+		Username  string `json:"username" validate:"required"`
+		Email     string `json:"email" validate:"required,email"`
+		Phone     string `json:"phone" validate:"required,e164"`
+	}
+
+	if err := c.BodyParser(&input); err != nil {
+		return helper.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request", err)
+	}
+
+	if err := h.validate.Struct(&input); err != nil {
+		errorFields := helper.ValidationErrorFormatter(err, input)
+		return helper.ErrorResponse(c, fiber.StatusUnprocessableEntity, errorFields, nil)
+	}
+
+	if !emailChangeAllowed(c, &input.Email) {
+		return helper.ErrorResponse(c, fiber.StatusUnprocessableEntity, map[string]string{"email": "Use the email change flow to update your email"}, nil)
+	}
+
+	user, err := h.usecase.Update(id, usecase.UserUpdate{
+		FirstName: &input.FirstName,
+		LastName:  &input.LastName,
+		Username:  &input.Username,
+		Email:     &input.Email,
+		Phone:     &input.Phone,
+		Version:   middleware.IfMatchVersion(c),
+	})
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	h.userCache.Invalidate(user.ID)
+	helper.SetVersionETag(c, user.Version, userETagVariant(nil)...)
+	return helper.SuccessResponse(c, ToUserResponseDto(user), "Update data user success")
+}
+
+// Patch hanya mengubah field yang dikirim
+func (h *UserHandler) Patch(c *fiber.Ctx) error {
+	id, err := h.usecase.ResolveID(c.Params("id"))
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	if !canManageUser(c, id) {
+		return helper.ErrorResponse(c, fiber.StatusForbidden, "Forbidden", nil)
+	}
+
+	var input struct {
+		FirstName *string `json:"first_name" validate:"omitempty,min=1"`
+		LastName  *string `json:"last_name" validate:"omitempty,min=1"`
+		Username  *string `json:"username" validate:"omitempty,min=1"`
+		Email     *string `json:"email" validate:"omitempty,email"`
+		Phone     *string `json:"phone" validate:"omitempty,e164"`
+	}
+
+	if err := c.BodyParser(&input); err != nil {
+		return helper.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request", err)
+	}
+
+	if err := h.validate.Struct(&input); err != nil {
+		errorFields := helper.ValidationErrorFormatter(err, input)
+		return helper.ErrorResponse(c, fiber.StatusUnprocessableEntity, errorFields, nil)
+	}
+
+	if !emailChangeAllowed(c, input.Email) {
+		return helper.ErrorResponse(c, fiber.StatusUnprocessableEntity, map[string]string{"email": "Use the email change flow to update your email"}, nil)
+	}
+
+	user, err := h.usecase.Update(id, usecase.UserUpdate{
+		FirstName: input.FirstName,
+		LastName:  input.LastName,
+		Username:  input.Username,
+		Email:     input.Email,
+		Phone:     input.Phone,
+		Version:   middleware.IfMatchVersion(c),
+	})
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	h.userCache.Invalidate(user.ID)
+	helper.SetVersionETag(c, user.Version, userETagVariant(nil)...)
+	return helper.SuccessResponse(c, ToUserResponseDto(user), "Update data user success")
+}
+
+func (h *UserHandler) Delete(c *fiber.Ctx) error {
+	id, err := h.usecase.ResolveID(c.Params("id"))
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	if !canManageUser(c, id) {
+		return helper.ErrorResponse(c, fiber.StatusForbidden, "Forbidden", nil)
+	}
+
+	if err := h.usecase.Delete(id); err != nil {
+		return errorResponse(c, err)
+	}
+
+	h.userCache.Invalidate(id)
+	return helper.SuccessResponse(c, nil, "Delete data user success")
+}
+
+func (h *UserHandler) Activate(c *fiber.Ctx) error {
+	return h.setActive(c, true)
+}
+
+func (h *UserHandler) Deactivate(c *fiber.Ctx) error {
+	return h.setActive(c, false)
+}
+
+func (h *UserHandler) setActive(c *fiber.Ctx, active bool) error {
+	id, err := h.usecase.ResolveID(c.Params("id"))
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	user, err := h.usecase.SetActive(id, active)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	h.userCache.Invalidate(user.ID)
+	return helper.SuccessResponse(c, ToUserResponseDto(user), "Update status user success")
+}
+
+func (h *UserHandler) Trashed(c *fiber.Ctx) error {
+	users, err := h.usecase.Trashed()
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	dto := []UserResponseDto{}
+	for _, user := range users {
+		dto = append(dto, ToUserResponseDto(user))
+	}
+
+	return helper.SuccessResponse(c, dto, "Fetch trashed users success")
+}
+
+func (h *UserHandler) Restore(c *fiber.Ctx) error {
+	id, err := h.usecase.ResolveID(c.Params("id"))
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	user, err := h.usecase.Restore(id)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return helper.SuccessResponse(c, ToUserResponseDto(user), "Restore user success")
+}
+
+func (h *UserHandler) Purge(c *fiber.Ctx) error {
+	id, err := h.usecase.ResolveID(c.Params("id"))
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	if err := h.usecase.Purge(id); err != nil {
+		return errorResponse(c, err)
+	}
+
+	h.userCache.Invalidate(id)
+	return helper.SuccessResponse(c, nil, "Purge user success")
+}
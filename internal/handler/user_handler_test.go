@@ -0,0 +1,394 @@
+package handler
+
+import (
+	"codebase-api/internal/domain"
+	"codebase-api/internal/repository"
+	"codebase-api/internal/testdb"
+	"codebase-api/internal/usecase"
+	"codebase-api/pkg/auth"
+	helper "codebase-api/pkg/helpers"
+	middleware "codebase-api/pkg/middlewares"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+func TestSearchingByGroupRequiresMembership(t *testing.T) {
+	db := testdb.Open(t)
+	userRepo := repository.NewUserRepository(db)
+	groups := usecase.NewGroupUseCase(repository.NewGroupRepository(db), repository.NewGroupMemberRepository(db), userRepo)
+	users := usecase.NewUserUseCase(userRepo, usecase.NewLocalAuthenticator(userRepo))
+
+	newUser := func(username string, role string) *domain.User {
+		user := &domain.User{
+			FirstName: "Test",
+			LastName:  "User",
+			Username:  username,
+			Email:     username + "@example.com",
+			Password:  "not-a-hash",
+			IsActive:  true,
+			Role:      role,
+		}
+		if err := userRepo.Create(user); err != nil {
+			t.Fatal(err)
+		}
+		return user
+	}
+	owner := newUser("owner", domain.RoleUser)
+	outsider := newUser("outsider", domain.RoleUser)
+	admin := newUser("admin", domain.RoleAdmin)
+
+	group, err := groups.Create(owner, "Finance", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := NewUserHandler(users, groups, auth.NewUserCache(time.Minute))
+	search := func(user *domain.User, groupID string) int {
+		app := fiber.New()
+		app.Get("/users/search", func(c *fiber.Ctx) error {
+			auth.SetUser(c, user)
+			return c.Next()
+		}, h.Searching)
+
+		resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/users/search?group="+groupID, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp.StatusCode
+	}
+
+	tests := []struct {
+		name    string
+		user    *domain.User
+		groupID string
+		want    int
+	}{
+		{name: "member", user: owner, groupID: group.UUID.String(), want: fiber.StatusOK},
+		{name: "admin", user: admin, groupID: group.UUID.String(), want: fiber.StatusOK},
+		{name: "non-member", user: outsider, groupID: group.UUID.String(), want: fiber.StatusNotFound},
+		{name: "unknown group", user: outsider, groupID: "00000000-0000-0000-0000-000000000000", want: fiber.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := search(tt.user, tt.groupID); got != tt.want {
+				t.Fatalf("status = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMeETagUsesFreshUser(t *testing.T) {
+	db := testdb.Open(t)
+	userRepo := repository.NewUserRepository(db)
+	users := usecase.NewUserUseCase(userRepo, usecase.NewLocalAuthenticator(userRepo))
+	groups := usecase.NewGroupUseCase(repository.NewGroupRepository(db), repository.NewGroupMemberRepository(db), userRepo)
+
+	user := &domain.User{FirstName: "Budi", Username: "budi", Email: "budi@example.com", Password: "not-a-hash", IsActive: true, Role: domain.RoleUser}
+	if err := userRepo.Create(user); err != nil {
+		t.Fatal(err)
+	}
+	// Salinan yang disimpan LoadUser di cache sebelum profil diubah
+	cached := *user
+
+	h := NewUserHandler(users, groups, auth.NewUserCache(time.Minute))
+	app := fiber.New()
+	app.Get("/users/me", func(c *fiber.Ctx) error {
+		auth.SetUser(c, &cached)
+		return c.Next()
+	}, h.Me)
+
+	get := func(ifNoneMatch string) (int, string) {
+		req := httptest.NewRequest(fiber.MethodGet, "/users/me", nil)
+		if ifNoneMatch != "" {
+			req.Header.Set(fiber.HeaderIfNoneMatch, ifNoneMatch)
+		}
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp.StatusCode, resp.Header.Get(fiber.HeaderETag)
+	}
+
+	status, etag := get("")
+	if status != fiber.StatusOK || etag == "" {
+		t.Fatalf("status = %d, etag = %q", status, etag)
+	}
+
+	user.FirstName = "Budiman"
+	if err := userRepo.Update(user); err != nil {
+		t.Fatal(err)
+	}
+
+	status, fresh := get(etag)
+	if status != fiber.StatusOK || fresh == etag {
+		t.Fatalf("stale cache produced status %d with etag %q (old %q)", status, fresh, etag)
+	}
+	if status, _ := get(fresh); status != fiber.StatusNotModified {
+		t.Fatalf("status = %d, want 304 for current etag", status)
+	}
+}
+
+// newUserCRUDApp memasang route CRUD user seperti SetupRoutes dengan actor sebagai user yang login
+func newUserCRUDApp(t *testing.T, userRepo *repository.UserRepository, actor *domain.User) *fiber.App {
+	t.Helper()
+
+	groups := usecase.NewGroupUseCase(repository.NewGroupRepository(userRepo.DB), repository.NewGroupMemberRepository(userRepo.DB), userRepo)
+	h := NewUserHandler(usecase.NewUserUseCase(userRepo), groups, auth.NewUserCache(time.Minute))
+
+	app := fiber.New()
+	app.Get("/users/:id", asUser(actor), h.Detail)
+	app.Put("/users/:id", middleware.RequireIfMatch(), asUser(actor), h.Update)
+	app.Patch("/users/:id", middleware.RequireIfMatch(), asUser(actor), h.Patch)
+	app.Delete("/users/:id", asUser(actor), h.Delete)
+	app.Post("/users/:id/deactivate", asUser(actor), h.Deactivate)
+	return app
+}
+
+func jsonRequest(method string, target string, body string) *http.Request {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	req.Header.Set(fiber.HeaderIfMatch, "*")
+	return req
+}
+
+func TestUserCRUDStatus(t *testing.T) {
+	userRepo := repository.NewUserRepository(testdb.Open(t))
+	admin := newTestUser(t, userRepo, "admin", domain.RoleAdmin)
+	budi := newTestUser(t, userRepo, "budi", domain.RoleUser)
+	newTestUser(t, userRepo, "siti", domain.RoleUser)
+	app := newUserCRUDApp(t, userRepo, admin)
+
+	unknown := "/users/00000000-0000-0000-0000-000000000000"
+	target := "/users/" + budi.UUID.String()
+	valid := `{"first_name":"Budi","last_name":"Santoso","username":"budi","email":"budi@example.com","phone":"+6281234567890"}`
+
+	tests := []struct {
+		name string
+		req  *http.Request
+		want int
+	}{
+		{name: "get unknown", req: httptest.NewRequest(fiber.MethodGet, unknown, nil), want: fiber.StatusNotFound},
+		{name: "put unknown", req: jsonRequest(fiber.MethodPut, unknown, valid), want: fiber.StatusNotFound},
+		{name: "patch unknown", req: jsonRequest(fiber.MethodPatch, unknown, `{"first_name":"X"}`), want: fiber.StatusNotFound},
+		{name: "delete unknown", req: httptest.NewRequest(fiber.MethodDelete, unknown, nil), want: fiber.StatusNotFound},
+		{name: "deactivate unknown", req: httptest.NewRequest(fiber.MethodPost, unknown+"/deactivate", nil), want: fiber.StatusNotFound},
+		{name: "put missing fields", req: jsonRequest(fiber.MethodPut, target, `{"first_name":"Budi"}`), want: fiber.StatusUnprocessableEntity},
+		{name: "put invalid phone", req: jsonRequest(fiber.MethodPut, target, strings.Replace(valid, "+6281234567890", "0812", 1)), want: fiber.StatusUnprocessableEntity},
+		{name: "patch invalid email", req: jsonRequest(fiber.MethodPatch, target, `{"email":"not-an-email"}`), want: fiber.StatusUnprocessableEntity},
+		{name: "patch empty username", req: jsonRequest(fiber.MethodPatch, target, `{"username":""}`), want: fiber.StatusUnprocessableEntity},
+		{name: "patch reserved username", req: jsonRequest(fiber.MethodPatch, target, `{"username":"administrator"}`), want: fiber.StatusUnprocessableEntity},
+		{name: "patch taken username", req: jsonRequest(fiber.MethodPatch, target, `{"username":"SITI"}`), want: fiber.StatusConflict},
+		{name: "patch taken email", req: jsonRequest(fiber.MethodPatch, target, `{"email":"siti@example.com"}`), want: fiber.StatusConflict},
+		{name: "put valid", req: jsonRequest(fiber.MethodPut, target, valid), want: fiber.StatusOK},
+		{name: "patch stale version", req: func() *http.Request {
+			req := jsonRequest(fiber.MethodPatch, target, `{"first_name":"X"}`)
+			req.Header.Set(fiber.HeaderIfMatch, helper.VersionETag(budi.Version))
+			return req
+		}(), want: fiber.StatusPreconditionFailed},
+		{name: "deactivate", req: httptest.NewRequest(fiber.MethodPost, target+"/deactivate", nil), want: fiber.StatusOK},
+		// SuccessResponse tanpa data selalu membalas 201
+		{name: "delete", req: httptest.NewRequest(fiber.MethodDelete, target, nil), want: fiber.StatusCreated},
+		{name: "get deleted", req: httptest.NewRequest(fiber.MethodGet, target, nil), want: fiber.StatusNotFound},
+		{name: "delete twice", req: httptest.NewRequest(fiber.MethodDelete, target, nil), want: fiber.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, env := doRequest(t, app, tt.req)
+			if resp.StatusCode != tt.want || env.StatusCode != tt.want {
+				t.Fatalf("status = %d (envelope %d), want %d: %s %s", resp.StatusCode, env.StatusCode, tt.want, env.Message, env.Data)
+			}
+		})
+	}
+
+}
+
+func TestUserUpdateCannotOverwriteCredentials(t *testing.T) {
+	userRepo := repository.NewUserRepository(testdb.Open(t))
+	budi := newTestUser(t, userRepo, "budi", domain.RoleUser)
+	budi.RefreshToken = "refresh-token"
+	if err := userRepo.Update(budi); err != nil {
+		t.Fatal(err)
+	}
+	app := newUserCRUDApp(t, userRepo, budi)
+
+	body := `{"first_name":"Budi","password":"hijacked","refresh_token":"hijacked","role":"admin"}`
+	if resp, env := doRequest(t, app, jsonRequest(fiber.MethodPatch, "/users/"+budi.UUID.String(), body)); resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("status = %d: %s", resp.StatusCode, env.Data)
+	}
+
+	stored, err := userRepo.FindByID(budi.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.FirstName != "Budi" || stored.Password != "not-a-hash" || stored.RefreshToken != "refresh-token" || stored.Role != domain.RoleUser {
+		t.Fatalf("credentials overwritten: %+v", stored)
+	}
+}
+
+func TestUserCRUDRequiresOwnerOrAdmin(t *testing.T) {
+	userRepo := repository.NewUserRepository(testdb.Open(t))
+	budi := newTestUser(t, userRepo, "budi", domain.RoleUser)
+	siti := newTestUser(t, userRepo, "siti", domain.RoleUser)
+	app := newUserCRUDApp(t, userRepo, siti)
+
+	target := "/users/" + budi.UUID.String()
+	for _, req := range []*http.Request{
+		jsonRequest(fiber.MethodPatch, target, `{"first_name":"X"}`),
+		httptest.NewRequest(fiber.MethodDelete, target, nil),
+	} {
+		if resp, _ := doRequest(t, app, req); resp.StatusCode != fiber.StatusForbidden {
+			t.Fatalf("%s by another user: status = %d, want 403", req.Method, resp.StatusCode)
+		}
+	}
+}
+
+func TestUserResponseUsesUUIDAsPublicID(t *testing.T) {
+	userRepo := repository.NewUserRepository(testdb.Open(t))
+	budi := newTestUser(t, userRepo, "budi", domain.RoleUser)
+	app := newUserCRUDApp(t, userRepo, budi)
+
+	get := func(id string) (int, map[string]interface{}) {
+		resp, env := doRequest(t, app, httptest.NewRequest(fiber.MethodGet, "/users/"+id, nil))
+		var data map[string]interface{}
+		json.Unmarshal(env.Data, &data)
+		return resp.StatusCode, data
+	}
+	numeric := strconv.FormatUint(uint64(budi.ID), 10)
+
+	status, data := get(budi.UUID.String())
+	if status != fiber.StatusOK || data["id"] != budi.UUID.String() {
+		t.Fatalf("status = %d, data = %v", status, data)
+	}
+	if _, ok := data["legacy_id"]; ok {
+		t.Fatalf("legacy_id exposed without LEGACY_NUMERIC_IDS: %v", data)
+	}
+	if status, _ := get(numeric); status != fiber.StatusNotFound {
+		t.Fatalf("numeric id without LEGACY_NUMERIC_IDS: status = %d, want 404", status)
+	}
+
+	t.Setenv("LEGACY_NUMERIC_IDS", "true")
+	status, data = get(numeric)
+	if status != fiber.StatusOK || data["id"] != budi.UUID.String() || data["legacy_id"] != float64(budi.ID) {
+		t.Fatalf("numeric id with LEGACY_NUMERIC_IDS: status = %d, data = %v", status, data)
+	}
+}
+
+func TestSearchingRejectsInvalidQueries(t *testing.T) {
+	db := testdb.Open(t)
+	userRepo := repository.NewUserRepository(db)
+	groups := usecase.NewGroupUseCase(repository.NewGroupRepository(db), repository.NewGroupMemberRepository(db), userRepo)
+	users := usecase.NewUserUseCase(userRepo)
+	admin := newTestUser(t, userRepo, "admin", domain.RoleAdmin)
+
+	app := fiber.New()
+	app.Get("/users/search", asUser(admin), NewUserHandler(users, groups, auth.NewUserCache(time.Minute)).Searching)
+
+	tests := map[string]string{
+		"unknown field":       "filter[password]=secret",
+		"disallowed operator": "filter[is_active][like]=tr",
+		"unknown sort":        "sort=-password",
+		"invalid value":       "filter[created_at][gt]=yesterday",
+	}
+	for name, rawQuery := range tests {
+		t.Run(name, func(t *testing.T) {
+			resp, _ := doRequest(t, app, httptest.NewRequest(fiber.MethodGet, "/users/search?"+rawQuery, nil))
+			if resp.StatusCode != fiber.StatusBadRequest {
+				t.Fatalf("status = %d, want 400", resp.StatusCode)
+			}
+		})
+	}
+
+	resp, _ := doRequest(t, app, httptest.NewRequest(fiber.MethodGet, "/users/search?filter[username][like]=ad&sort=-created_at,username", nil))
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("valid query: status = %d, want 200", resp.StatusCode)
+	}
+}
+
+func TestDetailSelectsOnlyProjectedColumns(t *testing.T) {
+	db := testdb.Open(t)
+	userRepo := repository.NewUserRepository(db)
+	groups := usecase.NewGroupUseCase(repository.NewGroupRepository(db), repository.NewGroupMemberRepository(db), userRepo)
+	users := usecase.NewUserUseCase(userRepo)
+	admin := newTestUser(t, userRepo, "admin", domain.RoleAdmin)
+	budi := newTestUser(t, userRepo, "budi", domain.RoleUser)
+
+	// Catat SELECT ke tabel users; query terakhir adalah pemuatan user setelah ResolveID
+	var selects []string
+	db.Callback().Query().After("gorm:query").Register("test:capture_select", func(tx *gorm.DB) {
+		if tx.Statement.Table == "users" {
+			selects = append(selects, tx.Statement.SQL.String())
+		}
+	})
+
+	app := fiber.New()
+	app.Get("/users/:id", asUser(admin), NewUserHandler(users, groups, auth.NewUserCache(time.Minute)).Detail)
+
+	get := func(query string) (int, envelope, string) {
+		selects = nil
+		resp, env := doRequest(t, app, httptest.NewRequest(fiber.MethodGet, "/users/"+budi.UUID.String()+query, nil))
+		return resp.StatusCode, env, resp.Header.Get(fiber.HeaderETag)
+	}
+
+	status, env, etag := get("?fields=username")
+	if status != fiber.StatusOK || string(env.Data) != `{"username":"budi"}` {
+		t.Fatalf("status = %d, data = %s", status, env.Data)
+	}
+	if version, err := helper.ParseIfMatch(etag); err != nil || version != budi.Version {
+		t.Fatalf("etag %q does not carry version %d: %d, %v", etag, budi.Version, version, err)
+	}
+	if len(selects) == 0 || !strings.HasPrefix(selects[len(selects)-1], "SELECT `id`,`created_at`,`version`,`updated_at`,`username` FROM") {
+		t.Fatalf("selects = %q, want only the projected columns", selects)
+	}
+
+	if status, _, _ := get(""); status != fiber.StatusOK || len(selects) == 0 || !strings.HasPrefix(selects[len(selects)-1], "SELECT * FROM") {
+		t.Fatalf("status = %d, selects = %q, want all columns without ?fields=", status, selects)
+	}
+
+	if status, _, _ := get("?fields=password"); status != fiber.StatusBadRequest || len(selects) != 0 {
+		t.Fatalf("unknown field: status = %d, selects = %q, want 400 without querying the user", status, selects)
+	}
+}
+
+func TestDetailETagDependsOnRepresentation(t *testing.T) {
+	db := testdb.Open(t)
+	userRepo := repository.NewUserRepository(db)
+	groups := usecase.NewGroupUseCase(repository.NewGroupRepository(db), repository.NewGroupMemberRepository(db), userRepo)
+	admin := newTestUser(t, userRepo, "admin", domain.RoleAdmin)
+
+	app := fiber.New()
+	app.Get("/users/:id", asUser(admin), NewUserHandler(usecase.NewUserUseCase(userRepo), groups, auth.NewUserCache(time.Minute)).Detail)
+
+	get := func(query string, ifNoneMatch string) (int, string) {
+		req := httptest.NewRequest(fiber.MethodGet, "/users/"+admin.UUID.String()+query, nil)
+		if ifNoneMatch != "" {
+			req.Header.Set(fiber.HeaderIfNoneMatch, ifNoneMatch)
+		}
+		resp, _ := doRequest(t, app, req)
+		return resp.StatusCode, resp.Header.Get(fiber.HeaderETag)
+	}
+
+	_, full := get("", "")
+	_, projected := get("?fields=username", "")
+	if full == projected {
+		t.Fatalf("full and projected representations share etag %s", full)
+	}
+	if status, _ := get("?fields=username", full); status != fiber.StatusOK {
+		t.Fatalf("projected request with the full etag: status = %d, want 200", status)
+	}
+	if status, _ := get("?fields=username", projected); status != fiber.StatusNotModified {
+		t.Fatalf("projected request with its own etag: status = %d, want 304", status)
+	}
+
+	t.Setenv("LEGACY_NUMERIC_IDS", "true")
+	if status, legacy := get("", full); status != fiber.StatusOK || legacy == full {
+		t.Fatalf("legacy ids: status = %d, etag = %s, want an etag other than %s", status, legacy, full)
+	}
+}
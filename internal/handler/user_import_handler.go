@@ -0,0 +1,237 @@
+package handler
+
+import (
+	"bufio"
+	"bytes"
+	"codebase-api/internal/usecase"
+	"codebase-api/pkg/auth"
+	helper "codebase-api/pkg/helpers"
+	"codebase-api/pkg/jobs"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+// Import dengan baris lebih banyak dari ini diproses sebagai background job; setiap baris
+// membutuhkan hash bcrypt dan beberapa query cek duplikasi
+const importSyncRows = 20
+
+var importColumns = []string{"first_name", "last_name", "username", "email", "password", "password_confirm", "phone"}
+
+type UserImportHandler struct {
+	usecase  *usecase.UserUseCase
+	jobs     *jobs.Registry
+	validate *validator.Validate
+}
+
+func NewUserImportHandler(usecase *usecase.UserUseCase, registry *jobs.Registry) *UserImportHandler {
+	return &UserImportHandler{usecase: usecase, jobs: registry, validate: validator.New()}
+}
+
+// Import menerima file CSV (dengan header) atau NDJSON lewat multipart field "file" atau body mentah.
+// Query: format=csv|ndjson, mode=atomic|skip, dry_run=true, async=true
+func (h *UserImportHandler) Import(c *fiber.Ctx) error {
+	user, ok := auth.User(c)
+	if !ok {
+		return helper.ErrorResponse(c, fiber.StatusUnauthorized, "User not found", nil)
+	}
+
+	data, filename, err := importPayload(c)
+	if err != nil {
+		return helper.ErrorResponse(c, fiber.StatusBadRequest, "Invalid import file", err)
+	}
+
+	format := strings.ToLower(utils.CopyString(c.Query("format")))
+	if format == "" {
+		format = detectImportFormat(filename, c.Get(fiber.HeaderContentType))
+	}
+	if format != "csv" && format != "ndjson" {
+		return helper.ErrorResponse(c, fiber.StatusBadRequest, "Invalid import file", errors.New("format must be csv or ndjson"))
+	}
+
+	mode := usecase.ImportMode(utils.CopyString(c.Query("mode", string(usecase.ImportModeAtomic))))
+	if mode != usecase.ImportModeAtomic && mode != usecase.ImportModeSkip {
+		return helper.ErrorResponse(c, fiber.StatusBadRequest, "Invalid import mode", errors.New("mode must be atomic or skip"))
+	}
+	dryRun := c.QueryBool("dry_run")
+
+	rows, err := h.parseRows(data, format)
+	if err != nil {
+		return helper.ErrorResponse(c, fiber.StatusBadRequest, "Invalid import file", err)
+	}
+
+	run := func(ctx context.Context, job *jobs.Job) error {
+		report, err := h.usecase.Import(ctx, rows, mode, dryRun)
+		if err != nil {
+			return err
+		}
+		job.SetSummary(report)
+		return writeImportReport(job, report)
+	}
+
+	if c.QueryBool("async") || len(rows) > importSyncRows {
+		job := h.jobs.Submit("user_import", user.ID, run)
+		return helper.AcceptedResponse(c, ToJobResponseDto(job), "Import queued")
+	}
+
+	job := h.jobs.Run("user_import", user.ID, run)
+	if job.Info().Status == jobs.StatusFailed {
+		return helper.ErrorResponse(c, fiber.StatusBadRequest, "Import failed", errors.New(job.Info().Error))
+	}
+	return helper.SuccessResponse(c, ToJobResponseDto(job), "Import processed")
+}
+
+func importPayload(c *fiber.Ctx) ([]byte, string, error) {
+	if strings.HasPrefix(c.Get(fiber.HeaderContentType), fiber.MIMEMultipartForm) {
+		header, err := c.FormFile("file")
+		if err != nil {
+			return nil, "", err
+		}
+		file, err := header.Open()
+		if err != nil {
+			return nil, "", err
+		}
+		defer file.Close()
+
+		data, err := io.ReadAll(file)
+		return data, header.Filename, err
+	}
+
+	data := c.Body()
+	if len(data) == 0 {
+		return nil, "", errors.New("empty request body")
+	}
+	// Body fasthttp hanya valid selama request, salin untuk background job
+	return bytes.Clone(data), "", nil
+}
+
+func detectImportFormat(filename string, contentType string) string {
+	switch {
+	case strings.EqualFold(filepath.Ext(filename), ".csv"), strings.HasPrefix(contentType, "text/csv"):
+		return "csv"
+	case strings.EqualFold(filepath.Ext(filename), ".ndjson"), strings.EqualFold(filepath.Ext(filename), ".jsonl"),
+		strings.HasPrefix(contentType, "application/x-ndjson"):
+		return "ndjson"
+	}
+	return ""
+}
+
+func (h *UserImportHandler) parseRows(data []byte, format string) ([]usecase.ImportRow, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	if format == "csv" {
+		return h.parseCSV(data)
+	}
+	return h.parseNDJSON(data)
+}
+
+func (h *UserImportHandler) parseCSV(data []byte) ([]usecase.ImportRow, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("could not read CSV header: %w", err)
+	}
+
+	index := map[string]int{}
+	for i, column := range header {
+		index[strings.ToLower(strings.TrimSpace(column))] = i
+	}
+	for _, column := range importColumns {
+		if _, ok := index[column]; !ok {
+			return nil, fmt.Errorf("missing CSV column %q", column)
+		}
+	}
+
+	var rows []usecase.ImportRow
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			rows = append(rows, usecase.ImportRow{Line: line, Errors: map[string]string{"row": err.Error()}})
+			continue
+		}
+
+		value := func(column string) string {
+			if i := index[column]; i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		rows = append(rows, h.validateRow(line, RegisterInput{
+			FirstName:       value("first_name"),
+			LastName:        value("last_name"),
+			Username:        value("username"),
+			Email:           value("email"),
+			Password:        value("password"),
+			PasswordConfirm: value("password_confirm"),
+			Phone:           value("phone"),
+		}))
+	}
+	return rows, nil
+}
+
+func (h *UserImportHandler) parseNDJSON(data []byte) ([]usecase.ImportRow, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64<<10), 1<<20)
+
+	var rows []usecase.ImportRow
+	for line := 1; scanner.Scan(); line++ {
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+
+		var input RegisterInput
+		if err := json.Unmarshal(text, &input); err != nil {
+			rows = append(rows, usecase.ImportRow{Line: line, Errors: map[string]string{"row": "Invalid JSON"}})
+			continue
+		}
+		rows = append(rows, h.validateRow(line, input))
+	}
+	return rows, scanner.Err()
+}
+
+// validateRow memakai aturan yang sama dengan AuthHandler.Register
+func (h *UserImportHandler) validateRow(line int, input RegisterInput) usecase.ImportRow {
+	if err := h.validate.Struct(&input); err != nil {
+		return usecase.ImportRow{Line: line, Errors: helper.ValidationErrorFormatter(err, input)}
+	}
+	return usecase.ImportRow{Line: line, User: input.ToUser()}
+}
+
+// writeImportReport menulis laporan per baris sebagai CSV yang bisa diunduh dari job
+func writeImportReport(job *jobs.Job, report *usecase.ImportReport) error {
+	file, err := job.CreateResult("user-import-report.csv", "text/csv")
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	writer.Write([]string{"line", "username", "email", "status", "errors"})
+	for _, row := range report.Rows {
+		var messages []string
+		for field, message := range row.Errors {
+			messages = append(messages, field+": "+message)
+		}
+		sort.Strings(messages)
+
+		writer.Write([]string{fmt.Sprint(row.Line), row.Username, row.Email, row.Status, strings.Join(messages, "; ")})
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,189 @@
+package handler
+
+import (
+	"codebase-api/internal/domain"
+	"codebase-api/internal/repository"
+	"codebase-api/internal/testdb"
+	"codebase-api/internal/usecase"
+	"codebase-api/pkg/jobs"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type importFixture struct {
+	app      *fiber.App
+	registry *jobs.Registry
+	userRepo *repository.UserRepository
+}
+
+func newImportFixture(t *testing.T) *importFixture {
+	t.Helper()
+
+	userRepo := repository.NewUserRepository(testdb.Open(t))
+	admin := newTestUser(t, userRepo, "root", domain.RoleAdmin)
+	registry := jobs.NewRegistry(time.Hour)
+	t.Cleanup(registry.Close)
+
+	h := NewUserImportHandler(usecase.NewUserUseCase(userRepo, usecase.NewLocalAuthenticator(userRepo)), registry)
+	app := fiber.New()
+	app.Post("/users/import", asUser(admin), h.Import)
+	return &importFixture{app: app, registry: registry, userRepo: userRepo}
+}
+
+func (f *importFixture) post(t *testing.T, query string, contentType string, body string) (int, JobResponseDto) {
+	t.Helper()
+
+	req := httptest.NewRequest(fiber.MethodPost, "/users/import"+query, strings.NewReader(body))
+	req.Header.Set(fiber.HeaderContentType, contentType)
+	resp, env := doRequest(t, f.app, req)
+
+	var job JobResponseDto
+	if resp.StatusCode < 300 {
+		if err := json.Unmarshal(env.Data, &job); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return resp.StatusCode, job
+}
+
+// report membaca laporan CSV per baris dari hasil job
+func (f *importFixture) report(t *testing.T, jobID string) [][]string {
+	t.Helper()
+
+	path, _, _, err := mustJob(t, f.registry, jobID).Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return records[1:]
+}
+
+const importCSVHeader = "First_Name, last_name,username,email,password,password_confirm,phone\n"
+
+func TestImportCSVValidatesEachRow(t *testing.T) {
+	f := newImportFixture(t)
+
+	body := "\xef\xbb\xbf" + importCSVHeader +
+		"Budi,Santoso,budi,budi@example.com,secret123,secret123,+628111111111\n" +
+		"Siti,Aminah,siti,not-an-email,secret123,secret123,+628222222222\n" +
+		"Andi,Wijaya,andi,andi@example.com,secret123,different,+628333333333\n" +
+		"Joko,\"Unclosed,joko\n"
+	status, job := f.post(t, "?mode=skip", "text/csv", body)
+	if status != fiber.StatusOK || job.Status != jobs.StatusSucceeded {
+		t.Fatalf("status = %d, job = %+v", status, job)
+	}
+
+	rows := f.report(t, job.ID)
+	got := make([]string, len(rows))
+	for i, row := range rows {
+		got[i] = row[0] + " " + row[3]
+	}
+	if want := "2 created,3 invalid,4 invalid,5 invalid"; strings.Join(got, ",") != want {
+		t.Fatalf("report = %v, want %s", got, want)
+	}
+	if !strings.Contains(rows[1][4], "email") || !strings.Contains(rows[2][4], "password_confirm") {
+		t.Fatalf("row errors = %q / %q", rows[1][4], rows[2][4])
+	}
+	if _, err := f.userRepo.GetUserByUsername("budi"); err != nil {
+		t.Fatalf("valid row not created: %v", err)
+	}
+}
+
+func TestImportNDJSON(t *testing.T) {
+	f := newImportFixture(t)
+
+	body := `{"first_name":"Budi","last_name":"Santoso","username":"budi","email":"budi@example.com","password":"secret123","password_confirm":"secret123","phone":"+628111111111"}` + "\n\n" +
+		`{"first_name":` + "\n"
+	status, job := f.post(t, "?dry_run=true&mode=skip", "application/x-ndjson", body)
+	if status != fiber.StatusOK {
+		t.Fatalf("status = %d", status)
+	}
+
+	rows := f.report(t, job.ID)
+	if len(rows) != 2 || rows[0][3] != usecase.ImportStatusValid || rows[1][0] != "3" || rows[1][4] != "row: Invalid JSON" {
+		t.Fatalf("report = %v", rows)
+	}
+	if _, err := f.userRepo.GetUserByUsername("budi"); err == nil {
+		t.Fatal("dry run created a user")
+	}
+}
+
+func TestImportRejectsInvalidFiles(t *testing.T) {
+	f := newImportFixture(t)
+
+	tests := []struct {
+		name        string
+		query       string
+		contentType string
+		body        string
+	}{
+		{name: "missing column", contentType: "text/csv", body: "first_name,last_name,username,email\nBudi,Santoso,budi,budi@example.com\n"},
+		{name: "unknown format", contentType: "application/octet-stream", body: "username\nbudi\n"},
+		{name: "unknown mode", query: "?mode=partial", contentType: "text/csv", body: importCSVHeader},
+		{name: "empty body", contentType: "text/csv"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if status, _ := f.post(t, tt.query, tt.contentType, tt.body); status != fiber.StatusBadRequest {
+				t.Fatalf("status = %d, want 400", status)
+			}
+		})
+	}
+}
+
+func TestImportRunsLargeFilesInBackground(t *testing.T) {
+	f := newImportFixture(t)
+
+	var body strings.Builder
+	body.WriteString(importCSVHeader)
+	for i := 0; i <= importSyncRows; i++ {
+		// Password tidak cocok: baris invalid sehingga test tidak menunggu bcrypt
+		fmt.Fprintf(&body, "User,%d,user%d,user%d@example.com,secret123,different,+62811000%04d\n", i, i, i, i)
+	}
+
+	status, job := f.post(t, "?dry_run=true", "text/csv", body.String())
+	if status != fiber.StatusAccepted {
+		t.Fatalf("status = %d, want 202 for %d rows", status, importSyncRows+1)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		info := mustJob(t, f.registry, job.ID).Info()
+		if info.Status == jobs.StatusSucceeded {
+			break
+		}
+		if info.Status == jobs.StatusFailed || time.Now().After(deadline) {
+			t.Fatalf("job = %+v", info)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if rows := f.report(t, job.ID); len(rows) != importSyncRows+1 {
+		t.Fatalf("report has %d rows", len(rows))
+	}
+}
+
+func mustJob(t *testing.T, registry *jobs.Registry, id string) *jobs.Job {
+	t.Helper()
+	job, ok := registry.Get(id)
+	if !ok {
+		t.Fatalf("job %s not found", id)
+	}
+	return job
+}
@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"codebase-api/internal/domain"
+
 	"gorm.io/gorm"
 )
 
@@ -13,28 +15,142 @@ func NewBaseRepository[T any](db *gorm.DB) *BaseRepository[T] {
 }
 
 func (r *BaseRepository[T]) Create(entity *T) error {
-	return r.DB.Create(entity).Error
+	return translateError(r.DB.Create(entity).Error)
+}
+
+// CreateInBatches menyimpan banyak entity dalam satu transaksi; gagal satu batch berarti semua dibatalkan
+func (r *BaseRepository[T]) CreateInBatches(entities []T, batchSize int) error {
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		return tx.CreateInBatches(&entities, batchSize).Error
+	})
+	return translateError(err)
 }
 
 func (r *BaseRepository[T]) FindByID(id uint) (*T, error) {
 	var entity T
-	err := r.DB.First(&entity, "id = ?", id).Error
+	err := translateError(r.DB.First(&entity, "id = ?", id).Error)
 	if err != nil {
 		return nil, err
 	}
 	return &entity, nil
 }
 
+// FindByIDSelect sama dengan FindByID tetapi hanya memuat columns; kosong berarti semua kolom
+func (r *BaseRepository[T]) FindByIDSelect(id uint, columns []string) (*T, error) {
+	query := r.DB
+	if len(columns) > 0 {
+		query = query.Select(columns)
+	}
+
+	var entity T
+	err := translateError(query.First(&entity, "id = ?", id).Error)
+	if err != nil {
+		return nil, err
+	}
+	return &entity, nil
+}
+
+func (r *BaseRepository[T]) FindByUUID(uuid string) (*T, error) {
+	var entity T
+	err := translateError(r.DB.First(&entity, "uuid = ?", uuid).Error)
+	if err != nil {
+		return nil, err
+	}
+	return &entity, nil
+}
+
+// FindIDByUUID menerjemahkan UUID publik menjadi primary key, termasuk entity yang sudah di-soft delete
+func (r *BaseRepository[T]) FindIDByUUID(uuid string) (uint, error) {
+	var ids []uint
+	var entity T
+	err := r.DB.Unscoped().Model(&entity).Where("uuid = ?", uuid).Limit(1).Pluck("id", &ids).Error
+	if err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, translateError(gorm.ErrRecordNotFound)
+	}
+	return ids[0], nil
+}
+
 func (r *BaseRepository[T]) FindAll() ([]T, error) {
 	var entities []T
 	err := r.DB.Find(&entities).Error
 	return entities, err
 }
 
+// versioned dipenuhi setiap entity yang meng-embed domain.BaseDomain
+type versioned interface {
+	Base() domain.BaseDomain
+	SetVersion(version uint)
+}
+
+// Update menyimpan seluruh field entity hanya jika version di database masih sama dengan entity.Version,
+// lalu menaikkan version. Jika baris sudah diubah request lain, domain.ErrStaleVersion dikembalikan
 func (r *BaseRepository[T]) Update(entity *T) error {
-	return r.DB.Save(entity).Error
+	return updateVersioned(r.DB, entity)
+}
+
+func updateVersioned[T any](db *gorm.DB, entity *T) error {
+	v, ok := any(entity).(versioned)
+	if !ok {
+		return translateError(db.Save(entity).Error)
+	}
+
+	current := v.Base().Version
+	v.SetVersion(current + 1)
+	// Bukan Save: Save akan melakukan INSERT ... ON DUPLICATE KEY bila tidak ada baris yang ter-update
+	result := db.Model(entity).Where("version = ?", current).Select("*").Updates(entity)
+	if result.Error != nil {
+		v.SetVersion(current)
+		return translateError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		v.SetVersion(current)
+		return domain.ErrStaleVersion
+	}
+	return nil
 }
 
 func (r *BaseRepository[T]) Delete(id uint, entity *T) error {
-	return r.DB.Delete(entity, id).Error
+	return translateError(r.DB.Delete(entity, id).Error)
+}
+
+// FindTrashed mengembalikan entity yang sudah di-soft delete
+func (r *BaseRepository[T]) FindTrashed() ([]T, error) {
+	var entities []T
+	err := r.DB.Unscoped().Where("deleted_at IS NOT NULL").Find(&entities).Error
+	return entities, err
+}
+
+func (r *BaseRepository[T]) FindTrashedByID(id uint) (*T, error) {
+	var entity T
+	err := translateError(r.DB.Unscoped().Where("deleted_at IS NOT NULL").First(&entity, "id = ?", id).Error)
+	if err != nil {
+		return nil, err
+	}
+	return &entity, nil
+}
+
+// FindByIDWithTrashed mencari entity termasuk yang sudah di-soft delete
+func (r *BaseRepository[T]) FindByIDWithTrashed(id uint) (*T, error) {
+	var entity T
+	err := translateError(r.DB.Unscoped().First(&entity, "id = ?", id).Error)
+	if err != nil {
+		return nil, err
+	}
+	return &entity, nil
+}
+
+func (r *BaseRepository[T]) Restore(id uint) error {
+	var entity T
+	err := r.DB.Unscoped().Model(&entity).Where("id = ?", id).
+		Updates(map[string]interface{}{"deleted_at": nil, "version": gorm.Expr("version + 1")}).Error
+	return translateError(err)
+}
+
+// Purge menghapus entity secara permanen
+func (r *BaseRepository[T]) Purge(id uint) error {
+	var entity T
+	return translateError(r.DB.Unscoped().Delete(&entity, id).Error)
 }
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"codebase-api/internal/domain"
+	"errors"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+)
+
+// mysqlDuplicateEntry adalah kode error MySQL untuk pelanggaran unique index
+const mysqlDuplicateEntry = 1062
+
+// translateError menerjemahkan error GORM / MySQL yang dikenal menjadi error domain.
+// Error asli tetap bisa diperiksa lewat errors.Is / errors.As; error lain dikembalikan apa adanya
+func translateError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &domain.Error{Kind: domain.ErrNotFound, Message: "record not found", Err: err}
+	}
+
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlDuplicateEntry {
+		conflict := domain.Conflict("", "duplicate entry")
+		if field := duplicateField(mysqlErr.Message); field != "" {
+			conflict = domain.FieldConflict(field)
+		}
+		conflict.Err = err
+		return conflict
+	}
+	// Dialector lain (SQLite di test) melaporkan duplikat lewat TranslateError tanpa nama index
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		conflict := domain.Conflict("", "duplicate entry")
+		conflict.Err = err
+		return conflict
+	}
+	return err
+}
+
+// duplicateField mencari field dari nama unique index di pesan "Duplicate entry '...' for key 'users.uq_users_email'"
+func duplicateField(message string) string {
+	for index, field := range domain.UniqueIndexFields {
+		if strings.Contains(message, "'"+index+"'") || strings.Contains(message, "."+index+"'") {
+			return field
+		}
+	}
+	return ""
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"codebase-api/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+type GroupRepository struct {
+	BaseRepository[domain.Group]
+}
+
+func NewGroupRepository(db *gorm.DB) *GroupRepository {
+	return &GroupRepository{
+		BaseRepository: *NewBaseRepository[domain.Group](db),
+	}
+}
+
+// List mengembalikan semua group, atau hanya group yang diikuti userID jika diisi
+func (r *GroupRepository) List(userID *uint, req PageRequest) (*Page[domain.Group], error) {
+	query := r.DB.Model(&domain.Group{})
+	if userID != nil {
+		query = query.Where("id IN (?)", r.DB.Model(&domain.GroupMember{}).Select("group_id").Where("user_id = ?", *userID))
+	}
+	return r.Paginate(query, req)
+}
+
+// Delete menghapus group (soft delete) beserta seluruh keanggotaannya
+func (r *GroupRepository) Delete(id uint) error {
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("group_id = ?", id).Delete(&domain.GroupMember{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&domain.Group{}, id).Error
+	})
+	return translateError(err)
+}
+
+type GroupMemberRepository struct {
+	BaseRepository[domain.GroupMember]
+}
+
+func NewGroupMemberRepository(db *gorm.DB) *GroupMemberRepository {
+	return &GroupMemberRepository{
+		BaseRepository: *NewBaseRepository[domain.GroupMember](db),
+	}
+}
+
+func (r *GroupMemberRepository) Find(groupID uint, userID uint) (*domain.GroupMember, error) {
+	var member domain.GroupMember
+	err := translateError(r.DB.Where("group_id = ? AND user_id = ?", groupID, userID).First(&member).Error)
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// ListByGroup mengembalikan anggota group beserta datanya; user yang sudah dihapus tidak ikut
+func (r *GroupMemberRepository) ListByGroup(groupID uint, req PageRequest) (*Page[domain.GroupMember], error) {
+	query := r.DB.Model(&domain.GroupMember{}).Preload("User").
+		Where("group_id = ?", groupID).
+		Where("user_id IN (SELECT id FROM users WHERE deleted_at IS NULL)")
+	return r.Paginate(query, req)
+}
+
+func (r *GroupMemberRepository) CountByRole(groupID uint, role string) (int64, error) {
+	var count int64
+	err := r.DB.Model(&domain.GroupMember{}).Where("group_id = ? AND role = ?", groupID, role).Count(&count).Error
+	return count, err
+}
+
+func (r *GroupMemberRepository) Remove(groupID uint, userID uint) error {
+	return translateError(r.DB.Unscoped().Where("group_id = ? AND user_id = ?", groupID, userID).Delete(&domain.GroupMember{}).Error)
+}
+
+// CreateWithOwner membuat group dan menjadikan ownerID sebagai owner dalam satu transaksi
+func (r *GroupRepository) CreateWithOwner(group *domain.Group, ownerID uint) error {
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(group).Error; err != nil {
+			return err
+		}
+		return tx.Create(&domain.GroupMember{GroupID: group.ID, UserID: ownerID, Role: domain.GroupRoleOwner}).Error
+	})
+	return translateError(err)
+}
+
+// ListByUser mengembalikan semua keanggotaan user beserta group-nya
+func (r *GroupMemberRepository) ListByUser(userID uint) ([]domain.GroupMember, error) {
+	var members []domain.GroupMember
+	err := r.DB.Preload("Group").Where("user_id = ?", userID).Order("created_at asc").Find(&members).Error
+	return members, err
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"codebase-api/pkg/query"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// List menerapkan filter dan sort dari query string di atas query dasar lalu mem-paginate hasilnya.
+// Sort kustom hanya berlaku pada mode offset karena keyset cursor terikat pada (created_at, id)
+func (r *BaseRepository[T]) List(base *gorm.DB, q query.ListQuery, schema query.Schema, req PageRequest) (*Page[T], error) {
+	if req.IsCursor() && len(q.Sorts) > 0 {
+		return nil, &query.Error{Message: "sort cannot be combined with cursor pagination"}
+	}
+	return r.Paginate(q.Apply(base, schema), req)
+}
+
+// Each membaca hasil query baris demi baris lewat cursor database dan memanggil fn per batch,
+// sehingga hasil yang sangat besar tidak pernah dimuat sekaligus ke memori
+func (r *BaseRepository[T]) Each(query *gorm.DB, batchSize int, fn func([]T) error) error {
+	rows, err := query.Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	batch := make([]T, 0, batchSize)
+	for rows.Next() {
+		var entity T
+		if err := query.ScanRows(rows, &entity); err != nil {
+			return err
+		}
+
+		batch = append(batch, entity)
+		if len(batch) == batchSize {
+			if err := fn(batch); err != nil {
+				return err
+			}
+			batch = batch[:0]
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if len(batch) > 0 {
+		return fn(batch)
+	}
+	return nil
+}
+
+// CollectionStamp meringkas isi sebuah list (jumlah baris dan updated_at terbaru) untuk ETag collection
+type CollectionStamp struct {
+	Count        int64
+	LastModified time.Time
+}
+
+// Stamp menghitung CollectionStamp dari query dengan satu agregat, tanpa memuat barisnya.
+// Query tidak boleh membawa ORDER BY karena dijalankan sebagai agregat tanpa GROUP BY
+func (r *BaseRepository[T]) Stamp(query *gorm.DB) (CollectionStamp, error) {
+	var row struct {
+		Count        int64
+		LastModified aggregateTime
+	}
+	err := query.Select("COUNT(*) AS count, MAX(updated_at) AS last_modified").Scan(&row).Error
+	if err != nil {
+		return CollectionStamp{}, err
+	}
+	return CollectionStamp{Count: row.Count, LastModified: time.Time(row.LastModified)}, nil
+}
+
+// aggregateTime menampung hasil MAX() atas kolom waktu. MySQL mengembalikan DATETIME,
+// sedangkan SQLite (dipakai test) mengembalikannya sebagai teks
+type aggregateTime time.Time
+
+func (t *aggregateTime) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*t = aggregateTime{}
+	case time.Time:
+		*t = aggregateTime(v)
+	case []byte:
+		return t.Scan(string(v))
+	case string:
+		parsed, err := time.Parse("2006-01-02 15:04:05.999999999-07:00", v)
+		if err != nil {
+			return err
+		}
+		*t = aggregateTime(parsed)
+	default:
+		return fmt.Errorf("unsupported aggregate time %T", value)
+	}
+	return nil
+}
+
+// StampAll menghitung CollectionStamp untuk FindAll
+func (r *BaseRepository[T]) StampAll() (CollectionStamp, error) {
+	var entity T
+	return r.Stamp(r.DB.Model(&entity))
+}
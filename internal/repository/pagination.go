@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"codebase-api/internal/domain"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrInvalidCursor = errors.New("invalid pagination cursor")
+	// ErrCursorOrder tetap cocok dengan ErrInvalidCursor lewat errors.Is
+	ErrCursorOrder = fmt.Errorf("%w: cursor cannot be combined with a custom sort or search ranking", ErrInvalidCursor)
+)
+
+// PageRequest memilih mode offset (Page) atau keyset (After / Before)
+type PageRequest struct {
+	Page     int
+	PageSize int
+	After    string
+	Before   string
+}
+
+func (p PageRequest) IsCursor() bool {
+	return p.After != "" || p.Before != ""
+}
+
+type Page[T any] struct {
+	Items      []T
+	Page       int
+	PageSize   int
+	TotalItems int64
+	TotalPages int
+	NextCursor string
+	PrevCursor string
+}
+
+type cursor struct {
+	CreatedAt time.Time `json:"c"`
+	ID        uint      `json:"i"`
+}
+
+func encodeCursor(base domain.BaseDomain) string {
+	data, _ := json.Marshal(cursor{CreatedAt: base.CreatedAt, ID: base.ID})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(value string) (*cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var c cursor
+	if err := json.Unmarshal(data, &c); err != nil || c.ID == 0 {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+type baseDomainer interface {
+	Base() domain.BaseDomain
+}
+
+func cursorOf[T any](item T) string {
+	if b, ok := any(item).(baseDomainer); ok {
+		return encodeCursor(b.Base())
+	}
+	return ""
+}
+
+// customOrder melaporkan apakah query sudah membawa ORDER BY sendiri (sort kustom atau relevansi).
+// Urutan seperti itu tidak sejalan dengan keyset (created_at, id)
+func customOrder(query *gorm.DB) bool {
+	_, ok := query.Statement.Clauses["ORDER BY"]
+	return ok
+}
+
+// thenOrder menambahkan urutan di belakang ORDER BY yang sudah ada. Order() milik GORM membuang
+// ORDER BY berbentuk ekspresi (relevansi pencarian) begitu kolom baru digabungkan ke dalamnya
+func thenOrder(query *gorm.DB, order string) *gorm.DB {
+	if c, ok := query.Statement.Clauses["ORDER BY"]; ok {
+		if orderBy, ok := c.Expression.(clause.OrderBy); ok && orderBy.Expression != nil {
+			return query.Clauses(clause.OrderBy{Expression: clause.Expr{
+				SQL:                "?, " + order,
+				Vars:               []interface{}{orderBy.Expression},
+				WithoutParentheses: true,
+			}})
+		}
+	}
+	return query.Order(order)
+}
+
+// Paginate menjalankan query dengan LIMIT/OFFSET + COUNT terpisah, atau keyset pagination
+// pada (created_at, id) jika request berisi cursor
+func (r *BaseRepository[T]) Paginate(query *gorm.DB, req PageRequest) (*Page[T], error) {
+	if req.IsCursor() {
+		if customOrder(query) {
+			return nil, ErrCursorOrder
+		}
+		return r.paginateCursor(query, req)
+	}
+	return r.paginateOffset(query, req)
+}
+
+func (r *BaseRepository[T]) paginateOffset(query *gorm.DB, req PageRequest) (*Page[T], error) {
+	page := &Page[T]{Page: req.Page, PageSize: req.PageSize}
+	// Cursor hanya diberikan bila halaman diurutkan dengan (created_at, id), selain itu
+	// halaman berikutnya lewat cursor akan melompati atau mengulang baris
+	keyset := !customOrder(query)
+
+	if err := query.Session(&gorm.Session{}).Count(&page.TotalItems).Error; err != nil {
+		return nil, err
+	}
+	page.TotalPages = int((page.TotalItems + int64(req.PageSize) - 1) / int64(req.PageSize))
+
+	err := thenOrder(query.Session(&gorm.Session{}), "created_at ASC, id ASC").
+		Offset((req.Page - 1) * req.PageSize).Limit(req.PageSize).
+		Find(&page.Items).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if keyset && len(page.Items) > 0 {
+		if req.Page > 1 {
+			page.PrevCursor = cursorOf(page.Items[0])
+		}
+		if int64(req.Page*req.PageSize) < page.TotalItems {
+			page.NextCursor = cursorOf(page.Items[len(page.Items)-1])
+		}
+	}
+	return page, nil
+}
+
+func (r *BaseRepository[T]) paginateCursor(query *gorm.DB, req PageRequest) (*Page[T], error) {
+	page := &Page[T]{PageSize: req.PageSize}
+	forward := req.After != ""
+
+	anchor := req.After
+	if !forward {
+		anchor = req.Before
+	}
+	c, err := decodeCursor(anchor)
+	if err != nil {
+		return nil, err
+	}
+
+	q := query.Session(&gorm.Session{})
+	if forward {
+		q = q.Where("(created_at > ? OR (created_at = ? AND id > ?))", c.CreatedAt, c.CreatedAt, c.ID).
+			Order("created_at ASC").Order("id ASC")
+	} else {
+		q = q.Where("(created_at < ? OR (created_at = ? AND id < ?))", c.CreatedAt, c.CreatedAt, c.ID).
+			Order("created_at DESC").Order("id DESC")
+	}
+
+	// Ambil satu baris lebih untuk mengetahui apakah masih ada halaman berikutnya
+	if err := q.Limit(req.PageSize + 1).Find(&page.Items).Error; err != nil {
+		return nil, err
+	}
+
+	hasMore := len(page.Items) > req.PageSize
+	if hasMore {
+		page.Items = page.Items[:req.PageSize]
+	}
+	if !forward {
+		for i, j := 0, len(page.Items)-1; i < j; i, j = i+1, j-1 {
+			page.Items[i], page.Items[j] = page.Items[j], page.Items[i]
+		}
+	}
+
+	if len(page.Items) > 0 {
+		first, last := cursorOf(page.Items[0]), cursorOf(page.Items[len(page.Items)-1])
+		if forward {
+			page.PrevCursor = first
+			if hasMore {
+				page.NextCursor = last
+			}
+		} else {
+			page.NextCursor = last
+			if hasMore {
+				page.PrevCursor = first
+			}
+		}
+	}
+	return page, nil
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"codebase-api/internal/domain"
+	"codebase-api/internal/testdb"
+	"codebase-api/pkg/query"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// newPaginationRepo membuat 5 user; urutan username kebalikan dari urutan created_at
+func newPaginationRepo(t *testing.T) *UserRepository {
+	t.Helper()
+
+	repo := NewUserRepository(testdb.Open(t))
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		username := fmt.Sprintf("user%d", 5-i)
+		user := &domain.User{
+			BaseDomain: domain.BaseDomain{CreatedAt: start.Add(time.Duration(i) * time.Minute)},
+			Username:   username,
+			Email:      username + "@example.com",
+			Password:   "not-a-hash",
+			IsActive:   true,
+			Role:       domain.RoleUser,
+		}
+		if err := repo.Create(user); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return repo
+}
+
+func usernames(users []domain.User) []string {
+	names := make([]string, len(users))
+	for i, user := range users {
+		names[i] = user.Username
+	}
+	return names
+}
+
+func TestOffsetPageCursorContinuesDefaultOrder(t *testing.T) {
+	repo := newPaginationRepo(t)
+
+	first, err := repo.Searching(UserFilter{}, query.ListQuery{}, PageRequest{Page: 1, PageSize: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.NextCursor == "" {
+		t.Fatal("default order should return a next cursor")
+	}
+
+	next, err := repo.Searching(UserFilter{}, query.ListQuery{}, PageRequest{PageSize: 2, After: first.NextCursor})
+	if err != nil {
+		t.Fatal(err)
+	}
+	offset, err := repo.Searching(UserFilter{}, query.ListQuery{}, PageRequest{Page: 2, PageSize: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := fmt.Sprint(usernames(next.Items)), fmt.Sprint(usernames(offset.Items)); got != want {
+		t.Fatalf("cursor page = %s, offset page = %s", got, want)
+	}
+}
+
+func TestOffsetPageWithCustomSortHasNoCursor(t *testing.T) {
+	repo := newPaginationRepo(t)
+
+	q, err := query.Parse(map[string]string{"sort": "username"}, UserListSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	page, err := repo.Searching(UserFilter{}, q, PageRequest{Page: 2, PageSize: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fmt.Sprint(usernames(page.Items)); got != "[user3 user4]" {
+		t.Fatalf("sorted page = %s", got)
+	}
+	if page.NextCursor != "" || page.PrevCursor != "" {
+		t.Fatalf("custom sort returned cursors next=%q prev=%q", page.NextCursor, page.PrevCursor)
+	}
+}
+
+func TestCursorRejectsCustomOrder(t *testing.T) {
+	repo := newPaginationRepo(t)
+
+	first, err := repo.Searching(UserFilter{}, query.ListQuery{}, PageRequest{Page: 1, PageSize: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ordered := repo.DB.Model(&domain.User{}).Order("username ASC")
+	_, err = repo.Paginate(ordered, PageRequest{PageSize: 2, After: first.NextCursor})
+	if !errors.Is(err, ErrCursorOrder) || !errors.Is(err, ErrInvalidCursor) {
+		t.Fatalf("err = %v, want ErrCursorOrder", err)
+	}
+}
@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"codebase-api/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+type PasskeyRepository struct {
+	BaseRepository[domain.Passkey]
+}
+
+func NewPasskeyRepository(db *gorm.DB) *PasskeyRepository {
+	return &PasskeyRepository{
+		BaseRepository: *NewBaseRepository[domain.Passkey](db),
+	}
+}
+
+func (r *PasskeyRepository) FindByUserID(userID uint) ([]domain.Passkey, error) {
+	var passkeys []domain.Passkey
+	err := r.DB.Where("user_id = ?", userID).Order("created_at asc").Find(&passkeys).Error
+	return passkeys, err
+}
+
+func (r *PasskeyRepository) FindByUserIDAndID(userID uint, id uint) (*domain.Passkey, error) {
+	var passkey domain.Passkey
+	err := translateError(r.DB.Where("user_id = ? AND id = ?", userID, id).First(&passkey).Error)
+	if err != nil {
+		return nil, err
+	}
+	return &passkey, nil
+}
+
+func (r *PasskeyRepository) FindByCredentialID(credentialID []byte) (*domain.Passkey, error) {
+	var passkey domain.Passkey
+	err := translateError(r.DB.Where("credential_id = ?", credentialID).First(&passkey).Error)
+	if err != nil {
+		return nil, err
+	}
+	return &passkey, nil
+}
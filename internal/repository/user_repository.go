@@ -2,39 +2,224 @@ package repository
 
 import (
 	"codebase-api/internal/domain"
+	"codebase-api/pkg/identity"
+	"codebase-api/pkg/query"
+	"time"
 
 	"gorm.io/gorm"
 )
 
 type UserRepository struct {
 	BaseRepository[domain.User]
+	search UserSearchBackend
 }
 
 func NewUserRepository(db *gorm.DB) *UserRepository {
 	return &UserRepository{
 		BaseRepository: *NewBaseRepository[domain.User](db),
+		search:         NewMySQLUserSearch(),
 	}
 }
 
+// WithSearchBackend mengganti backend pencarian, misalnya MemoryUserSearch untuk test
+func (r *UserRepository) WithSearchBackend(backend UserSearchBackend) *UserRepository {
+	r.search = backend
+	return r
+}
+
 func (r *UserRepository) GetUserByUsername(username string) (*domain.User, error) {
 	var user domain.User
-	err := r.DB.Where("username = ?", username).First(&user).Error
+	err := translateError(r.DB.Where("username_canonical = ?", identity.Username(username)).First(&user).Error)
 	return &user, err
 }
 
-func (r *UserRepository) Searching(isActive *bool, search string) ([]domain.User, error) {
+// UserFilter adalah filter list user di luar query language filter[...]
+type UserFilter struct {
+	IsActive *bool
+	Search   string
+	// GroupID adalah UUID group; hanya anggota group tersebut yang dikembalikan
+	GroupID string
+}
+
+// searchQuery membangun query dasar list user; rank mengurutkan hasil pencarian berdasarkan relevansi
+func (r *UserRepository) searchQuery(filter UserFilter, rank bool) *gorm.DB {
+	query := r.DB.Model(&domain.User{})
+	if filter.IsActive != nil {
+		query = query.Where("is_active = ?", *filter.IsActive)
+	}
+	if filter.GroupID != "" {
+		query = query.Where("id IN (SELECT gm.user_id FROM group_members gm JOIN user_groups g ON g.id = gm.group_id WHERE g.uuid = ? AND g.deleted_at IS NULL)", filter.GroupID)
+	}
+	return r.search.Scope(query, filter.Search, rank)
+}
+
+// UserListSchema adalah allow-list field yang boleh dipakai pada filter[...] dan sort di list user
+var UserListSchema = query.Schema{
+	"id":         {Column: "uuid", Type: query.String, Operators: []query.Operator{query.Eq, query.In}},
+	"username":   {Column: "username", Type: query.String, Operators: []query.Operator{query.Eq, query.Ne, query.Like, query.In}, Sortable: true},
+	"email":      {Column: "email", Type: query.String, Operators: []query.Operator{query.Eq, query.Ne, query.Like, query.In}, Sortable: true},
+	"phone":      {Column: "phone", Type: query.String, Operators: []query.Operator{query.Eq, query.Like}},
+	"first_name": {Column: "first_name", Type: query.String, Operators: []query.Operator{query.Eq, query.Like}, Sortable: true},
+	"last_name":  {Column: "last_name", Type: query.String, Operators: []query.Operator{query.Eq, query.Like}, Sortable: true},
+	"is_active":  {Column: "is_active", Type: query.Bool, Operators: []query.Operator{query.Eq}, Sortable: true},
+	"created_at": {Column: "created_at", Type: query.Time, Operators: []query.Operator{query.Eq, query.Gt, query.Gte, query.Lt, query.Lte}, Sortable: true},
+	"updated_at": {Column: "updated_at", Type: query.Time, Operators: []query.Operator{query.Eq, query.Gt, query.Gte, query.Lt, query.Lte}, Sortable: true},
+}
+
+func (r *UserRepository) Searching(filter UserFilter, q query.ListQuery, req PageRequest) (*Page[domain.User], error) {
+	// Relevansi hanya dipakai bila client tidak meminta sort sendiri dan tidak memakai cursor
+	rank := len(q.Sorts) == 0 && !req.IsCursor()
+	return r.List(r.searchQuery(filter, rank), q, UserListSchema, req)
+}
+
+// CountMatching menghitung user yang cocok dengan filter Searching
+func (r *UserRepository) CountMatching(filter UserFilter, q query.ListQuery) (int64, error) {
+	var count int64
+	err := q.Apply(r.searchQuery(filter, false), UserListSchema).Count(&count).Error
+	return count, err
+}
+
+// StampMatching menghitung CollectionStamp untuk user yang cocok dengan filter Searching; sort diabaikan
+func (r *UserRepository) StampMatching(filter UserFilter, q query.ListQuery) (CollectionStamp, error) {
+	filters := query.ListQuery{Filters: q.Filters}
+	stamp, err := r.Stamp(filters.Apply(r.searchQuery(filter, false), UserListSchema))
+	if err != nil || filter.GroupID == "" {
+		return stamp, err
+	}
+
+	// Anggota bisa ditambah / dikeluarkan tanpa mengubah baris users. Keanggotaan dihapus permanen,
+	// jadi pengeluaran terlihat dari count dan penambahan dari updated_at keanggotaan terbaru
+	var row struct {
+		LastModified aggregateTime
+	}
+	err = r.DB.Table("group_members gm").
+		Select("MAX(gm.updated_at) AS last_modified").
+		Joins("JOIN user_groups g ON g.id = gm.group_id").
+		Where("g.uuid = ?", filter.GroupID).
+		Scan(&row).Error
+	if err != nil {
+		return CollectionStamp{}, err
+	}
+	if membership := time.Time(row.LastModified); membership.After(stamp.LastModified) {
+		stamp.LastModified = membership
+	}
+	return stamp, nil
+}
+
+// Export mengalirkan user yang cocok dengan filter Searching per batch
+func (r *UserRepository) Export(filter UserFilter, q query.ListQuery, batchSize int, fn func([]domain.User) error) error {
+	base := q.Apply(r.searchQuery(filter, len(q.Sorts) == 0), UserListSchema)
+	return r.Each(thenOrder(base, "created_at ASC, id ASC"), batchSize, fn)
+}
+
+// UpdatePreferences hanya mengubah kolom preferences (dan menaikkan version) agar tidak menimpa perubahan
+// profil lain. version adalah version saat preferensi dibaca; bila baris sudah berubah, domain.ErrStaleVersion
+func (r *UserRepository) UpdatePreferences(id uint, version uint, data []byte) error {
+	result := r.DB.Model(&domain.User{}).Where("id = ? AND version = ?", id, version).
+		Updates(map[string]interface{}{"preferences": data, "version": gorm.Expr("version + 1")})
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrStaleVersion
+	}
+	return nil
+}
+
+// FindConflict mengembalikan nama field (username, email, phone) yang bentuk kanoniknya sudah dipakai user lain
+func (r *UserRepository) FindConflict(user *domain.User) (string, error) {
+	fields := []string{"username", "email"}
+	columns := []string{"username_canonical", "email_canonical"}
+	values := []interface{}{identity.Username(user.Username), identity.Email(user.Email)}
+	if user.Phone != nil && identity.Phone(*user.Phone) != "" {
+		fields = append(fields, "phone")
+		columns = append(columns, "phone_canonical")
+		values = append(values, identity.Phone(*user.Phone))
+	}
+
+	for i, field := range fields {
+		var count int64
+		err := r.DB.Model(&domain.User{}).
+			Where(columns[i]+" = ? AND id <> ?", values[i], user.ID).
+			Count(&count).Error
+		if err != nil {
+			return "", err
+		}
+		if count > 0 {
+			return field, nil
+		}
+	}
+	return "", nil
+}
+
+// ScimColumns memetakan attribute SCIM (huruf kecil) ke kolom tabel users
+var ScimColumns = map[string]string{
+	"id":                 "uuid",
+	"externalid":         "external_id",
+	"username":           "username",
+	"name.givenname":     "first_name",
+	"name.familyname":    "last_name",
+	"emails":             "email",
+	"emails.value":       "email",
+	"phonenumbers":       "phone",
+	"phonenumbers.value": "phone",
+	"active":             "is_active",
+	"meta.created":       "created_at",
+	"meta.lastmodified":  "updated_at",
+}
+
+func (r *UserRepository) FindByTenantAndUUID(tenant string, uuid string) (*domain.User, error) {
+	var user domain.User
+	err := translateError(r.DB.Where("provisioning_tenant = ? AND uuid = ?", tenant, uuid).First(&user).Error)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// ScimSearch mencari user milik tenant SCIM dengan klausa filter yang sudah diterjemahkan
+func (r *UserRepository) ScimSearch(tenant string, where string, args []interface{}, offset int, limit int) ([]domain.User, int64, error) {
 	var users []domain.User
+	var total int64
 
-	query := r.DB
-	if isActive != nil {
-		query = query.Where("is_active = ?", *isActive)
+	query := r.DB.Model(&domain.User{}).Where("provisioning_tenant = ?", tenant)
+	if where != "" {
+		query = query.Where(where, args...)
 	}
 
-	if search != "" {
-		searchTerm := "%" + search + "%"
-		query = query.Where("username LIKE ? OR email LIKE ? OR phone LIKE ?", searchTerm, searchTerm, searchTerm)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
 	}
 
-	err := query.Find(&users).Error
-	return users, err
+	err := query.Order("id asc").Offset(offset).Limit(limit).Find(&users).Error
+	return users, total, err
+}
+
+// Purge menghapus user beserta passkey miliknya secara permanen
+func (r *UserRepository) Purge(id uint) error {
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("user_id = ?", id).Delete(&domain.Passkey{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("user_id = ?", id).Delete(&domain.GroupMember{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(&domain.User{}, id).Error
+	})
+	return translateError(err)
+}
+
+// Erase menyimpan user yang sudah dianonimkan, menghapus passkey-nya dan mencatat erasure dalam satu transaksi
+func (r *UserRepository) Erase(user *domain.User, record *domain.ErasureRecord) error {
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("user_id = ?", user.ID).Delete(&domain.Passkey{}).Error; err != nil {
+			return err
+		}
+		// Unscoped agar user yang sudah ada di trash juga bisa dianonimkan
+		if err := updateVersioned(tx.Unscoped(), user); err != nil {
+			return err
+		}
+		return tx.Create(record).Error
+	})
+	return translateError(err)
 }
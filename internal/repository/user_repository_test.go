@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"codebase-api/internal/domain"
+	"codebase-api/internal/testdb"
+	"codebase-api/pkg/query"
+	"testing"
+	"time"
+)
+
+func TestStampMatchingTracksGroupMembership(t *testing.T) {
+	db := testdb.Open(t)
+	users := NewUserRepository(db)
+	groups := NewGroupRepository(db)
+	members := NewGroupMemberRepository(db)
+
+	newUser := func(username string) *domain.User {
+		user := &domain.User{Username: username, Email: username + "@example.com", Password: "not-a-hash", IsActive: true, Role: domain.RoleUser}
+		if err := users.Create(user); err != nil {
+			t.Fatal(err)
+		}
+		return user
+	}
+	// siti dibuat paling awal sehingga menukar budi dengan siti tidak menaikkan MAX(users.updated_at)
+	siti := newUser("siti")
+	budi := newUser("budi")
+	owner := newUser("owner")
+
+	group := &domain.Group{Name: "Finance"}
+	if err := groups.CreateWithOwner(group, owner.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := members.Create(&domain.GroupMember{GroupID: group.ID, UserID: budi.ID, Role: domain.GroupRoleMember}); err != nil {
+		t.Fatal(err)
+	}
+
+	filter := UserFilter{GroupID: group.UUID.String()}
+	stamp := func() CollectionStamp {
+		t.Helper()
+		stamp, err := users.StampMatching(filter, query.ListQuery{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return stamp
+	}
+
+	before := stamp()
+	if before.Count != 2 {
+		t.Fatalf("count = %d, want 2", before.Count)
+	}
+
+	// Keluarkan budi lalu masukkan siti: jumlah anggota sama, baris users tidak berubah
+	time.Sleep(time.Millisecond)
+	if err := members.Remove(group.ID, budi.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := members.Create(&domain.GroupMember{GroupID: group.ID, UserID: siti.ID, Role: domain.GroupRoleMember}); err != nil {
+		t.Fatal(err)
+	}
+
+	after := stamp()
+	if after.Count != before.Count {
+		t.Fatalf("count changed %d -> %d", before.Count, after.Count)
+	}
+	if !after.LastModified.After(before.LastModified) {
+		t.Fatalf("stamp did not change after membership swap: %v -> %v", before.LastModified, after.LastModified)
+	}
+}
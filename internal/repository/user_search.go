@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"codebase-api/internal/domain"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// UserSearchBackend membatasi query user ke hasil pencarian full-text.
+// Jika rank bernilai true hasil diurutkan berdasarkan relevansi tertinggi
+type UserSearchBackend interface {
+	Scope(db *gorm.DB, term string, rank bool) *gorm.DB
+}
+
+// searchTokens memecah term menjadi kata huruf kecil dengan aturan yang mirip parser FULLTEXT MySQL,
+// sehingga "john@example.com" menjadi john, example, com
+func searchTokens(term string) []string {
+	return strings.FieldsFunc(strings.ToLower(term), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// MySQLUserSearch memakai index FULLTEXT idx_users_search dalam boolean mode
+type MySQLUserSearch struct{}
+
+const userSearchColumns = "first_name, last_name, username, email, phone"
+
+func NewMySQLUserSearch() *MySQLUserSearch {
+	return &MySQLUserSearch{}
+}
+
+func (s *MySQLUserSearch) Scope(db *gorm.DB, term string, rank bool) *gorm.DB {
+	tokens := searchTokens(term)
+	if len(tokens) == 0 {
+		return db
+	}
+
+	// Setiap kata wajib ada (+) dan dicocokkan sebagai prefix (*)
+	for i, token := range tokens {
+		tokens[i] = "+" + token + "*"
+	}
+	expr := strings.Join(tokens, " ")
+
+	db = db.Where("MATCH("+userSearchColumns+") AGAINST (? IN BOOLEAN MODE)", expr)
+	if rank {
+		db = db.Order(clause.OrderBy{Expression: clause.Expr{
+			SQL:                "MATCH(" + userSearchColumns + ") AGAINST (? IN BOOLEAN MODE) DESC",
+			Vars:               []interface{}{expr},
+			WithoutParentheses: true,
+		}})
+	}
+	return db
+}
+
+// SearchHit adalah satu hasil dari MemoryUserSearch beserta skor relevansinya
+type SearchHit struct {
+	ID    uint
+	Score float64
+}
+
+// MemoryUserSearch adalah index in-memory untuk test dan development tanpa MySQL.
+// Index tidak ikut diperbarui oleh repository; pemanggil meng-Index user setelah menyimpannya
+type MemoryUserSearch struct {
+	mu        sync.RWMutex
+	documents map[uint][]string
+}
+
+func NewMemoryUserSearch() *MemoryUserSearch {
+	return &MemoryUserSearch{documents: map[uint][]string{}}
+}
+
+// Index menambahkan atau memperbarui dokumen milik user
+func (s *MemoryUserSearch) Index(user domain.User) {
+	fields := []string{user.FirstName, user.LastName, user.Username, user.Email}
+	if user.Phone != nil {
+		fields = append(fields, *user.Phone)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.documents[user.ID] = searchTokens(strings.Join(fields, " "))
+}
+
+func (s *MemoryUserSearch) Remove(id uint) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.documents, id)
+}
+
+// Search mengembalikan user yang memuat semua kata (sebagai prefix), kecocokan penuh bernilai lebih tinggi
+func (s *MemoryUserSearch) Search(term string) []SearchHit {
+	tokens := searchTokens(term)
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var hits []SearchHit
+	for id, words := range s.documents {
+		score, matched := 0.0, true
+		for _, token := range tokens {
+			best := 0.0
+			for _, word := range words {
+				if word == token {
+					best = 1
+					break
+				}
+				if strings.HasPrefix(word, token) {
+					best = max(best, float64(len(token))/float64(len(word)))
+				}
+			}
+			if best == 0 {
+				matched = false
+				break
+			}
+			score += best
+		}
+		if matched {
+			hits = append(hits, SearchHit{ID: id, Score: score})
+		}
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].ID < hits[j].ID
+	})
+	return hits
+}
+
+func (s *MemoryUserSearch) Scope(db *gorm.DB, term string, rank bool) *gorm.DB {
+	if len(searchTokens(term)) == 0 {
+		return db
+	}
+
+	hits := s.Search(term)
+	if len(hits) == 0 {
+		return db.Where("1 = 0")
+	}
+
+	ids := make([]uint, len(hits))
+	for i, hit := range hits {
+		ids[i] = hit.ID
+	}
+	db = db.Where("id IN ?", ids)
+
+	if rank {
+		// CASE dipakai agar urutan relevansi tetap portable di luar MySQL
+		var sql strings.Builder
+		vars := make([]interface{}, 0, len(ids)*2)
+		sql.WriteString("CASE id")
+		for i, id := range ids {
+			sql.WriteString(" WHEN ? THEN ?")
+			vars = append(vars, id, i)
+		}
+		sql.WriteString(" END")
+		db = db.Order(clause.OrderBy{Expression: clause.Expr{SQL: sql.String(), Vars: vars, WithoutParentheses: true}})
+	}
+	return db
+}
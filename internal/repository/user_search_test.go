@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"codebase-api/internal/domain"
+	"codebase-api/internal/testdb"
+	"codebase-api/pkg/query"
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestSearchTokens(t *testing.T) {
+	got := fmt.Sprint(searchTokens("  John.Doe@Example.com +62 "))
+	if got != "[john doe example com 62]" {
+		t.Fatalf("tokens = %s", got)
+	}
+}
+
+// newSearchRepo membuat user di SQLite dan meng-index semuanya ke MemoryUserSearch
+func newSearchRepo(t *testing.T) *UserRepository {
+	t.Helper()
+
+	search := NewMemoryUserSearch()
+	repo := NewUserRepository(testdb.Open(t)).WithSearchBackend(search)
+	phone := "+6281234567890"
+	for _, user := range []*domain.User{
+		{Username: "budiman", FirstName: "Budiman", LastName: "Hartono", Email: "budiman@example.com"},
+		{Username: "budi", FirstName: "Budi", LastName: "Santoso", Email: "budi@example.com"},
+		{Username: "siti", FirstName: "Siti", LastName: "Budiarti", Email: "siti@corp.example.com", Phone: &phone},
+		{Username: "andi", FirstName: "Andi", LastName: "Santoso", Email: "andi@example.com"},
+	} {
+		user.Password, user.IsActive, user.Role = "not-a-hash", true, domain.RoleUser
+		if err := repo.Create(user); err != nil {
+			t.Fatal(err)
+		}
+		search.Index(*user)
+	}
+	return repo
+}
+
+func TestMemoryUserSearchRanksAndMatchesPrefixes(t *testing.T) {
+	repo := newSearchRepo(t)
+
+	tests := []struct {
+		name string
+		term string
+		want []string
+	}{
+		// Kecocokan penuh (budi) lebih relevan daripada prefix yang lebih panjang (budiman, budiarti)
+		{name: "exact before prefix", term: "budi", want: []string{"budi", "budiman", "siti"}},
+		{name: "prefix", term: "bud", want: []string{"budi", "budiman", "siti"}},
+		{name: "every word must match", term: "santoso bud", want: []string{"budi"}},
+		{name: "last name", term: "Santoso", want: []string{"budi", "andi"}},
+		{name: "email domain", term: "corp.example", want: []string{"siti"}},
+		{name: "phone", term: "+62812", want: []string{"siti"}},
+		{name: "no match", term: "joko", want: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := repo.Searching(UserFilter{Search: tt.term}, query.ListQuery{}, PageRequest{Page: 1, PageSize: 10})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := usernames(page.Items); !slices.Equal(got, tt.want) {
+				t.Fatalf("results = %v, want %v", got, tt.want)
+			}
+			if page.TotalItems != int64(len(tt.want)) {
+				t.Fatalf("total = %d, want %d", page.TotalItems, len(tt.want))
+			}
+		})
+	}
+}
+
+func TestSearchingWithSortIgnoresRelevance(t *testing.T) {
+	repo := newSearchRepo(t)
+
+	q := query.ListQuery{Sorts: []query.Sort{{Field: "username", Desc: true}}}
+	page, err := repo.Searching(UserFilter{Search: "bud"}, q, PageRequest{Page: 1, PageSize: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := usernames(page.Items), []string{"siti", "budiman", "budi"}; !slices.Equal(got, want) {
+		t.Fatalf("results = %v, want %v", got, want)
+	}
+}
+
+func TestMemoryUserSearchRemove(t *testing.T) {
+	search := NewMemoryUserSearch()
+	search.Index(domain.User{BaseDomain: domain.BaseDomain{ID: 1}, Username: "budi"})
+	search.Index(domain.User{BaseDomain: domain.BaseDomain{ID: 2}, Username: "budiman"})
+
+	search.Remove(1)
+	hits := search.Search("budi")
+	if len(hits) != 1 || hits[0].ID != 2 {
+		t.Fatalf("hits = %+v, want only user 2", hits)
+	}
+}
+
+func TestExportKeepsRelevanceOrder(t *testing.T) {
+	repo := newSearchRepo(t)
+
+	var got []string
+	err := repo.Export(UserFilter{Search: "bud"}, query.ListQuery{}, 2, func(batch []domain.User) error {
+		got = append(got, usernames(batch)...)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"budi", "budiman", "siti"}; !slices.Equal(got, want) {
+		t.Fatalf("exported = %v, want %v", got, want)
+	}
+}
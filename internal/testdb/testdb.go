@@ -0,0 +1,78 @@
+// Package testdb menyediakan database SQLite in-memory dengan schema aplikasi untuk test
+// use case dan repository, sehingga test tidak membutuhkan server MySQL
+package testdb
+
+import (
+	"codebase-api/internal/domain"
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Models adalah daftar model yang dimigrasikan, sama dengan config.InitDB
+var Models = []interface{}{&domain.User{}, &domain.Passkey{}, &domain.Group{}, &domain.GroupMember{}, &domain.ErasureRecord{}}
+
+var counter atomic.Int64
+
+// Open membuat database baru per test; database dihapus saat test selesai
+func Open(t testing.TB) *gorm.DB {
+	t.Helper()
+
+	db := OpenEmpty(t)
+	for _, model := range Models {
+		if err := migrate(db, model); err != nil {
+			t.Fatalf("migrate %T: %v", model, err)
+		}
+	}
+	return db
+}
+
+// OpenEmpty membuat database baru tanpa schema, misalnya untuk menguji migration dari schema lama
+func OpenEmpty(t testing.TB) *gorm.DB {
+	t.Helper()
+
+	dsn := fmt.Sprintf("file:testdb%d?mode=memory&cache=shared&_foreign_keys=1", counter.Add(1))
+	// TranslateError mengubah pelanggaran unique index SQLite menjadi gorm.ErrDuplicatedKey,
+	// padanan error 1062 MySQL yang diterjemahkan repository
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent), TranslateError: true})
+	if err != nil {
+		t.Fatalf("open test database: %v", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("open test database: %v", err)
+	}
+	// Satu koneksi agar database in-memory tidak hilang dan penulisan tidak saling mengunci
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	return db
+}
+
+// migrate menjalankan AutoMigrate tanpa index FULLTEXT yang hanya ada di MySQL
+func migrate(db *gorm.DB, model interface{}) error {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return err
+	}
+
+	migrator := db.Migrator()
+	if !migrator.HasTable(model) {
+		if err := migrator.CreateTable(model); err != nil && !migrator.HasTable(model) {
+			return err
+		}
+	}
+	for name, index := range stmt.Schema.ParseIndexes() {
+		if index.Class == "FULLTEXT" || migrator.HasIndex(model, name) {
+			continue
+		}
+		if err := migrator.CreateIndex(model, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
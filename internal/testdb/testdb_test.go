@@ -0,0 +1,24 @@
+package testdb
+
+import (
+	"codebase-api/internal/domain"
+	"testing"
+)
+
+func TestOpenCreatesSchema(t *testing.T) {
+	db := Open(t)
+	for _, name := range []string{"uq_users_username", "uq_users_email", "uq_users_phone"} {
+		if !db.Migrator().HasIndex(&domain.User{}, name) {
+			t.Errorf("index %s missing", name)
+		}
+	}
+
+	user := domain.User{Username: "Budi", Email: "budi@example.com", Password: "x", IsActive: true}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+	duplicate := domain.User{Username: "budi", Email: "other@example.com", Password: "x"}
+	if err := db.Create(&duplicate).Error; err == nil {
+		t.Fatal("canonical username duplicate was accepted")
+	}
+}
@@ -0,0 +1,53 @@
+package usecase
+
+import (
+	"codebase-api/internal/domain"
+	"codebase-api/internal/repository"
+	"errors"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ErrAuthenticatorSkip dikembalikan authenticator yang tidak menangani user tersebut,
+// sehingga authenticator berikutnya di chain dicoba
+var ErrAuthenticatorSkip = errors.New("authenticator does not handle this user")
+
+// Authenticator memverifikasi username dan password terhadap satu backend (database lokal, LDAP, ...)
+type Authenticator interface {
+	Name() string
+	Authenticate(username, password string) (*domain.User, error)
+}
+
+// LocalAuthenticator memverifikasi password bcrypt yang tersimpan di tabel users
+type LocalAuthenticator struct {
+	userRepo *repository.UserRepository
+}
+
+func NewLocalAuthenticator(userRepo *repository.UserRepository) *LocalAuthenticator {
+	return &LocalAuthenticator{userRepo: userRepo}
+}
+
+func (a *LocalAuthenticator) Name() string {
+	return domain.AuthSourceLocal
+}
+
+func (a *LocalAuthenticator) Authenticate(username, password string) (*domain.User, error) {
+	user, err := a.userRepo.GetUserByUsername(username)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, ErrAuthenticatorSkip
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Password user dari backend lain tidak disimpan secara lokal
+	if user.AuthSource != "" && user.AuthSource != domain.AuthSourceLocal {
+		return nil, ErrAuthenticatorSkip
+	}
+
+	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password))
+	if err != nil {
+		return nil, ErrInvalidCredentials
+	}
+	return user, nil
+}
@@ -0,0 +1,136 @@
+package usecase
+
+import (
+	"bytes"
+	"codebase-api/internal/domain"
+	"codebase-api/internal/repository"
+	"crypto/rand"
+	"encoding/hex"
+	"image"
+	"image/color"
+	"image/jpeg"
+
+	"github.com/disintegration/imaging"
+	"github.com/gabriel-vasile/mimetype"
+	_ "golang.org/x/image/webp"
+)
+
+const (
+	AvatarMaxBytes = 5 << 20
+	// Batas dimensi mencegah decompression bomb (file kecil dengan resolusi sangat besar)
+	avatarMaxPixels = 40_000_000
+	avatarQuality   = 85
+)
+
+var (
+	ErrAvatarTooLarge    = domain.TooLarge("avatar exceeds the maximum size")
+	ErrAvatarUnsupported = domain.Unsupported("avatar must be a JPEG, PNG, GIF or WebP image")
+	ErrAvatarInvalid     = domain.Unsupported("avatar image could not be decoded")
+)
+
+var avatarMimeTypes = []string{"image/jpeg", "image/png", "image/gif", "image/webp"}
+
+type AvatarUseCase struct {
+	userRepo *repository.UserRepository
+	files    FileStore
+}
+
+func NewAvatarUseCase(userRepo *repository.UserRepository, files FileStore) *AvatarUseCase {
+	return &AvatarUseCase{userRepo: userRepo, files: files}
+}
+
+// Upload memvalidasi gambar berdasarkan isinya, membuat thumbnail persegi dan mengganti avatar lama.
+// Gambar selalu di-encode ulang sehingga metadata EXIF (lokasi GPS, kamera) ikut terbuang
+func (u *AvatarUseCase) Upload(userID uint, data []byte) (*domain.User, error) {
+	if len(data) > AvatarMaxBytes {
+		return nil, ErrAvatarTooLarge
+	}
+	if !isAvatarMime(mimetype.Detect(data)) {
+		return nil, ErrAvatarUnsupported
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrAvatarInvalid
+	}
+	if config.Width*config.Height > avatarMaxPixels {
+		return nil, ErrAvatarTooLarge
+	}
+
+	// AutoOrientation menerapkan orientasi EXIF sebelum metadata dibuang
+	img, err := imaging.Decode(bytes.NewReader(data), imaging.AutoOrientation(true))
+	if err != nil {
+		return nil, ErrAvatarInvalid
+	}
+
+	user, err := u.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, notFoundAs(err, ErrUserNotFound)
+	}
+
+	token := make([]byte, 8)
+	if _, err := rand.Read(token); err != nil {
+		return nil, err
+	}
+	// Token acak di path membuat URL baru setiap upload sehingga cache CDN / browser tidak basi
+	path := "avatars/" + user.UUID.String() + "/" + hex.EncodeToString(token)
+
+	previous := *user
+	user.AvatarPath = &path
+
+	for size, key := range user.AvatarKeys() {
+		thumbnail := imaging.Fill(img, size, size, imaging.Center, imaging.Lanczos)
+		// JPEG tidak mendukung transparansi, ratakan di atas latar putih
+		flattened := imaging.Overlay(imaging.New(size, size, color.White), thumbnail, image.Pt(0, 0), 1)
+
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, flattened, &jpeg.Options{Quality: avatarQuality}); err != nil {
+			return nil, err
+		}
+		if err := u.files.Put(key, buf.Bytes()); err != nil {
+			u.deleteVariants(*user)
+			return nil, err
+		}
+	}
+
+	if err := u.userRepo.Update(user); err != nil {
+		u.deleteVariants(*user)
+		return nil, err
+	}
+
+	u.deleteVariants(previous)
+	return user, nil
+}
+
+// Remove menghapus avatar user beserta semua ukurannya
+func (u *AvatarUseCase) Remove(userID uint) (*domain.User, error) {
+	user, err := u.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, notFoundAs(err, ErrUserNotFound)
+	}
+
+	previous := *user
+	user.AvatarPath = nil
+	if err := u.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+
+	u.deleteVariants(previous)
+	return user, nil
+}
+
+// deleteVariants bersifat best effort; file yatim tidak mempengaruhi data user
+func (u *AvatarUseCase) deleteVariants(user domain.User) {
+	for _, key := range user.AvatarKeys() {
+		u.files.Delete(key)
+	}
+}
+
+func isAvatarMime(detected *mimetype.MIME) bool {
+	for _, mime := range avatarMimeTypes {
+		if detected.Is(mime) {
+			return true
+		}
+	}
+	return false
+}
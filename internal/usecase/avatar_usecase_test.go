@@ -0,0 +1,149 @@
+package usecase
+
+import (
+	"bytes"
+	"codebase-api/internal/domain"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func newAvatarFixture(t *testing.T) (*AvatarUseCase, *memoryFiles, *domain.User) {
+	t.Helper()
+
+	userRepo := newUserRepo(t)
+	files := newMemoryFiles()
+	return NewAvatarUseCase(userRepo, files), files, createUser(t, userRepo, "budi")
+}
+
+func testImage(width, height int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+	return img
+}
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// jpegWithExif menyisipkan segmen APP1 Exif berisi marker setelah SOI, seperti foto dari kamera
+func jpegWithExif(t *testing.T, marker string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, testImage(40, 30), nil); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	payload := append([]byte("Exif\x00\x00MM\x00\x2a\x00\x00\x00\x08\x00\x00\x00\x00\x00\x00"), marker...)
+	segment := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	segment = append(segment, payload...)
+
+	return append(append(append([]byte{}, data[:2]...), segment...), data[2:]...)
+}
+
+func TestAvatarUploadCreatesSquareVariants(t *testing.T) {
+	avatars, files, user := newAvatarFixture(t)
+
+	updated, err := avatars.Upload(user.ID, encodePNG(t, testImage(300, 200)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.AvatarPath == nil || len(files.files) != len(domain.AvatarSizes) {
+		t.Fatalf("avatar path = %v, stored %d files", updated.AvatarPath, len(files.files))
+	}
+	for size, key := range updated.AvatarKeys() {
+		config, format, err := image.DecodeConfig(bytes.NewReader(files.files[key]))
+		if err != nil || format != "jpeg" || config.Width != size || config.Height != size {
+			t.Fatalf("%s = %s %dx%d, %v, want jpeg %dx%d", key, format, config.Width, config.Height, err, size, size)
+		}
+	}
+
+	// Upload baru menghapus semua ukuran avatar lama
+	previous := updated.AvatarKeys()
+	replaced, err := avatars.Upload(user.ID, encodePNG(t, testImage(64, 64)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *replaced.AvatarPath == *updated.AvatarPath || len(files.files) != len(domain.AvatarSizes) {
+		t.Fatalf("replaced avatar path = %s, stored %d files", *replaced.AvatarPath, len(files.files))
+	}
+	for _, key := range previous {
+		if _, ok := files.files[key]; ok {
+			t.Fatalf("old variant %s was kept", key)
+		}
+	}
+}
+
+func TestAvatarUploadStripsExif(t *testing.T) {
+	avatars, files, user := newAvatarFixture(t)
+	const marker = "camera-serial-1234567"
+
+	upload := jpegWithExif(t, marker)
+	if !bytes.Contains(upload, []byte(marker)) {
+		t.Fatal("fixture does not contain the EXIF marker")
+	}
+	if _, err := avatars.Upload(user.ID, upload); err != nil {
+		t.Fatal(err)
+	}
+	for key, data := range files.files {
+		if bytes.Contains(data, []byte("Exif")) || bytes.Contains(data, []byte(marker)) {
+			t.Fatalf("%s still contains EXIF metadata", key)
+		}
+	}
+}
+
+func TestAvatarUploadRejectsInvalidFiles(t *testing.T) {
+	avatars, files, user := newAvatarFixture(t)
+
+	// GIF 1x1 yang header-nya mengklaim 8000x8000 pixel (decompression bomb)
+	var bomb bytes.Buffer
+	if err := gif.Encode(&bomb, testImage(1, 1), nil); err != nil {
+		t.Fatal(err)
+	}
+	binary.LittleEndian.PutUint16(bomb.Bytes()[6:], 8000)
+	binary.LittleEndian.PutUint16(bomb.Bytes()[8:], 8000)
+
+	// PNG yang terpotong lolos deteksi MIME tetapi tidak bisa di-decode
+	truncated := encodePNG(t, testImage(20, 20))[:40]
+
+	tests := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{name: "plain text", data: []byte("definitely not an image"), want: ErrAvatarUnsupported},
+		{name: "svg", data: []byte(`<svg xmlns="http://www.w3.org/2000/svg" width="10" height="10"></svg>`), want: ErrAvatarUnsupported},
+		{name: "pdf", data: []byte("%PDF-1.7\n1 0 obj\n<<>>\nendobj\n"), want: ErrAvatarUnsupported},
+		{name: "oversized file", data: make([]byte, AvatarMaxBytes+1), want: ErrAvatarTooLarge},
+		{name: "too many pixels", data: bomb.Bytes(), want: ErrAvatarTooLarge},
+		{name: "truncated png", data: truncated, want: ErrAvatarInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := avatars.Upload(user.ID, tt.data); !errors.Is(err, tt.want) {
+				t.Fatalf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+	if len(files.files) != 0 {
+		t.Fatalf("rejected uploads stored %d files", len(files.files))
+	}
+}
@@ -0,0 +1,222 @@
+package usecase
+
+import (
+	"codebase-api/config"
+	"codebase-api/internal/domain"
+	"codebase-api/internal/repository"
+	"codebase-api/pkg/identity"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	emailChangeTTL = 24 * time.Hour
+	emailRevertTTL = 7 * 24 * time.Hour
+)
+
+var (
+	ErrEmailChangeToken = domain.Invalid("token", "email change token is invalid or expired")
+	ErrEmailUnchanged   = domain.Invalid("email", "new email is the same as the current email")
+)
+
+// emailChange disimpan di KeyValueStore dengan key berupa hash token, bukan token itu sendiri
+type emailChange struct {
+	UserID   uint   `json:"user_id"`
+	OldEmail string `json:"old_email"`
+	NewEmail string `json:"new_email"`
+}
+
+type EmailChangeUseCase struct {
+	userRepo  *repository.UserRepository
+	store     KeyValueStore
+	publisher MessagePublisher
+}
+
+func NewEmailChangeUseCase(userRepo *repository.UserRepository, store KeyValueStore, publisher MessagePublisher) *EmailChangeUseCase {
+	return &EmailChangeUseCase{userRepo: userRepo, store: store, publisher: publisher}
+}
+
+func emailChangeKey(kind string, token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return "email_change:" + kind + ":" + hex.EncodeToString(sum[:])
+}
+
+func emailChangePendingKey(userID uint) string {
+	return fmt.Sprintf("email_change:pending:%d", userID)
+}
+
+func newEmailChangeToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func emailChangeLink(path string, token string) string {
+	return config.GetAppURL() + path + "?token=" + url.QueryEscape(token)
+}
+
+// Request mengirim token konfirmasi ke alamat baru dan pemberitahuan ke alamat lama.
+// Email user belum berubah sampai token dikonfirmasi; request baru membatalkan request sebelumnya
+func (u *EmailChangeUseCase) Request(user *domain.User, newEmail string) error {
+	newEmail = strings.TrimSpace(newEmail)
+	if identity.Email(newEmail) == identity.Email(user.Email) {
+		return ErrEmailUnchanged
+	}
+	if err := u.ensureAvailable(user, newEmail); err != nil {
+		return err
+	}
+
+	token, err := newEmailChangeToken()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(emailChange{UserID: user.ID, OldEmail: user.Email, NewEmail: newEmail})
+	if err != nil {
+		return err
+	}
+
+	if previous, err := u.store.Get(emailChangePendingKey(user.ID)); err == nil && len(previous) > 0 {
+		_ = u.store.Delete(string(previous))
+	}
+	key := emailChangeKey("confirm", token)
+	if err := u.store.Set(key, data, emailChangeTTL); err != nil {
+		return err
+	}
+	if err := u.store.Set(emailChangePendingKey(user.ID), []byte(key), emailChangeTTL); err != nil {
+		return err
+	}
+
+	if err := publishNotification(u.publisher, Notification{
+		Type:    NotificationEmailChangeConfirm,
+		Channel: "email",
+		To:      newEmail,
+		Data:    map[string]string{"confirm_url": emailChangeLink("/email-change/confirm", token), "username": user.Username},
+	}); err != nil {
+		return err
+	}
+	return publishNotification(u.publisher, Notification{
+		Type:    NotificationEmailChangeRequested,
+		Channel: "email",
+		To:      user.Email,
+		Data:    map[string]string{"new_email": newEmail, "username": user.Username},
+	})
+}
+
+// Confirm menerapkan perubahan email lalu mengirim link revert ke alamat lama
+func (u *EmailChangeUseCase) Confirm(token string) (*domain.User, error) {
+	change, err := u.take(emailChangeKey("confirm", token))
+	if err != nil {
+		return nil, err
+	}
+	_ = u.store.Delete(emailChangePendingKey(change.UserID))
+
+	user, err := u.userRepo.FindByID(change.UserID)
+	if err != nil || user.Email != change.OldEmail {
+		// Email sudah berubah lewat jalur lain sejak request dibuat
+		return nil, ErrEmailChangeToken
+	}
+	if err := u.ensureAvailable(user, change.NewEmail); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	user.Email = change.NewEmail
+	user.EmailVerifiedAt = &now
+	if err := u.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+
+	revertToken, err := newEmailChangeToken()
+	if err != nil {
+		return nil, err
+	}
+	data, _ := json.Marshal(change)
+	if err := u.store.Set(emailChangeKey("revert", revertToken), data, emailRevertTTL); err != nil {
+		return nil, err
+	}
+
+	err = publishNotification(u.publisher, Notification{
+		Type:    NotificationEmailChanged,
+		Channel: "email",
+		To:      change.OldEmail,
+		Data: map[string]string{
+			"new_email":  change.NewEmail,
+			"revert_url": emailChangeLink("/email-change/revert", revertToken),
+			"expires_at": now.Add(emailRevertTTL).Format(time.RFC3339),
+			"username":   user.Username,
+		},
+	})
+	return user, err
+}
+
+// Revert mengembalikan email lama jika akun diambil alih dan mencabut semua sesi (JWT) yang sudah diterbitkan
+// lewat SessionVersion, termasuk milik pengambil alih; user harus login ulang
+func (u *EmailChangeUseCase) Revert(token string) (*domain.User, error) {
+	change, err := u.take(emailChangeKey("revert", token))
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := u.userRepo.FindByID(change.UserID)
+	if err != nil || user.Email != change.NewEmail {
+		return nil, ErrEmailChangeToken
+	}
+	if err := u.ensureAvailable(user, change.OldEmail); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	user.Email = change.OldEmail
+	user.EmailVerifiedAt = &now
+	user.RefreshToken = ""
+	user.SessionVersion++
+	if err := u.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+
+	err = publishNotification(u.publisher, Notification{
+		Type:    NotificationEmailChangeReverted,
+		Channel: "email",
+		To:      change.OldEmail,
+		Data:    map[string]string{"username": user.Username},
+	})
+	return user, err
+}
+
+// take membaca lalu menghapus token agar tidak bisa dipakai ulang
+func (u *EmailChangeUseCase) take(key string) (*emailChange, error) {
+	data, err := u.store.Get(key)
+	if err != nil || len(data) == 0 {
+		return nil, ErrEmailChangeToken
+	}
+	_ = u.store.Delete(key)
+
+	var change emailChange
+	if err := json.Unmarshal(data, &change); err != nil {
+		return nil, ErrEmailChangeToken
+	}
+	return &change, nil
+}
+
+func (u *EmailChangeUseCase) ensureAvailable(user *domain.User, email string) error {
+	candidate := *user
+	candidate.Email = email
+
+	field, err := u.userRepo.FindConflict(&candidate)
+	if err != nil {
+		return err
+	}
+	if field == "email" {
+		return domain.FieldConflict(field)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package usecase
+
+import (
+	"encoding/json"
+	"net/url"
+	"testing"
+)
+
+// recordingPublisher menyimpan notifikasi yang dikirim agar test bisa membaca token dari link-nya
+type recordingPublisher struct {
+	notifications []Notification
+}
+
+func (p *recordingPublisher) Publish(body []byte) error {
+	var notification Notification
+	if err := json.Unmarshal(body, &notification); err != nil {
+		return err
+	}
+	p.notifications = append(p.notifications, notification)
+	return nil
+}
+
+func (p *recordingPublisher) token(t *testing.T, notificationType string, field string) string {
+	t.Helper()
+
+	for _, notification := range p.notifications {
+		if notification.Type != notificationType {
+			continue
+		}
+		link, err := url.Parse(notification.Data[field])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return link.Query().Get("token")
+	}
+	t.Fatalf("no %s notification sent", notificationType)
+	return ""
+}
+
+func TestEmailChangeRevertRevokesSessions(t *testing.T) {
+	userRepo := newUserRepo(t)
+	publisher := &recordingPublisher{}
+	emailChange := NewEmailChangeUseCase(userRepo, newMemoryStore(), publisher)
+	user := createUser(t, userRepo, "budi")
+
+	if err := emailChange.Request(user, "attacker@example.com"); err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	changed, err := emailChange.Confirm(publisher.token(t, NotificationEmailChangeConfirm, "confirm_url"))
+	if err != nil {
+		t.Fatalf("confirm: %v", err)
+	}
+	if changed.SessionVersion != user.SessionVersion {
+		t.Fatal("confirming a change should not revoke sessions")
+	}
+
+	reverted, err := emailChange.Revert(publisher.token(t, NotificationEmailChanged, "revert_url"))
+	if err != nil {
+		t.Fatalf("revert: %v", err)
+	}
+	if reverted.Email != "budi@example.com" {
+		t.Fatalf("email = %q, want the original address", reverted.Email)
+	}
+
+	stored, err := userRepo.FindByID(user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.SessionVersion != user.SessionVersion+1 {
+		t.Fatalf("session version = %d, want %d", stored.SessionVersion, user.SessionVersion+1)
+	}
+}
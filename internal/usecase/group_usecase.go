@@ -0,0 +1,211 @@
+package usecase
+
+import (
+	"codebase-api/internal/domain"
+	"codebase-api/internal/repository"
+	"errors"
+	"slices"
+)
+
+var (
+	ErrGroupNotFound       = domain.NotFound("group not found")
+	ErrGroupMemberNotFound = domain.NotFound("group member not found")
+	ErrGroupForbidden      = domain.Forbidden("not allowed to access this group")
+	ErrLastGroupOwner      = domain.Conflict("", "group must keep at least one owner")
+	ErrInvalidGroupRole    = domain.Invalid("role", "role must be owner or member")
+)
+
+// GroupUpdate berisi field group yang boleh diubah; nil berarti tidak diubah
+type GroupUpdate struct {
+	Name        *string
+	Description *string
+	// Version adalah version yang dilihat client (If-Match); 0 berarti tidak dicek
+	Version uint
+}
+
+type GroupUseCase struct {
+	groupRepo  *repository.GroupRepository
+	memberRepo *repository.GroupMemberRepository
+	userRepo   *repository.UserRepository
+}
+
+func NewGroupUseCase(groupRepo *repository.GroupRepository, memberRepo *repository.GroupMemberRepository, userRepo *repository.UserRepository) *GroupUseCase {
+	return &GroupUseCase{groupRepo: groupRepo, memberRepo: memberRepo, userRepo: userRepo}
+}
+
+// Role mengembalikan peran user di group, atau "" jika bukan anggota
+func (u *GroupUseCase) Role(groupID uint, userID uint) string {
+	member, err := u.memberRepo.Find(groupID, userID)
+	if err != nil {
+		return ""
+	}
+	return member.Role
+}
+
+// Authorize mengizinkan admin, atau anggota dengan salah satu roles (tanpa roles: anggota apa pun)
+func (u *GroupUseCase) Authorize(user *domain.User, groupID uint, roles ...string) error {
+	if user.Role == domain.RoleAdmin {
+		return nil
+	}
+
+	role := u.Role(groupID, user.ID)
+	if role == "" || (len(roles) > 0 && !slices.Contains(roles, role)) {
+		return ErrGroupForbidden
+	}
+	return nil
+}
+
+// Find mencari group berdasarkan ID publik (UUID)
+func (u *GroupUseCase) Find(publicID string) (*domain.Group, error) {
+	id, err := resolvePublicID(u.groupRepo, publicID)
+	if err != nil {
+		return nil, notFoundAs(err, ErrGroupNotFound)
+	}
+
+	group, err := u.groupRepo.FindByID(id)
+	if err != nil {
+		return nil, notFoundAs(err, ErrGroupNotFound)
+	}
+	return group, nil
+}
+
+// FindVisible mencari group yang boleh dilihat user (admin atau anggota). Bukan anggota
+// diperlakukan seperti group tidak ada agar keberadaan group tidak bocor
+func (u *GroupUseCase) FindVisible(user *domain.User, publicID string) (*domain.Group, error) {
+	group, err := u.Find(publicID)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.Authorize(user, group.ID); err != nil {
+		return nil, ErrGroupNotFound
+	}
+	return group, nil
+}
+
+// List mengembalikan semua group untuk admin, dan hanya group yang diikuti untuk user lain
+func (u *GroupUseCase) List(user *domain.User, req repository.PageRequest) (*repository.Page[domain.Group], error) {
+	if user.Role == domain.RoleAdmin {
+		return u.groupRepo.List(nil, req)
+	}
+	return u.groupRepo.List(&user.ID, req)
+}
+
+func (u *GroupUseCase) ListForUser(userID uint, req repository.PageRequest) (*repository.Page[domain.Group], error) {
+	return u.groupRepo.List(&userID, req)
+}
+
+// Create membuat group baru dengan pembuatnya sebagai owner
+func (u *GroupUseCase) Create(owner *domain.User, name string, description string) (*domain.Group, error) {
+	group := &domain.Group{Name: name, Description: description}
+	if err := u.groupRepo.CreateWithOwner(group, owner.ID); err != nil {
+		return nil, err
+	}
+	return group, nil
+}
+
+func (u *GroupUseCase) Update(group *domain.Group, input GroupUpdate) (*domain.Group, error) {
+	if err := checkVersion(group.Version, input.Version); err != nil {
+		return nil, err
+	}
+	if input.Name != nil {
+		group.Name = *input.Name
+	}
+	if input.Description != nil {
+		group.Description = *input.Description
+	}
+
+	if err := u.groupRepo.Update(group); err != nil {
+		return nil, staleAs(err, input.Version)
+	}
+	return group, nil
+}
+
+func (u *GroupUseCase) Delete(group *domain.Group) error {
+	return u.groupRepo.Delete(group.ID)
+}
+
+func (u *GroupUseCase) Members(group *domain.Group, req repository.PageRequest) (*repository.Page[domain.GroupMember], error) {
+	return u.memberRepo.ListByGroup(group.ID, req)
+}
+
+// ResolveMember menerjemahkan ID publik user menjadi primary key
+func (u *GroupUseCase) ResolveMember(userPublicID string) (uint, error) {
+	id, err := resolvePublicID(u.userRepo, userPublicID)
+	if err != nil {
+		return 0, notFoundAs(err, ErrUserNotFound)
+	}
+	return id, nil
+}
+
+// SetMember menambahkan user ke group atau mengubah perannya
+// SetMember menambahkan anggota atau mengubah perannya. version adalah version keanggotaan dari If-Match;
+// 0 ("*") berarti tambahkan atau ubah tanpa dicek
+func (u *GroupUseCase) SetMember(group *domain.Group, userID uint, role string, version uint) (*domain.GroupMember, error) {
+	if role != domain.GroupRoleOwner && role != domain.GroupRoleMember {
+		return nil, ErrInvalidGroupRole
+	}
+
+	user, err := u.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, notFoundAs(err, ErrUserNotFound)
+	}
+
+	member, err := u.memberRepo.Find(group.ID, userID)
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return nil, err
+	}
+	if err != nil {
+		// Version tertentu berarti client mengira keanggotaan ini sudah ada
+		if version != 0 {
+			return nil, domain.ErrVersionMismatch
+		}
+		member = &domain.GroupMember{GroupID: group.ID, UserID: userID, Role: role}
+		if err := u.memberRepo.Create(member); err != nil {
+			return nil, err
+		}
+		member.User = user
+		return member, nil
+	}
+
+	if err := checkVersion(member.Version, version); err != nil {
+		return nil, err
+	}
+	if member.Role == domain.GroupRoleOwner && role != domain.GroupRoleOwner {
+		if err := u.ensureAnotherOwner(group.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	member.Role = role
+	if err := u.memberRepo.Update(member); err != nil {
+		return nil, staleAs(err, version)
+	}
+	member.User = user
+	return member, nil
+}
+
+func (u *GroupUseCase) RemoveMember(group *domain.Group, userID uint) error {
+	member, err := u.memberRepo.Find(group.ID, userID)
+	if err != nil {
+		return notFoundAs(err, ErrGroupMemberNotFound)
+	}
+
+	if member.Role == domain.GroupRoleOwner {
+		if err := u.ensureAnotherOwner(group.ID); err != nil {
+			return err
+		}
+	}
+	return u.memberRepo.Remove(group.ID, userID)
+}
+
+// ensureAnotherOwner mencegah group kehilangan owner terakhir
+func (u *GroupUseCase) ensureAnotherOwner(groupID uint) error {
+	owners, err := u.memberRepo.CountByRole(groupID, domain.GroupRoleOwner)
+	if err != nil {
+		return err
+	}
+	if owners <= 1 {
+		return ErrLastGroupOwner
+	}
+	return nil
+}
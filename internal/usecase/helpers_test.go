@@ -0,0 +1,172 @@
+package usecase
+
+import (
+	"codebase-api/internal/domain"
+	"codebase-api/internal/repository"
+	"codebase-api/internal/testdb"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memoryStore adalah CounterStore in-memory pengganti Redis untuk test
+type memoryStore struct {
+	mu    sync.Mutex
+	items map[string]memoryItem
+}
+
+type memoryItem struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{items: map[string]memoryItem{}}
+}
+
+// live mengembalikan item yang belum kedaluwarsa; pemanggil memegang s.mu
+func (s *memoryStore) live(key string) (memoryItem, bool) {
+	item, ok := s.items[key]
+	if !ok || (!item.expiresAt.IsZero() && time.Now().After(item.expiresAt)) {
+		return memoryItem{}, false
+	}
+	return item, true
+}
+
+func (s *memoryStore) Get(key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	item, _ := s.live(key)
+	return item.value, nil
+}
+
+func (s *memoryStore) Set(key string, val []byte, exp time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	item := memoryItem{value: append([]byte(nil), val...)}
+	if exp > 0 {
+		item.expiresAt = time.Now().Add(exp)
+	}
+	s.items[key] = item
+	return nil
+}
+
+func (s *memoryStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.items, key)
+	return nil
+}
+
+func (s *memoryStore) Incr(key string, delta int64, exp time.Duration) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	item, ok := s.live(key)
+	if !ok && exp > 0 {
+		item.expiresAt = time.Now().Add(exp)
+	}
+	value, _ := strconv.ParseInt(string(item.value), 10, 64)
+	value += delta
+	item.value = []byte(strconv.FormatInt(value, 10))
+	s.items[key] = item
+	return value, nil
+}
+
+func (s *memoryStore) SetNX(key string, val []byte, exp time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.live(key); ok {
+		return false, nil
+	}
+	item := memoryItem{value: append([]byte(nil), val...)}
+	if exp > 0 {
+		item.expiresAt = time.Now().Add(exp)
+	}
+	s.items[key] = item
+	return true, nil
+}
+
+func (s *memoryStore) TTL(key string) (time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	item, ok := s.live(key)
+	if !ok || item.expiresAt.IsZero() {
+		return 0, nil
+	}
+	return time.Until(item.expiresAt), nil
+}
+
+// slowStore menambah jeda pada Get seperti round-trip ke Redis, sehingga counter yang dihitung
+// dengan baca-ubah-tulis (bukan Incr) akan kebobolan oleh tebakan paralel
+type slowStore struct {
+	*memoryStore
+}
+
+func (s slowStore) Get(key string) ([]byte, error) {
+	data, err := s.memoryStore.Get(key)
+	time.Sleep(time.Millisecond)
+	return data, err
+}
+
+// wrongCode mengembalikan kode 6 digit yang pasti berbeda dari kode yang benar
+func wrongCode(code string) string {
+	if code == "000000" {
+		return "111111"
+	}
+	return "000000"
+}
+
+// expire memajukan waktu kedaluwarsa key seolah-olah TTL-nya sudah lewat
+func (s *memoryStore) expire(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.items, key)
+}
+
+func createUser(t *testing.T, repo *repository.UserRepository, username string) *domain.User {
+	t.Helper()
+
+	user := &domain.User{
+		FirstName: "Test",
+		LastName:  "User",
+		Username:  username,
+		Email:     username + "@example.com",
+		Password:  "not-a-hash",
+		IsActive:  true,
+		Role:      domain.RoleUser,
+	}
+	if err := repo.Create(user); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	return user
+}
+
+func newUserRepo(t *testing.T) *repository.UserRepository {
+	t.Helper()
+	return repository.NewUserRepository(testdb.Open(t))
+}
+
+// memoryFiles adalah FileStore in-memory untuk test avatar dan erasure
+type memoryFiles struct {
+	mu    sync.Mutex
+	files map[string][]byte
+}
+
+func newMemoryFiles() *memoryFiles {
+	return &memoryFiles{files: map[string][]byte{}}
+}
+
+func (f *memoryFiles) Put(key string, data []byte) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.files[key] = append([]byte(nil), data...)
+	return nil
+}
+
+func (f *memoryFiles) Delete(key string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.files, key)
+	return nil
+}
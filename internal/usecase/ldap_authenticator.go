@@ -0,0 +1,191 @@
+package usecase
+
+import (
+	"codebase-api/config"
+	"codebase-api/internal/domain"
+	"codebase-api/internal/repository"
+	"codebase-api/pkg/identity"
+	"crypto/tls"
+	"errors"
+	"strings"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/sirupsen/logrus"
+)
+
+// ErrLDAPEntryIncomplete dikembalikan bila entry directory tidak punya attribute wajib (email)
+var ErrLDAPEntryIncomplete = domain.Forbidden("directory account is missing required attributes")
+
+// LDAPConn adalah bagian dari *ldap.Conn yang dipakai authenticator,
+// sehingga server LDAP bisa diganti stand-in saat pengujian
+type LDAPConn interface {
+	Bind(username, password string) error
+	Search(request *ldap.SearchRequest) (*ldap.SearchResult, error)
+	Close() error
+}
+
+// LDAPAuthenticator memverifikasi user dengan pola search-then-bind lalu
+// membuat / memperbarui domain.User lokal dari attribute LDAP
+type LDAPAuthenticator struct {
+	cfg      config.LDAPConfig
+	userRepo *repository.UserRepository
+	dial     func() (LDAPConn, error)
+}
+
+func NewLDAPAuthenticator(cfg config.LDAPConfig, userRepo *repository.UserRepository) *LDAPAuthenticator {
+	a := &LDAPAuthenticator{cfg: cfg, userRepo: userRepo}
+	a.dial = a.dialServer
+	return a
+}
+
+// WithDialer mengganti cara membuka koneksi LDAP (mis. ke server in-process)
+func (a *LDAPAuthenticator) WithDialer(dial func() (LDAPConn, error)) *LDAPAuthenticator {
+	a.dial = dial
+	return a
+}
+
+func (a *LDAPAuthenticator) dialServer() (LDAPConn, error) {
+	conn, err := ldap.DialURL(a.cfg.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	if a.cfg.StartTLS {
+		host := strings.TrimPrefix(strings.TrimPrefix(a.cfg.URL, "ldap://"), "ldaps://")
+		host, _, _ = strings.Cut(host, ":")
+		if err := conn.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return conn, nil
+}
+
+func (a *LDAPAuthenticator) Name() string {
+	return domain.AuthSourceLDAP
+}
+
+func (a *LDAPAuthenticator) Authenticate(username, password string) (*domain.User, error) {
+	// Bind dengan password kosong adalah unauthenticated bind dan selalu "berhasil"
+	if password == "" {
+		return nil, ErrInvalidCredentials
+	}
+
+	conn, err := a.dial()
+	if err != nil {
+		logrus.WithError(err).Warn("ldap: connect failed")
+		return nil, ErrAuthenticatorSkip
+	}
+	defer conn.Close()
+
+	// 1. Cari entry user dengan service account
+	if a.cfg.BindDN != "" {
+		if err := conn.Bind(a.cfg.BindDN, a.cfg.BindPassword); err != nil {
+			logrus.WithError(err).Warn("ldap: service bind failed")
+			return nil, ErrAuthenticatorSkip
+		}
+	}
+
+	attributes := []string{
+		a.cfg.UsernameAttribute, a.cfg.EmailAttribute, a.cfg.FirstNameAttribute,
+		a.cfg.LastNameAttribute, a.cfg.PhoneAttribute, a.cfg.GroupAttribute,
+	}
+	result, err := conn.Search(ldap.NewSearchRequest(
+		a.cfg.BaseDN,
+		ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 2, 0, false,
+		strings.ReplaceAll(a.cfg.UserFilter, "%s", ldap.EscapeFilter(username)),
+		attributes,
+		nil,
+	))
+	if err != nil {
+		logrus.WithError(err).Warn("ldap: search failed")
+		return nil, ErrAuthenticatorSkip
+	}
+	if len(result.Entries) != 1 {
+		return nil, ErrAuthenticatorSkip
+	}
+	entry := result.Entries[0]
+
+	// 2. Bind sebagai user untuk memverifikasi password
+	if err := conn.Bind(entry.DN, password); err != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	return a.provision(username, entry)
+}
+
+// provision membuat atau memperbarui domain.User lokal dari entry LDAP
+func (a *LDAPAuthenticator) provision(username string, entry *ldap.Entry) (*domain.User, error) {
+	if value := entry.GetAttributeValue(a.cfg.UsernameAttribute); value != "" {
+		username = value
+	}
+
+	email := entry.GetAttributeValue(a.cfg.EmailAttribute)
+	if email == "" {
+		logrus.WithField("dn", entry.DN).Warnf("ldap: entry has no %s attribute", a.cfg.EmailAttribute)
+		return nil, ErrLDAPEntryIncomplete
+	}
+
+	user, err := a.userRepo.GetUserByUsername(username)
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return nil, err
+	}
+	isNew := err != nil
+	if isNew {
+		// Aturan yang sama dengan Register: directory tidak boleh membuat akun "admin" atau "erased-..."
+		if identity.IsReservedUsername(username) {
+			logrus.WithField("dn", entry.DN).Warn("ldap: username is reserved")
+			return nil, ErrReservedUsername
+		}
+		password, err := randomPassword()
+		if err != nil {
+			return nil, err
+		}
+		user = &domain.User{
+			Username:   username,
+			Password:   password,
+			IsActive:   true,
+			Role:       domain.RoleUser,
+			AuthSource: domain.AuthSourceLDAP,
+		}
+	} else if user.AuthSource != domain.AuthSourceLDAP {
+		return nil, domain.Conflict("username", "a local account with this username already exists")
+	}
+
+	user.Email = email
+	user.FirstName = entry.GetAttributeValue(a.cfg.FirstNameAttribute)
+	user.LastName = entry.GetAttributeValue(a.cfg.LastNameAttribute)
+	user.Phone = nil
+	if phone := entry.GetAttributeValue(a.cfg.PhoneAttribute); phone != "" {
+		user.Phone = &phone
+	}
+
+	if len(a.cfg.GroupRoles) > 0 {
+		user.Role = domain.RoleUser
+		for _, group := range entry.GetAttributeValues(a.cfg.GroupAttribute) {
+			if role, ok := a.cfg.GroupRoles[strings.ToLower(group)]; ok {
+				user.Role = role
+				// Admin adalah role tertinggi, tidak perlu mencari lebih lanjut
+				if role == domain.RoleAdmin {
+					break
+				}
+			}
+		}
+	}
+
+	if field, err := a.userRepo.FindConflict(user); err != nil {
+		return nil, err
+	} else if field != "" {
+		return nil, domain.Conflict(field, "ldap user conflicts with an existing account on "+field)
+	}
+
+	if isNew {
+		err = a.userRepo.Create(user)
+	} else {
+		err = a.userRepo.Update(user)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
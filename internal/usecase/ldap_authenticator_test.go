@@ -0,0 +1,230 @@
+package usecase
+
+import (
+	"codebase-api/config"
+	"codebase-api/internal/domain"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/go-ldap/ldap/v3"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	testServiceDN       = "cn=service,dc=example,dc=com"
+	testServicePassword = "service-secret"
+)
+
+type fakeEntry struct {
+	password   string
+	attributes map[string][]string
+}
+
+// fakeDirectory adalah stand-in server LDAP in-process yang memenuhi LDAPConn
+type fakeDirectory struct {
+	entries map[string]fakeEntry
+	filters []string
+	dials   int
+}
+
+func newFakeDirectory() *fakeDirectory {
+	return &fakeDirectory{entries: map[string]fakeEntry{
+		"uid=budi,ou=people,dc=example,dc=com": {
+			password: "directory-pass",
+			attributes: map[string][]string{
+				"uid":             {"budi"},
+				"mail":            {"budi@corp.example.com"},
+				"givenName":       {"Budi"},
+				"sn":              {"Santoso"},
+				"telephoneNumber": {"+628111111111"},
+				"memberOf":        {"CN=Admins,OU=Groups,DC=example,DC=com"},
+			},
+		},
+	}}
+}
+
+func (d *fakeDirectory) dial() (LDAPConn, error) {
+	d.dials++
+	return d, nil
+}
+
+func (d *fakeDirectory) Bind(username, password string) error {
+	if username == testServiceDN && password == testServicePassword {
+		return nil
+	}
+	if entry, ok := d.entries[username]; ok && password != "" && entry.password == password {
+		return nil
+	}
+	return ldap.NewError(ldap.LDAPResultInvalidCredentials, errors.New("invalid credentials"))
+}
+
+// Search hanya mengenal filter bawaan (&(objectClass=person)(uid=...)) dan mencatat setiap filter yang diterima
+func (d *fakeDirectory) Search(request *ldap.SearchRequest) (*ldap.SearchResult, error) {
+	d.filters = append(d.filters, request.Filter)
+
+	result := &ldap.SearchResult{}
+	for dn, entry := range d.entries {
+		if request.Filter != "(&(objectClass=person)(uid="+ldap.EscapeFilter(entry.attributes["uid"][0])+"))" {
+			continue
+		}
+		var attributes []*ldap.EntryAttribute
+		for _, name := range request.Attributes {
+			if values, ok := entry.attributes[name]; ok {
+				attributes = append(attributes, ldap.NewEntryAttribute(name, values))
+			}
+		}
+		result.Entries = append(result.Entries, ldap.NewEntry(dn, nil))
+		result.Entries[len(result.Entries)-1].Attributes = attributes
+	}
+	return result, nil
+}
+
+func (d *fakeDirectory) Close() error {
+	return nil
+}
+
+func testLDAPConfig() config.LDAPConfig {
+	return config.LDAPConfig{
+		URL:                "ldap://directory.test",
+		BindDN:             testServiceDN,
+		BindPassword:       testServicePassword,
+		BaseDN:             "dc=example,dc=com",
+		UserFilter:         "(&(objectClass=person)(uid=%s))",
+		UsernameAttribute:  "uid",
+		EmailAttribute:     "mail",
+		FirstNameAttribute: "givenName",
+		LastNameAttribute:  "sn",
+		PhoneAttribute:     "telephoneNumber",
+		GroupAttribute:     "memberOf",
+		GroupRoles:         map[string]string{"cn=admins,ou=groups,dc=example,dc=com": domain.RoleAdmin},
+	}
+}
+
+func newLDAPFixture(t *testing.T) (*LDAPAuthenticator, *fakeDirectory, *UserUseCase) {
+	t.Helper()
+
+	userRepo := newUserRepo(t)
+	directory := newFakeDirectory()
+	authenticator := NewLDAPAuthenticator(testLDAPConfig(), userRepo).WithDialer(directory.dial)
+	users := NewUserUseCase(userRepo, NewLocalAuthenticator(userRepo), authenticator)
+	return authenticator, directory, users
+}
+
+func TestLDAPAuthenticatorProvisionsUserOnBind(t *testing.T) {
+	authenticator, _, _ := newLDAPFixture(t)
+
+	user, err := authenticator.Authenticate("budi", "directory-pass")
+	if err != nil {
+		t.Fatalf("authenticate: %v", err)
+	}
+	if user.ID == 0 || user.AuthSource != domain.AuthSourceLDAP {
+		t.Fatalf("user was not provisioned as an LDAP user: %+v", user)
+	}
+	if user.Email != "budi@corp.example.com" || user.FirstName != "Budi" || user.LastName != "Santoso" {
+		t.Fatalf("attributes not mapped: %+v", user)
+	}
+	if user.Role != domain.RoleAdmin {
+		t.Fatalf("role = %q, want admin from group mapping", user.Role)
+	}
+
+	// Login berikutnya memperbarui user yang sama, bukan membuat baru
+	again, err := authenticator.Authenticate("budi", "directory-pass")
+	if err != nil || again.ID != user.ID {
+		t.Fatalf("second login = %+v, %v", again, err)
+	}
+}
+
+func TestLDAPAuthenticatorRejectsBadPassword(t *testing.T) {
+	authenticator, _, _ := newLDAPFixture(t)
+
+	if _, err := authenticator.Authenticate("budi", "wrong"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("err = %v, want ErrInvalidCredentials", err)
+	}
+}
+
+func TestLDAPAuthenticatorSkipsUnknownUser(t *testing.T) {
+	authenticator, _, _ := newLDAPFixture(t)
+
+	if _, err := authenticator.Authenticate("nobody", "directory-pass"); !errors.Is(err, ErrAuthenticatorSkip) {
+		t.Fatalf("err = %v, want ErrAuthenticatorSkip", err)
+	}
+}
+
+func TestLDAPAuthenticatorEscapesFilter(t *testing.T) {
+	authenticator, directory, _ := newLDAPFixture(t)
+
+	_, err := authenticator.Authenticate("*)(uid=budi", "directory-pass")
+	if !errors.Is(err, ErrAuthenticatorSkip) {
+		t.Fatalf("err = %v, want ErrAuthenticatorSkip", err)
+	}
+	if len(directory.filters) != 1 {
+		t.Fatalf("filters = %v", directory.filters)
+	}
+	if filter := directory.filters[0]; filter != `(&(objectClass=person)(uid=\2a\29\28uid=budi))` || strings.Contains(filter, "*") {
+		t.Fatalf("filter was not escaped: %s", filter)
+	}
+}
+
+func TestLDAPAuthenticatorRejectsEmptyPassword(t *testing.T) {
+	authenticator, directory, _ := newLDAPFixture(t)
+
+	if _, err := authenticator.Authenticate("budi", ""); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("err = %v, want ErrInvalidCredentials", err)
+	}
+	if directory.dials != 0 {
+		t.Fatal("empty password reached the directory (unauthenticated bind)")
+	}
+}
+
+func TestVerifyPasswordUsesDirectoryForLDAPUser(t *testing.T) {
+	_, _, users := newLDAPFixture(t)
+
+	user, err := users.Login("budi", "directory-pass")
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+
+	if err := users.VerifyPassword(user, "directory-pass"); err != nil {
+		t.Fatalf("reauthenticate with directory password: %v", err)
+	}
+	if err := users.VerifyPassword(user, "wrong"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("reauthenticate with wrong password: err = %v", err)
+	}
+}
+
+func TestVerifyPasswordForLocalUser(t *testing.T) {
+	_, _, users := newLDAPFixture(t)
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("local-pass"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := createUser(t, users.userRepo, "siti")
+	user.Password = string(hash)
+	if err := users.userRepo.Update(user); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := users.VerifyPassword(user, "local-pass"); err != nil {
+		t.Fatalf("reauthenticate local user: %v", err)
+	}
+	if err := users.VerifyPassword(user, "directory-pass"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("wrong password accepted: %v", err)
+	}
+}
+
+func TestLDAPAuthenticatorRefusesReservedUsername(t *testing.T) {
+	authenticator, directory, _ := newLDAPFixture(t)
+	directory.entries["uid=admin,ou=people,dc=example,dc=com"] = fakeEntry{
+		password:   "directory-pass",
+		attributes: map[string][]string{"uid": {"admin"}, "mail": {"admin@corp.example.com"}},
+	}
+
+	if _, err := authenticator.Authenticate("admin", "directory-pass"); !errors.Is(err, ErrReservedUsername) {
+		t.Fatalf("err = %v, want ErrReservedUsername", err)
+	}
+	if _, err := authenticator.userRepo.GetUserByUsername("admin"); err == nil {
+		t.Fatal("reserved user was provisioned")
+	}
+}
@@ -0,0 +1,31 @@
+package usecase
+
+import "encoding/json"
+
+// MessagePublisher mengirim pesan ke worker notifikasi (mis. RabbitMQ)
+type MessagePublisher interface {
+	Publish(body []byte) error
+}
+
+const (
+	NotificationEmailChangeConfirm   = "email_change_confirm"
+	NotificationEmailChangeRequested = "email_change_requested"
+	NotificationEmailChanged         = "email_changed"
+	NotificationEmailChangeReverted  = "email_change_reverted"
+)
+
+// Notification adalah payload yang dikonsumsi worker; Type menentukan template yang dipakai
+type Notification struct {
+	Type    string            `json:"type"`
+	Channel string            `json:"channel"`
+	To      string            `json:"to"`
+	Data    map[string]string `json:"data,omitempty"`
+}
+
+func publishNotification(publisher MessagePublisher, notification Notification) error {
+	body, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+	return publisher.Publish(body)
+}
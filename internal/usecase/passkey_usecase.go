@@ -0,0 +1,313 @@
+package usecase
+
+import (
+	"bytes"
+	"codebase-api/internal/domain"
+	"codebase-api/internal/repository"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+const passkeySessionTTL = 5 * time.Minute
+
+var (
+	ErrPasskeyNotFound     = domain.NotFound("passkey not found")
+	ErrPasskeySession      = domain.Invalid("passkey", "passkey session not found or expired")
+	ErrPasskeyResponse     = domain.Invalid("passkey", "passkey response is invalid")
+	ErrPasskeyRegistration = domain.Invalid("passkey", "passkey registration failed")
+	// Semua kegagalan login passkey memakai satu error agar response tidak membedakan penyebabnya
+	ErrPasskeyLogin = domain.Unauthorized("passkey login failed")
+)
+
+// webAuthnUser menghubungkan domain.User dengan interface webauthn.User
+type webAuthnUser struct {
+	user     *domain.User
+	passkeys []domain.Passkey
+}
+
+func (w *webAuthnUser) WebAuthnID() []byte {
+	id := w.user.UUID
+	return id[:]
+}
+
+func (w *webAuthnUser) WebAuthnName() string {
+	return w.user.Username
+}
+
+func (w *webAuthnUser) WebAuthnDisplayName() string {
+	return strings.TrimSpace(w.user.FirstName + " " + w.user.LastName)
+}
+
+func (w *webAuthnUser) WebAuthnCredentials() []webauthn.Credential {
+	credentials := make([]webauthn.Credential, 0, len(w.passkeys))
+	for _, p := range w.passkeys {
+		credentials = append(credentials, toWebAuthnCredential(p))
+	}
+	return credentials
+}
+
+func toWebAuthnCredential(p domain.Passkey) webauthn.Credential {
+	var transports []protocol.AuthenticatorTransport
+	for _, t := range strings.Split(p.Transports, ",") {
+		if t != "" {
+			transports = append(transports, protocol.AuthenticatorTransport(t))
+		}
+	}
+
+	return webauthn.Credential{
+		ID:              p.CredentialID,
+		PublicKey:       p.PublicKey,
+		AttestationType: p.AttestationType,
+		Transport:       transports,
+		Flags: webauthn.CredentialFlags{
+			BackupEligible: p.BackupEligible,
+			BackupState:    p.BackupState,
+		},
+		Authenticator: webauthn.Authenticator{
+			AAGUID:    p.AAGUID,
+			SignCount: p.SignCount,
+		},
+	}
+}
+
+type PasskeyUseCase struct {
+	passkeyRepo *repository.PasskeyRepository
+	userRepo    *repository.UserRepository
+	webAuthn    *webauthn.WebAuthn
+	sessions    KeyValueStore
+}
+
+func NewPasskeyUseCase(passkeyRepo *repository.PasskeyRepository, userRepo *repository.UserRepository, webAuthn *webauthn.WebAuthn, sessions KeyValueStore) *PasskeyUseCase {
+	return &PasskeyUseCase{passkeyRepo: passkeyRepo, userRepo: userRepo, webAuthn: webAuthn, sessions: sessions}
+}
+
+func (u *PasskeyUseCase) loadWebAuthnUser(user *domain.User) (*webAuthnUser, error) {
+	passkeys, err := u.passkeyRepo.FindByUserID(user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("load passkeys: %w", err)
+	}
+	return &webAuthnUser{user: user, passkeys: passkeys}, nil
+}
+
+func (u *PasskeyUseCase) saveSession(key string, session *webauthn.SessionData) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+	return u.sessions.Set(key, data, passkeySessionTTL)
+}
+
+// takeSession membaca lalu menghapus session agar challenge tidak bisa dipakai ulang
+func (u *PasskeyUseCase) takeSession(key string) (*webauthn.SessionData, error) {
+	data, err := u.sessions.Get(key)
+	if err != nil || len(data) == 0 {
+		return nil, ErrPasskeySession
+	}
+	_ = u.sessions.Delete(key)
+
+	var session webauthn.SessionData
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, ErrPasskeySession
+	}
+	return &session, nil
+}
+
+func registrationSessionKey(userID uint) string {
+	return fmt.Sprintf("webauthn:registration:%d", userID)
+}
+
+func loginSessionKey(ceremonyID string) string {
+	return "webauthn:login:" + ceremonyID
+}
+
+func (u *PasskeyUseCase) BeginRegistration(user *domain.User) (*protocol.CredentialCreation, error) {
+	waUser, err := u.loadWebAuthnUser(user)
+	if err != nil {
+		return nil, err
+	}
+
+	// Jangan daftarkan authenticator yang sama dua kali
+	var exclusions []protocol.CredentialDescriptor
+	for _, credential := range waUser.WebAuthnCredentials() {
+		exclusions = append(exclusions, credential.Descriptor())
+	}
+
+	creation, session, err := u.webAuthn.BeginRegistration(waUser,
+		webauthn.WithExclusions(exclusions),
+		// Login selalu discoverable (lihat BeginLogin), jadi credential wajib resident key
+		webauthn.WithResidentKeyRequirement(protocol.ResidentKeyRequirementRequired),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.saveSession(registrationSessionKey(user.ID), session); err != nil {
+		return nil, fmt.Errorf("store passkey session: %w", err)
+	}
+	return creation, nil
+}
+
+func (u *PasskeyUseCase) FinishRegistration(user *domain.User, name string, body []byte) (*domain.Passkey, error) {
+	session, err := u.takeSession(registrationSessionKey(user.ID))
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, err := protocol.ParseCredentialCreationResponseBody(bytes.NewReader(body))
+	if err != nil {
+		return nil, ErrPasskeyResponse
+	}
+
+	waUser, err := u.loadWebAuthnUser(user)
+	if err != nil {
+		return nil, err
+	}
+
+	credential, err := u.webAuthn.CreateCredential(waUser, *session, parsed)
+	if err != nil {
+		return nil, ErrPasskeyRegistration
+	}
+
+	if name == "" {
+		name = fmt.Sprintf("Passkey %d", len(waUser.passkeys)+1)
+	}
+
+	transports := make([]string, 0, len(credential.Transport))
+	for _, t := range credential.Transport {
+		transports = append(transports, string(t))
+	}
+
+	passkey := &domain.Passkey{
+		UserID:          user.ID,
+		Name:            name,
+		CredentialID:    credential.ID,
+		PublicKey:       credential.PublicKey,
+		AttestationType: credential.AttestationType,
+		AAGUID:          credential.Authenticator.AAGUID,
+		SignCount:       credential.Authenticator.SignCount,
+		Transports:      strings.Join(transports, ","),
+		BackupEligible:  credential.Flags.BackupEligible,
+		BackupState:     credential.Flags.BackupState,
+	}
+	if err := u.passkeyRepo.Create(passkey); err != nil {
+		return nil, err
+	}
+	return passkey, nil
+}
+
+// BeginLogin memulai discoverable login: authenticator yang memilih akun, sehingga challenge tidak
+// bergantung pada username dan tidak bisa dipakai untuk menebak username mana yang terdaftar
+func (u *PasskeyUseCase) BeginLogin() (*protocol.CredentialAssertion, string, error) {
+	assertion, session, err := u.webAuthn.BeginDiscoverableLogin()
+	if err != nil {
+		return nil, "", err
+	}
+
+	ceremonyID := uuid.NewString()
+	if err := u.saveSession(loginSessionKey(ceremonyID), session); err != nil {
+		return nil, "", fmt.Errorf("store passkey session: %w", err)
+	}
+	return assertion, ceremonyID, nil
+}
+
+func (u *PasskeyUseCase) FinishLogin(ceremonyID string, body []byte) (*domain.User, error) {
+	session, err := u.takeSession(loginSessionKey(ceremonyID))
+	if err != nil {
+		return nil, ErrPasskeyLogin
+	}
+
+	parsed, err := protocol.ParseCredentialRequestResponseBody(bytes.NewReader(body))
+	if err != nil {
+		return nil, ErrPasskeyLogin
+	}
+
+	// Pemilik credential selalu ditentukan dari credential ID yang tersimpan
+	passkey, err := u.passkeyRepo.FindByCredentialID(parsed.RawID)
+	if err != nil {
+		return nil, notFoundAs(err, ErrPasskeyLogin)
+	}
+
+	user, err := u.userRepo.FindByID(passkey.UserID)
+	if err != nil {
+		return nil, notFoundAs(err, ErrPasskeyLogin)
+	}
+	if !user.IsActive {
+		return nil, ErrPasskeyLogin
+	}
+
+	waUser, err := u.loadWebAuthnUser(user)
+	if err != nil {
+		return nil, err
+	}
+
+	credential, err := u.webAuthn.ValidateDiscoverableLogin(func(rawID, userHandle []byte) (webauthn.User, error) {
+		if !bytes.Equal(userHandle, waUser.WebAuthnID()) {
+			return nil, ErrPasskeyLogin
+		}
+		return waUser, nil
+	}, *session, parsed)
+	if err != nil {
+		return nil, ErrPasskeyLogin
+	}
+
+	if credential.Authenticator.CloneWarning {
+		logrus.WithField("passkey", passkey.UUID).Warn("passkey sign counter mismatch, authenticator may be cloned")
+		return nil, ErrPasskeyLogin
+	}
+
+	now := time.Now()
+	passkey.SignCount = credential.Authenticator.SignCount
+	passkey.BackupState = credential.Flags.BackupState
+	passkey.LastUsedAt = &now
+	if err := u.passkeyRepo.Update(passkey); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (u *PasskeyUseCase) List(userID uint) ([]domain.Passkey, error) {
+	return u.passkeyRepo.FindByUserID(userID)
+}
+
+func (u *PasskeyUseCase) find(userID uint, publicID string) (*domain.Passkey, error) {
+	id, err := resolvePublicID(u.passkeyRepo, publicID)
+	if err != nil {
+		return nil, err
+	}
+	return u.passkeyRepo.FindByUserIDAndID(userID, id)
+}
+
+// Rename mengganti nama passkey; version adalah version dari If-Match (0 berarti tidak dicek)
+func (u *PasskeyUseCase) Rename(userID uint, publicID string, name string, version uint) (*domain.Passkey, error) {
+	passkey, err := u.find(userID, publicID)
+	if err != nil {
+		return nil, notFoundAs(err, ErrPasskeyNotFound)
+	}
+	if err := checkVersion(passkey.Version, version); err != nil {
+		return nil, err
+	}
+
+	passkey.Name = name
+	if err := u.passkeyRepo.Update(passkey); err != nil {
+		return nil, staleAs(err, version)
+	}
+	return passkey, nil
+}
+
+func (u *PasskeyUseCase) Delete(userID uint, publicID string) error {
+	passkey, err := u.find(userID, publicID)
+	if err != nil {
+		return notFoundAs(err, ErrPasskeyNotFound)
+	}
+	// Hard delete: credential yang di-soft delete tetap memegang unique index credential_id
+	// sehingga authenticator yang sama tidak bisa didaftarkan ulang
+	return u.passkeyRepo.Purge(passkey.ID)
+}
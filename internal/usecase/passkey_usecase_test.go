@@ -0,0 +1,198 @@
+package usecase
+
+import (
+	"codebase-api/internal/domain"
+	"codebase-api/internal/repository"
+	"errors"
+	"testing"
+)
+
+type passkeyFixture struct {
+	usecase  *PasskeyUseCase
+	userRepo *repository.UserRepository
+	user     *domain.User
+}
+
+func newPasskeyFixture(t *testing.T) *passkeyFixture {
+	t.Helper()
+
+	userRepo := newUserRepo(t)
+	passkeyRepo := repository.NewPasskeyRepository(userRepo.DB)
+	return &passkeyFixture{
+		usecase:  NewPasskeyUseCase(passkeyRepo, userRepo, newTestWebAuthn(t), newMemoryStore()),
+		userRepo: userRepo,
+		user:     createUser(t, userRepo, "budi"),
+	}
+}
+
+func (f *passkeyFixture) register(t *testing.T, authenticator *softAuthenticator, name string) *domain.Passkey {
+	t.Helper()
+
+	creation, err := f.usecase.BeginRegistration(f.user)
+	if err != nil {
+		t.Fatalf("begin registration: %v", err)
+	}
+	passkey, err := f.usecase.FinishRegistration(f.user, name, authenticator.Register(creation))
+	if err != nil {
+		t.Fatalf("finish registration: %v", err)
+	}
+	return passkey
+}
+
+func (f *passkeyFixture) login(t *testing.T, authenticator *softAuthenticator) (*domain.User, error) {
+	t.Helper()
+
+	assertion, ceremonyID, err := f.usecase.BeginLogin()
+	if err != nil {
+		t.Fatalf("begin login: %v", err)
+	}
+	return f.usecase.FinishLogin(ceremonyID, authenticator.Login(assertion))
+}
+
+func TestPasskeyLifecycle(t *testing.T) {
+	f := newPasskeyFixture(t)
+	authenticator := newSoftAuthenticator(t)
+
+	passkey := f.register(t, authenticator, "Laptop")
+	if passkey.Name != "Laptop" || passkey.Transports != "internal" {
+		t.Fatalf("unexpected passkey %+v", passkey)
+	}
+
+	user, err := f.login(t, authenticator)
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	if user.ID != f.user.ID {
+		t.Fatalf("logged in as user %d, want %d", user.ID, f.user.ID)
+	}
+
+	passkeys, err := f.usecase.List(f.user.ID)
+	if err != nil || len(passkeys) != 1 {
+		t.Fatalf("list = %v, %v", passkeys, err)
+	}
+	if passkeys[0].SignCount != 1 || passkeys[0].LastUsedAt == nil {
+		t.Fatalf("sign count / last used not updated: %+v", passkeys[0])
+	}
+
+	// Login memperbarui sign count, jadi version dari registrasi sudah tidak berlaku
+	if _, err := f.usecase.Rename(f.user.ID, passkey.UUID.String(), "Work laptop", passkey.Version); !errors.Is(err, domain.ErrVersionMismatch) {
+		t.Fatalf("rename with stale version: err = %v, want ErrVersionMismatch", err)
+	}
+	renamed, err := f.usecase.Rename(f.user.ID, passkey.UUID.String(), "Work laptop", passkeys[0].Version)
+	if err != nil || renamed.Name != "Work laptop" || renamed.Version != passkeys[0].Version+1 {
+		t.Fatalf("rename = %+v, %v", renamed, err)
+	}
+
+	if err := f.usecase.Delete(f.user.ID, passkey.UUID.String()); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := f.login(t, authenticator); err == nil {
+		t.Fatal("login with a deleted passkey succeeded")
+	}
+}
+
+func TestPasskeyRenameAndDeleteAreScopedToOwner(t *testing.T) {
+	f := newPasskeyFixture(t)
+	passkey := f.register(t, newSoftAuthenticator(t), "Laptop")
+	other := createUser(t, f.userRepo, "siti")
+
+	if _, err := f.usecase.Rename(other.ID, passkey.UUID.String(), "Mine", 0); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("rename by another user: err = %v, want not found", err)
+	}
+	if err := f.usecase.Delete(other.ID, passkey.UUID.String()); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("delete by another user: err = %v, want not found", err)
+	}
+}
+
+func TestPasskeyLoginCeremonyCannotBeReplayed(t *testing.T) {
+	f := newPasskeyFixture(t)
+	authenticator := newSoftAuthenticator(t)
+	f.register(t, authenticator, "")
+
+	assertion, ceremonyID, err := f.usecase.BeginLogin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	response := authenticator.Login(assertion)
+
+	if _, err := f.usecase.FinishLogin(ceremonyID, response); err != nil {
+		t.Fatalf("first login: %v", err)
+	}
+	if _, err := f.usecase.FinishLogin(ceremonyID, response); !errors.Is(err, ErrPasskeyLogin) {
+		t.Fatalf("replayed login response: err = %v, want ErrPasskeyLogin", err)
+	}
+
+	// Response yang sama juga tidak berlaku untuk ceremony baru karena challenge-nya berbeda
+	_, nextCeremony, err := f.usecase.BeginLogin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.usecase.FinishLogin(nextCeremony, response); !errors.Is(err, ErrPasskeyLogin) {
+		t.Fatalf("login response for a different challenge: err = %v, want ErrPasskeyLogin", err)
+	}
+}
+
+func TestPasskeyRegistrationCannotBeReplayed(t *testing.T) {
+	f := newPasskeyFixture(t)
+	authenticator := newSoftAuthenticator(t)
+
+	creation, err := f.usecase.BeginRegistration(f.user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response := authenticator.Register(creation)
+
+	if _, err := f.usecase.FinishRegistration(f.user, "", response); err != nil {
+		t.Fatalf("first registration: %v", err)
+	}
+	if _, err := f.usecase.FinishRegistration(f.user, "", response); !errors.Is(err, ErrPasskeySession) {
+		t.Fatalf("replayed registration response: err = %v, want ErrPasskeySession", err)
+	}
+}
+
+func TestPasskeyLoginRejectsInactiveUser(t *testing.T) {
+	f := newPasskeyFixture(t)
+	authenticator := newSoftAuthenticator(t)
+	f.register(t, authenticator, "")
+
+	f.user.IsActive = false
+	if err := f.userRepo.Update(f.user); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.login(t, authenticator); !errors.Is(err, ErrPasskeyLogin) {
+		t.Fatalf("inactive user: err = %v, want ErrPasskeyLogin", err)
+	}
+}
+
+func TestPasskeyCanBeRegisteredAgainAfterDelete(t *testing.T) {
+	f := newPasskeyFixture(t)
+	authenticator := newSoftAuthenticator(t)
+
+	passkey := f.register(t, authenticator, "Laptop")
+	if err := f.usecase.Delete(f.user.ID, passkey.UUID.String()); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+
+	// Credential yang dihapus tidak boleh lagi ada di exclusion list
+	creation, err := f.usecase.BeginRegistration(f.user)
+	if err != nil {
+		t.Fatalf("begin registration: %v", err)
+	}
+	if len(creation.Response.CredentialExcludeList) != 0 {
+		t.Fatalf("deleted credential still excluded: %+v", creation.Response.CredentialExcludeList)
+	}
+
+	again, err := f.usecase.FinishRegistration(f.user, "Laptop", authenticator.Register(creation))
+	if err != nil {
+		t.Fatalf("re-register same authenticator: %v", err)
+	}
+	if _, err := f.login(t, authenticator); err != nil {
+		t.Fatalf("login with re-registered passkey: %v", err)
+	}
+
+	var count int64
+	f.userRepo.DB.Unscoped().Model(&domain.Passkey{}).Where("user_id = ?", f.user.ID).Count(&count)
+	if count != 1 || again.ID == passkey.ID {
+		t.Fatalf("expected only the new passkey row, got %d rows", count)
+	}
+}
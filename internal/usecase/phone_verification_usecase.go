@@ -0,0 +1,230 @@
+package usecase
+
+import (
+	"codebase-api/internal/domain"
+	"codebase-api/internal/repository"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// SMSSender mengirim SMS; implementasi gateway nyata cukup memenuhi interface ini
+type SMSSender interface {
+	Send(to string, message string) error
+}
+
+const (
+	PhoneOTPTTL         = 5 * time.Minute
+	phoneOTPMaxAttempts = 5
+	phoneOTPCooldown    = time.Minute
+	phoneOTPHourlyLimit = 5
+)
+
+var (
+	ErrPhoneMissing         = domain.Invalid("phone", "user has no phone number")
+	ErrPhoneAlreadyVerified = domain.Invalid("phone", "phone number is already verified")
+	ErrOTPInvalid           = domain.Invalid("phone", "verification code is invalid")
+	ErrOTPExpired           = domain.Invalid("phone", "verification code is expired or was not requested")
+	ErrOTPTooManyAttempts   = domain.RateLimited("too many invalid attempts, request a new code")
+)
+
+// RateLimitError dikembalikan saat OTP diminta terlalu sering; RetryAfter dipakai handler untuk header Retry-After
+type RateLimitError struct {
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("too many verification codes requested, retry in %s", e.RetryAfter.Round(time.Second))
+}
+
+// Unwrap membuat status dan pesannya ditentukan oleh pemetaan error domain seperti error lain
+func (e *RateLimitError) Unwrap() error {
+	return domain.RateLimited(e.Error())
+}
+
+// phoneOTP disimpan per user; hanya hash kode yang disimpan. Jumlah percobaan dihitung di key
+// terpisah dengan Incr agar atomik di semua instance
+type phoneOTP struct {
+	Phone     string    `json:"phone"`
+	CodeHash  string    `json:"code_hash"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type PhoneVerificationUseCase struct {
+	userRepo *repository.UserRepository
+	store    CounterStore
+	sender   SMSSender
+}
+
+func NewPhoneVerificationUseCase(userRepo *repository.UserRepository, store CounterStore, sender SMSSender) *PhoneVerificationUseCase {
+	return &PhoneVerificationUseCase{userRepo: userRepo, store: store, sender: sender}
+}
+
+func phoneOTPKey(userID uint) string {
+	return fmt.Sprintf("phone_otp:%d", userID)
+}
+
+func phoneOTPAttemptsKey(userID uint) string {
+	return fmt.Sprintf("phone_otp:attempts:%d", userID)
+}
+
+func phoneOTPCooldownKey(userID uint) string {
+	return fmt.Sprintf("phone_otp:cooldown:%d", userID)
+}
+
+func phoneOTPWindowKey(userID uint) string {
+	return fmt.Sprintf("phone_otp:window:%d", userID)
+}
+
+func phoneOTPHash(userID uint, phone string, code string) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%d:%s:%s", userID, phone, code)))
+	return hex.EncodeToString(sum[:])
+}
+
+// Send membuat kode 6 digit dan mengirimkannya ke nomor user.
+// Dibatasi satu kode per menit dan phoneOTPHourlyLimit kode per jam; kuota dikembalikan jika SMS gagal dikirim
+func (u *PhoneVerificationUseCase) Send(user *domain.User) error {
+	if user.Phone == nil || *user.Phone == "" {
+		return ErrPhoneMissing
+	}
+	if user.PhoneVerifiedAt != nil {
+		return ErrPhoneAlreadyVerified
+	}
+	if err := u.reserveSend(user.ID); err != nil {
+		return err
+	}
+	if err := u.issue(user); err != nil {
+		u.releaseSend(user.ID)
+		return err
+	}
+	return nil
+}
+
+// issue menyimpan kode baru (percobaan direset) lalu mengirimkannya; kode dibuang jika SMS gagal
+func (u *PhoneVerificationUseCase) issue(user *domain.User) error {
+	n, err := rand.Int(rand.Reader, big.NewInt(1_000_000))
+	if err != nil {
+		return err
+	}
+	code := fmt.Sprintf("%06d", n.Int64())
+
+	data, err := json.Marshal(phoneOTP{
+		Phone:     *user.Phone,
+		CodeHash:  phoneOTPHash(user.ID, *user.Phone, code),
+		ExpiresAt: time.Now().Add(PhoneOTPTTL),
+	})
+	if err != nil {
+		return err
+	}
+	if err := u.store.Set(phoneOTPKey(user.ID), data, PhoneOTPTTL); err != nil {
+		return err
+	}
+	if err := u.store.Delete(phoneOTPAttemptsKey(user.ID)); err != nil {
+		return err
+	}
+
+	message := fmt.Sprintf("Your verification code is %s. It expires in %d minutes.", code, int(PhoneOTPTTL.Minutes()))
+	if err := u.sender.Send(*user.Phone, message); err != nil {
+		_ = u.store.Delete(phoneOTPKey(user.ID))
+		return err
+	}
+	return nil
+}
+
+// reserveSend mengambil jatah pengiriman: cooldown lewat SetNX dan jendela per jam lewat Incr,
+// keduanya atomik sehingga request paralel dari instance mana pun tidak bisa melewati batas
+func (u *PhoneVerificationUseCase) reserveSend(userID uint) error {
+	cooldownKey := phoneOTPCooldownKey(userID)
+	ok, err := u.store.SetNX(cooldownKey, []byte("1"), phoneOTPCooldown)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		ttl, _ := u.store.TTL(cooldownKey)
+		return &RateLimitError{RetryAfter: ttl}
+	}
+
+	windowKey := phoneOTPWindowKey(userID)
+	count, err := u.store.Incr(windowKey, 1, time.Hour)
+	if err != nil {
+		_ = u.store.Delete(cooldownKey)
+		return err
+	}
+	if count > phoneOTPHourlyLimit {
+		ttl, _ := u.store.TTL(windowKey)
+		return &RateLimitError{RetryAfter: ttl}
+	}
+	return nil
+}
+
+// releaseSend mengembalikan jatah yang diambil reserveSend ketika kode gagal dibuat atau dikirim
+func (u *PhoneVerificationUseCase) releaseSend(userID uint) {
+	_, _ = u.store.Incr(phoneOTPWindowKey(userID), -1, time.Hour)
+	_ = u.store.Delete(phoneOTPCooldownKey(userID))
+}
+
+// Verify mencocokkan kode; kode hangus setelah berhasil atau setelah phoneOTPMaxAttempts kali salah
+func (u *PhoneVerificationUseCase) Verify(user *domain.User, code string) (*domain.User, error) {
+	if err := u.checkCode(user, code); err != nil {
+		return nil, err
+	}
+
+	fresh, err := u.userRepo.FindByID(user.ID)
+	if err != nil {
+		return nil, notFoundAs(err, ErrUserNotFound)
+	}
+	now := time.Now()
+	fresh.PhoneVerifiedAt = &now
+	if err := u.userRepo.Update(fresh); err != nil {
+		return nil, err
+	}
+	return fresh, nil
+}
+
+// checkCode menghitung percobaan dengan Incr sebelum kode dibandingkan, sehingga tebakan paralel
+// tetap dibatasi phoneOTPMaxAttempts
+func (u *PhoneVerificationUseCase) checkCode(user *domain.User, code string) error {
+	key := phoneOTPKey(user.ID)
+	data, err := u.store.Get(key)
+	if err != nil || len(data) == 0 {
+		return ErrOTPExpired
+	}
+
+	var otp phoneOTP
+	if err := json.Unmarshal(data, &otp); err != nil || time.Now().After(otp.ExpiresAt) {
+		_ = u.store.Delete(key)
+		return ErrOTPExpired
+	}
+	// Nomor berubah setelah kode dikirim
+	if user.Phone == nil || *user.Phone != otp.Phone {
+		_ = u.store.Delete(key)
+		return ErrOTPExpired
+	}
+
+	attempts, err := u.store.Incr(phoneOTPAttemptsKey(user.ID), 1, PhoneOTPTTL)
+	if err != nil {
+		return err
+	}
+	if attempts > phoneOTPMaxAttempts {
+		// Kode sudah hangus oleh percobaan lain
+		return ErrOTPExpired
+	}
+
+	expected := []byte(otp.CodeHash)
+	actual := []byte(phoneOTPHash(user.ID, otp.Phone, code))
+	if subtle.ConstantTimeCompare(expected, actual) != 1 {
+		if attempts == phoneOTPMaxAttempts {
+			_ = u.store.Delete(key)
+			return ErrOTPTooManyAttempts
+		}
+		return ErrOTPInvalid
+	}
+
+	_ = u.store.Delete(phoneOTPAttemptsKey(user.ID))
+	return u.store.Delete(key)
+}
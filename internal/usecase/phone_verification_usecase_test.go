@@ -0,0 +1,311 @@
+package usecase
+
+import (
+	"codebase-api/internal/domain"
+	"errors"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingSMS menyimpan SMS terakhir per nomor agar test bisa membaca kode OTP
+type recordingSMS struct {
+	mu       sync.Mutex
+	messages map[string]string
+}
+
+var otpCodePattern = regexp.MustCompile(`\b\d{6}\b`)
+
+func (s *recordingSMS) Send(to string, message string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.messages[to] = message
+	return nil
+}
+
+func (s *recordingSMS) code(t *testing.T, to string) string {
+	t.Helper()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	code := otpCodePattern.FindString(s.messages[to])
+	if code == "" {
+		t.Fatalf("no code sent to %s", to)
+	}
+	return code
+}
+
+type phoneFixture struct {
+	usecase *PhoneVerificationUseCase
+	store   *memoryStore
+	sms     *recordingSMS
+	user    *domain.User
+}
+
+func newPhoneFixture(t *testing.T) *phoneFixture {
+	t.Helper()
+
+	userRepo := newUserRepo(t)
+	user := createUser(t, userRepo, "budi")
+	phone := "+628111111111"
+	user.Phone = &phone
+	if err := userRepo.Update(user); err != nil {
+		t.Fatal(err)
+	}
+
+	store := newMemoryStore()
+	sms := &recordingSMS{messages: map[string]string{}}
+	return &phoneFixture{usecase: NewPhoneVerificationUseCase(userRepo, store, sms), store: store, sms: sms, user: user}
+}
+
+func TestPhoneVerificationSucceeds(t *testing.T) {
+	f := newPhoneFixture(t)
+
+	if err := f.usecase.Send(f.user); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	verified, err := f.usecase.Verify(f.user, f.sms.code(t, *f.user.Phone))
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if verified.PhoneVerifiedAt == nil {
+		t.Fatal("phone_verified_at not set")
+	}
+
+	// Kode hanya bisa dipakai sekali
+	if _, err := f.usecase.Verify(f.user, f.sms.code(t, *f.user.Phone)); !errors.Is(err, ErrOTPExpired) {
+		t.Fatalf("reused code: err = %v, want ErrOTPExpired", err)
+	}
+	if err := f.usecase.Send(verified); !errors.Is(err, ErrPhoneAlreadyVerified) {
+		t.Fatalf("send after verify: err = %v", err)
+	}
+}
+
+func TestPhoneVerificationCooldown(t *testing.T) {
+	f := newPhoneFixture(t)
+
+	if err := f.usecase.Send(f.user); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	var rateLimit *RateLimitError
+	if err := f.usecase.Send(f.user); !errors.As(err, &rateLimit) {
+		t.Fatalf("second send: err = %v, want RateLimitError", err)
+	}
+	if rateLimit.RetryAfter <= 0 || rateLimit.RetryAfter > phoneOTPCooldown {
+		t.Fatalf("retry after = %s", rateLimit.RetryAfter)
+	}
+
+	f.store.expire(phoneOTPCooldownKey(f.user.ID))
+	if err := f.usecase.Send(f.user); err != nil {
+		t.Fatalf("send after cooldown: %v", err)
+	}
+}
+
+func TestPhoneVerificationCooldownHoldsUnderConcurrency(t *testing.T) {
+	f := newPhoneFixture(t)
+	f.usecase.store = slowStore{f.store}
+
+	const sends = 20
+	results := make(chan error, sends)
+	var wg sync.WaitGroup
+	for i := 0; i < sends; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results <- f.usecase.Send(f.user)
+		}()
+	}
+	wg.Wait()
+	close(results)
+
+	sent := 0
+	for err := range results {
+		var rateLimit *RateLimitError
+		switch {
+		case err == nil:
+			sent++
+		case !errors.As(err, &rateLimit):
+			t.Fatalf("send: %v", err)
+		}
+	}
+	if sent != 1 {
+		t.Fatalf("%d codes sent in parallel, want 1", sent)
+	}
+}
+
+func TestPhoneVerificationHourlyLimit(t *testing.T) {
+	f := newPhoneFixture(t)
+	cooldownKey := phoneOTPCooldownKey(f.user.ID)
+
+	for i := 0; i < phoneOTPHourlyLimit; i++ {
+		if err := f.usecase.Send(f.user); err != nil {
+			t.Fatalf("send %d: %v", i+1, err)
+		}
+		f.store.expire(cooldownKey)
+	}
+
+	var rateLimit *RateLimitError
+	if err := f.usecase.Send(f.user); !errors.As(err, &rateLimit) {
+		t.Fatalf("send over hourly limit: err = %v, want RateLimitError", err)
+	}
+	if rateLimit.RetryAfter <= phoneOTPCooldown || rateLimit.RetryAfter > time.Hour {
+		t.Fatalf("retry after = %s, want the rest of the hour window", rateLimit.RetryAfter)
+	}
+}
+
+// failingSMS menolak pengiriman seperti gateway yang sedang down
+type failingSMS struct{}
+
+var errGatewayDown = errors.New("sms gateway is down")
+
+func (failingSMS) Send(to string, message string) error {
+	return errGatewayDown
+}
+
+func TestPhoneVerificationFailedSendKeepsQuota(t *testing.T) {
+	f := newPhoneFixture(t)
+	f.usecase.sender = failingSMS{}
+
+	for i := 0; i < phoneOTPHourlyLimit+1; i++ {
+		if err := f.usecase.Send(f.user); !errors.Is(err, errGatewayDown) {
+			t.Fatalf("send %d: err = %v, want the gateway error", i+1, err)
+		}
+	}
+	if _, err := f.usecase.Verify(f.user, "123456"); !errors.Is(err, ErrOTPExpired) {
+		t.Fatalf("code from a failed send must not be stored: err = %v", err)
+	}
+
+	// Gateway pulih: tidak ada cooldown dan kuota per jam masih utuh
+	f.usecase.sender = f.sms
+	if err := f.usecase.Send(f.user); err != nil {
+		t.Fatalf("send after gateway recovered: %v", err)
+	}
+}
+
+func TestPhoneVerificationNewCodeResetsAttempts(t *testing.T) {
+	f := newPhoneFixture(t)
+
+	if err := f.usecase.Send(f.user); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	code := f.sms.code(t, *f.user.Phone)
+	for i := 1; i < phoneOTPMaxAttempts; i++ {
+		if _, err := f.usecase.Verify(f.user, wrongCode(code)); !errors.Is(err, ErrOTPInvalid) {
+			t.Fatalf("attempt %d: err = %v, want ErrOTPInvalid", i, err)
+		}
+	}
+
+	f.store.expire(phoneOTPCooldownKey(f.user.ID))
+	if err := f.usecase.Send(f.user); err != nil {
+		t.Fatalf("resend: %v", err)
+	}
+	code = f.sms.code(t, *f.user.Phone)
+	if _, err := f.usecase.Verify(f.user, wrongCode(code)); !errors.Is(err, ErrOTPInvalid) {
+		t.Fatalf("first attempt on the new code: err = %v, want ErrOTPInvalid", err)
+	}
+	if _, err := f.usecase.Verify(f.user, code); err != nil {
+		t.Fatalf("verify new code: %v", err)
+	}
+}
+
+func TestPhoneVerificationAttemptLimit(t *testing.T) {
+	f := newPhoneFixture(t)
+
+	if err := f.usecase.Send(f.user); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	code := f.sms.code(t, *f.user.Phone)
+
+	for i := 1; i < phoneOTPMaxAttempts; i++ {
+		if _, err := f.usecase.Verify(f.user, wrongCode(code)); !errors.Is(err, ErrOTPInvalid) {
+			t.Fatalf("attempt %d: err = %v, want ErrOTPInvalid", i, err)
+		}
+	}
+	if _, err := f.usecase.Verify(f.user, wrongCode(code)); !errors.Is(err, ErrOTPTooManyAttempts) {
+		t.Fatalf("last attempt: err = %v, want ErrOTPTooManyAttempts", err)
+	}
+	// Kode hangus setelah batas percobaan, kode yang benar pun ditolak
+	if _, err := f.usecase.Verify(f.user, code); !errors.Is(err, ErrOTPExpired) {
+		t.Fatalf("correct code after lockout: err = %v, want ErrOTPExpired", err)
+	}
+}
+
+func TestPhoneVerificationAttemptLimitHoldsUnderConcurrency(t *testing.T) {
+	f := newPhoneFixture(t)
+	f.usecase.store = slowStore{f.store}
+
+	if err := f.usecase.Send(f.user); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	guess := wrongCode(f.sms.code(t, *f.user.Phone))
+
+	const guesses = 50
+	results := make(chan error, guesses)
+	var wg sync.WaitGroup
+	for i := 0; i < guesses; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := f.usecase.Verify(f.user, guess)
+			results <- err
+		}()
+	}
+	wg.Wait()
+	close(results)
+
+	counts := map[error]int{}
+	for err := range results {
+		counts[err]++
+	}
+	if counts[ErrOTPInvalid] != phoneOTPMaxAttempts-1 || counts[ErrOTPTooManyAttempts] != 1 || counts[ErrOTPExpired] != guesses-phoneOTPMaxAttempts {
+		t.Fatalf("parallel guesses were not limited: %v", counts)
+	}
+}
+
+func TestPhoneVerificationExpiry(t *testing.T) {
+	f := newPhoneFixture(t)
+
+	if _, err := f.usecase.Verify(f.user, "123456"); !errors.Is(err, ErrOTPExpired) {
+		t.Fatalf("verify without send: err = %v, want ErrOTPExpired", err)
+	}
+
+	if err := f.usecase.Send(f.user); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	code := f.sms.code(t, *f.user.Phone)
+	f.store.expire(phoneOTPKey(f.user.ID))
+
+	if _, err := f.usecase.Verify(f.user, code); !errors.Is(err, ErrOTPExpired) {
+		t.Fatalf("verify after expiry: err = %v, want ErrOTPExpired", err)
+	}
+}
+
+func TestPhoneVerificationRejectsChangedPhone(t *testing.T) {
+	f := newPhoneFixture(t)
+
+	if err := f.usecase.Send(f.user); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	code := f.sms.code(t, *f.user.Phone)
+
+	// Nomor diganti setelah kode dikirim: kode untuk nomor lama tidak boleh memverifikasi nomor baru
+	changed := *f.user
+	other := "+628222222222"
+	changed.Phone = &other
+	if _, err := f.usecase.Verify(&changed, code); !errors.Is(err, ErrOTPExpired) {
+		t.Fatalf("verify with changed phone: err = %v, want ErrOTPExpired", err)
+	}
+	if _, err := f.usecase.Verify(f.user, code); !errors.Is(err, ErrOTPExpired) {
+		t.Fatal("code should be discarded once the phone no longer matches")
+	}
+}
+
+func TestPhoneVerificationRequiresPhone(t *testing.T) {
+	f := newPhoneFixture(t)
+
+	f.user.Phone = nil
+	if err := f.usecase.Send(f.user); !errors.Is(err, ErrPhoneMissing) {
+		t.Fatalf("err = %v, want ErrPhoneMissing", err)
+	}
+}
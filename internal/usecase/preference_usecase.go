@@ -0,0 +1,151 @@
+package usecase
+
+import (
+	"codebase-api/internal/domain"
+	"codebase-api/internal/repository"
+	"codebase-api/pkg/preferences"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+)
+
+// UserPreferences adalah daftar preferensi yang dikenal beserta default-nya.
+// Tambahkan key baru di sini, bukan sebagai kolom baru di domain.User
+var UserPreferences = preferences.Schema{
+	"language": {Kind: preferences.String, Default: "en", Allowed: []string{"en", "id"}},
+	"theme":    {Kind: preferences.String, Default: "system", Allowed: []string{"system", "light", "dark"}},
+	"timezone": {Kind: preferences.String, Default: "UTC", Validate: func(value interface{}) error {
+		if _, err := time.LoadLocation(value.(string)); err != nil || value == "" || value == "Local" {
+			return errors.New("Must be a valid IANA time zone")
+		}
+		return nil
+	}},
+	"notifications.email":  {Kind: preferences.Bool, Default: true},
+	"notifications.push":   {Kind: preferences.Bool, Default: true},
+	"notifications.digest": {Kind: preferences.String, Default: "weekly", Allowed: []string{"daily", "weekly", "never"}},
+}
+
+type cachedPreferences struct {
+	values    preferences.Values
+	version   uint
+	expiresAt time.Time
+}
+
+// preferenceCacheSize membatasi jumlah user yang preferensinya disimpan di memori
+const preferenceCacheSize = 10_000
+
+type PreferenceUseCase struct {
+	userRepo *repository.UserRepository
+	ttl      time.Duration
+	maxSize  int
+	mu       sync.RWMutex
+	cache    map[uint]cachedPreferences
+}
+
+func NewPreferenceUseCase(userRepo *repository.UserRepository, ttl time.Duration) *PreferenceUseCase {
+	return &PreferenceUseCase{userRepo: userRepo, ttl: ttl, maxSize: preferenceCacheSize, cache: map[uint]cachedPreferences{}}
+}
+
+// Get mengembalikan preferensi user (default + nilai yang disimpan) beserta version baris user untuk ETag,
+// di-cache selama ttl
+func (u *PreferenceUseCase) Get(userID uint) (preferences.Values, uint, error) {
+	u.mu.RLock()
+	entry, ok := u.cache[userID]
+	u.mu.RUnlock()
+	if ok && time.Now().Before(entry.expiresAt) {
+		return entry.values, entry.version, nil
+	}
+
+	stored, version, err := u.stored(userID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	values := UserPreferences.Resolve(stored)
+	u.remember(userID, values, version)
+	return values, version, nil
+}
+
+// Update menggabungkan patch dengan preferensi yang tersimpan; nilai null mengembalikan key ke default.
+// expected adalah version dari If-Match (0 berarti tidak dicek); penulisan tetap dikunci ke version yang
+// dibaca sehingga dua PATCH bersamaan tidak saling menimpa
+func (u *PreferenceUseCase) Update(userID uint, expected uint, patch map[string]interface{}) (preferences.Values, uint, error) {
+	stored, version, err := u.stored(userID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := checkVersion(version, expected); err != nil {
+		// ETag client mungkin berasal dari cache yang tertinggal (profil diubah lewat endpoint lain)
+		u.Forget(userID)
+		return nil, 0, err
+	}
+
+	overrides, fieldErrors := UserPreferences.Merge(stored, patch)
+	if fieldErrors != nil {
+		return nil, 0, domain.InvalidFields("invalid preferences", fieldErrors)
+	}
+
+	data, err := json.Marshal(overrides)
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := u.userRepo.UpdatePreferences(userID, version, data); err != nil {
+		u.Forget(userID)
+		return nil, 0, staleAs(err, expected)
+	}
+
+	values := UserPreferences.Resolve(overrides)
+	u.remember(userID, values, version+1)
+	return values, version + 1, nil
+}
+
+func (u *PreferenceUseCase) stored(userID uint) (map[string]interface{}, uint, error) {
+	user, err := u.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, 0, notFoundAs(err, ErrUserNotFound)
+	}
+
+	stored := map[string]interface{}{}
+	if len(user.Preferences) > 0 {
+		if err := json.Unmarshal(user.Preferences, &stored); err != nil {
+			return nil, 0, err
+		}
+	}
+	return stored, user.Version, nil
+}
+
+func (u *PreferenceUseCase) remember(userID uint, values preferences.Values, version uint) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if _, ok := u.cache[userID]; !ok && len(u.cache) >= u.maxSize {
+		u.evict()
+	}
+	u.cache[userID] = cachedPreferences{values: values, version: version, expiresAt: time.Now().Add(u.ttl)}
+}
+
+// evict membuang entry yang sudah kedaluwarsa; bila semuanya masih berlaku, satu entry acak dibuang
+// (urutan iterasi map Go acak). Pemanggil memegang u.mu
+func (u *PreferenceUseCase) evict() {
+	now := time.Now()
+	for userID, entry := range u.cache {
+		if now.After(entry.expiresAt) {
+			delete(u.cache, userID)
+		}
+	}
+	if len(u.cache) < u.maxSize {
+		return
+	}
+	for userID := range u.cache {
+		delete(u.cache, userID)
+		return
+	}
+}
+
+// Forget membuang preferensi user dari cache, mis. setelah user di-erase atau di-purge
+func (u *PreferenceUseCase) Forget(userID uint) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	delete(u.cache, userID)
+}
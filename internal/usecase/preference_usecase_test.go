@@ -0,0 +1,105 @@
+package usecase
+
+import (
+	"codebase-api/internal/repository"
+	"testing"
+	"time"
+)
+
+func TestPreferenceUpdateResetsKeyWithNull(t *testing.T) {
+	userRepo := newUserRepo(t)
+	prefs := NewPreferenceUseCase(userRepo, time.Minute)
+	user := createUser(t, userRepo, "budi")
+
+	values, version, err := prefs.Update(user.ID, 0, map[string]interface{}{"theme": "dark", "language": "id"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values.String("theme") != "dark" || values.String("language") != "id" || version != user.Version+1 {
+		t.Fatalf("values = %v, version = %d", values, version)
+	}
+
+	values, _, err = prefs.Update(user.ID, version, map[string]interface{}{"theme": nil})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values.String("theme") != "system" || values.String("language") != "id" {
+		t.Fatalf("values = %v", values)
+	}
+
+	// Hanya nilai yang berbeda dari default yang disimpan
+	saved, err := userRepo.FindByID(user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(saved.Preferences) != `{"language":"id"}` {
+		t.Fatalf("stored = %s", saved.Preferences)
+	}
+}
+
+func TestPreferenceCacheIsBounded(t *testing.T) {
+	userRepo := newUserRepo(t)
+	prefs := NewPreferenceUseCase(userRepo, time.Minute)
+	prefs.maxSize = 2
+
+	for _, username := range []string{"budi", "siti", "andi", "joko"} {
+		user := createUser(t, userRepo, username)
+		if _, _, err := prefs.Get(user.ID); err != nil {
+			t.Fatal(err)
+		}
+		if len(prefs.cache) > prefs.maxSize {
+			t.Fatalf("cache holds %d entries, max %d", len(prefs.cache), prefs.maxSize)
+		}
+	}
+}
+
+func TestPreferenceCacheEvictsExpiredEntriesFirst(t *testing.T) {
+	userRepo := newUserRepo(t)
+	prefs := NewPreferenceUseCase(userRepo, time.Minute)
+	prefs.maxSize = 2
+
+	expired, fresh, next := createUser(t, userRepo, "budi"), createUser(t, userRepo, "siti"), createUser(t, userRepo, "andi")
+	prefs.Get(expired.ID)
+	prefs.Get(fresh.ID)
+	entry := prefs.cache[expired.ID]
+	entry.expiresAt = time.Now().Add(-time.Second)
+	prefs.cache[expired.ID] = entry
+
+	prefs.Get(next.ID)
+	if _, ok := prefs.cache[expired.ID]; ok {
+		t.Fatal("expired entry was kept")
+	}
+	if _, ok := prefs.cache[fresh.ID]; !ok {
+		t.Fatal("fresh entry was evicted while an expired one existed")
+	}
+}
+
+func TestPurgeAndEraseForgetCachedPreferences(t *testing.T) {
+	userRepo := newUserRepo(t)
+	prefs := NewPreferenceUseCase(userRepo, time.Minute)
+	users := NewUserUseCase(userRepo).WithPreferences(prefs)
+	privacy := NewPrivacyUseCase(userRepo, repository.NewPasskeyRepository(userRepo.DB), repository.NewGroupMemberRepository(userRepo.DB), prefs, newMemoryFiles())
+
+	erased, purged := createUser(t, userRepo, "budi"), createUser(t, userRepo, "siti")
+	for _, id := range []uint{erased.ID, purged.ID} {
+		if _, _, err := prefs.Update(id, 0, map[string]interface{}{"theme": "dark"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := privacy.Erase(erased.ID, purged.ID, "request"); err != nil {
+		t.Fatal(err)
+	}
+	if err := users.Purge(purged.ID); err != nil {
+		t.Fatal(err)
+	}
+	if len(prefs.cache) != 0 {
+		t.Fatalf("cache still holds %d entries", len(prefs.cache))
+	}
+
+	// Preferensi user yang di-erase juga ikut dihapus dari database
+	values, _, err := prefs.Get(erased.ID)
+	if err != nil || values.String("theme") != "system" {
+		t.Fatalf("values after erase = %v, %v", values, err)
+	}
+}
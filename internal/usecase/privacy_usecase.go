@@ -0,0 +1,200 @@
+package usecase
+
+import (
+	"archive/zip"
+	"codebase-api/internal/domain"
+	"codebase-api/internal/repository"
+	"context"
+	"encoding/json"
+	"io"
+	"time"
+)
+
+var ErrUserAlreadyErased = domain.Conflict("", "user data has already been erased")
+
+// PrivacyUseCase menangani export data pribadi (GDPR) dan right-to-erasure
+type PrivacyUseCase struct {
+	userRepo    *repository.UserRepository
+	passkeyRepo *repository.PasskeyRepository
+	memberRepo  *repository.GroupMemberRepository
+	preferences *PreferenceUseCase
+	files       FileStore
+}
+
+func NewPrivacyUseCase(userRepo *repository.UserRepository, passkeyRepo *repository.PasskeyRepository, memberRepo *repository.GroupMemberRepository, preferences *PreferenceUseCase, files FileStore) *PrivacyUseCase {
+	return &PrivacyUseCase{userRepo: userRepo, passkeyRepo: passkeyRepo, memberRepo: memberRepo, preferences: preferences, files: files}
+}
+
+type dataExportFile struct {
+	name string
+	data func() (interface{}, error)
+}
+
+// Export menulis ZIP berisi file JSON untuk setiap kategori data milik user.
+// Password hash, refresh token dan public key credential tidak ikut diekspor
+func (u *PrivacyUseCase) Export(ctx context.Context, userID uint, w io.Writer) error {
+	user, err := u.userRepo.FindByID(userID)
+	if err != nil {
+		return notFoundAs(err, ErrUserNotFound)
+	}
+
+	files := []dataExportFile{
+		{"profile.json", func() (interface{}, error) { return exportProfile(user), nil }},
+		{"preferences.json", func() (interface{}, error) {
+			values, _, err := u.preferences.Get(userID)
+			return values, err
+		}},
+		{"passkeys.json", func() (interface{}, error) { return u.exportPasskeys(userID) }},
+		{"groups.json", func() (interface{}, error) { return u.exportGroups(userID) }},
+	}
+
+	archive := zip.NewWriter(w)
+	var names []string
+	for _, file := range files {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		data, err := file.data()
+		if err != nil {
+			return err
+		}
+		if err := writeZipJSON(archive, file.name, data); err != nil {
+			return err
+		}
+		names = append(names, file.name)
+	}
+
+	// Belum ada penyimpanan session maupun security event di server (JWT bersifat stateless),
+	// sehingga kategori tersebut dicatat di manifest sebagai kosong
+	manifest := map[string]interface{}{
+		"user_id":      user.UUID.String(),
+		"generated_at": time.Now(),
+		"files":        names,
+		"not_stored":   []string{"sessions", "security_events"},
+	}
+	if err := writeZipJSON(archive, "manifest.json", manifest); err != nil {
+		return err
+	}
+	return archive.Close()
+}
+
+func writeZipJSON(archive *zip.Writer, name string, data interface{}) error {
+	w, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(data)
+}
+
+func exportProfile(user *domain.User) map[string]interface{} {
+	return map[string]interface{}{
+		"id":                user.UUID.String(),
+		"first_name":        user.FirstName,
+		"last_name":         user.LastName,
+		"username":          user.Username,
+		"email":             user.Email,
+		"email_verified_at": user.EmailVerifiedAt,
+		"phone":             user.Phone,
+		"phone_verified_at": user.PhoneVerifiedAt,
+		"is_active":         user.IsActive,
+		"role":              user.Role,
+		"auth_source":       user.AuthSource,
+		"external_id":       user.ExternalID,
+		"avatar_keys":       user.AvatarKeys(),
+		"created_at":        user.CreatedAt,
+		"updated_at":        user.UpdatedAt,
+	}
+}
+
+func (u *PrivacyUseCase) exportPasskeys(userID uint) ([]map[string]interface{}, error) {
+	passkeys, err := u.passkeyRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []map[string]interface{}{}
+	for _, p := range passkeys {
+		result = append(result, map[string]interface{}{
+			"id":           p.UUID.String(),
+			"name":         p.Name,
+			"transports":   p.Transports,
+			"created_at":   p.CreatedAt,
+			"last_used_at": p.LastUsedAt,
+		})
+	}
+	return result, nil
+}
+
+func (u *PrivacyUseCase) exportGroups(userID uint) ([]map[string]interface{}, error) {
+	members, err := u.memberRepo.ListByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []map[string]interface{}{}
+	for _, m := range members {
+		entry := map[string]interface{}{"role": m.Role, "joined_at": m.CreatedAt}
+		if m.Group != nil {
+			entry["group_id"] = m.Group.UUID.String()
+			entry["name"] = m.Group.Name
+			entry["description"] = m.Group.Description
+		}
+		result = append(result, entry)
+	}
+	return result, nil
+}
+
+// Erase menganonimkan PII user (nama, username, email, telepon, avatar) tanpa menghapus barisnya,
+// sehingga relasi ke data lain tetap valid, lalu mencatat erasure tersebut
+func (u *PrivacyUseCase) Erase(userID uint, requestedBy uint, reason string) (*domain.User, error) {
+	user, err := u.userRepo.FindByIDWithTrashed(userID)
+	if err != nil {
+		return nil, notFoundAs(err, ErrUserNotFound)
+	}
+	if user.ErasedAt != nil {
+		return nil, ErrUserAlreadyErased
+	}
+
+	previous := *user
+	now := time.Now()
+	anonymous := "erased-" + user.UUID.String()
+
+	user.FirstName = "Deleted"
+	user.LastName = "User"
+	user.Username = anonymous
+	user.Email = anonymous + "@erased.invalid"
+	user.EmailVerifiedAt = nil
+	user.Phone = nil
+	user.PhoneVerifiedAt = nil
+	// Hash kosong tidak akan pernah cocok dengan password apa pun
+	user.Password = ""
+	user.RefreshToken = ""
+	user.TOTPSecret = nil
+	user.TOTPEnabledAt = nil
+	user.ExternalID = nil
+	user.AvatarPath = nil
+	user.Preferences = nil
+	user.IsActive = false
+	user.ErasedAt = &now
+
+	record := &domain.ErasureRecord{
+		UserID:      user.ID,
+		UserUUID:    user.UUID,
+		RequestedBy: requestedBy,
+		Reason:      reason,
+		ErasedAt:    now,
+	}
+	if err := u.userRepo.Erase(user, record); err != nil {
+		return nil, err
+	}
+	u.preferences.Forget(userID)
+
+	for _, key := range previous.AvatarKeys() {
+		u.files.Delete(key)
+	}
+	return user, nil
+}
@@ -0,0 +1,183 @@
+package usecase
+
+import (
+	"archive/zip"
+	"bytes"
+	"codebase-api/internal/domain"
+	"codebase-api/internal/repository"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+type privacyFixture struct {
+	usecase     *PrivacyUseCase
+	userRepo    *repository.UserRepository
+	preferences *PreferenceUseCase
+	files       *memoryFiles
+}
+
+func newPrivacyFixture(t *testing.T) *privacyFixture {
+	t.Helper()
+
+	userRepo := newUserRepo(t)
+	prefs := NewPreferenceUseCase(userRepo, time.Minute)
+	files := newMemoryFiles()
+	return &privacyFixture{
+		usecase:     NewPrivacyUseCase(userRepo, repository.NewPasskeyRepository(userRepo.DB), repository.NewGroupMemberRepository(userRepo.DB), prefs, files),
+		userRepo:    userRepo,
+		preferences: prefs,
+		files:       files,
+	}
+}
+
+// createPrivateUser membuat user dengan telepon, avatar, TOTP dan refresh token
+func (f *privacyFixture) createPrivateUser(t *testing.T, username string) *domain.User {
+	t.Helper()
+
+	user := createUser(t, f.userRepo, username)
+	phone, avatar, secret := "+6281234567890", "avatars/"+username+"/abc", "JBSWY3DPEHPK3PXP"
+	user.Phone, user.AvatarPath, user.TOTPSecret = &phone, &avatar, &secret
+	user.RefreshToken = "refresh-secret"
+	if err := f.userRepo.Update(user); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range user.AvatarKeys() {
+		f.files.Put(key, []byte("jpeg"))
+	}
+	return user
+}
+
+func TestPrivacyExportContents(t *testing.T) {
+	f := newPrivacyFixture(t)
+	user := f.createPrivateUser(t, "budi")
+	if _, _, err := f.preferences.Update(user.ID, 0, map[string]interface{}{"theme": "dark"}); err != nil {
+		t.Fatal(err)
+	}
+	groups := NewGroupUseCase(repository.NewGroupRepository(f.userRepo.DB), repository.NewGroupMemberRepository(f.userRepo.DB), f.userRepo)
+	if _, err := groups.Create(user, "Finance", "Budget team"); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := f.usecase.Export(context.Background(), user.ID, &buf); err != nil {
+		t.Fatal(err)
+	}
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	contents := map[string][]byte{}
+	for _, file := range archive.File {
+		r, err := file.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		contents[file.Name] = data
+
+		// Secret tidak boleh ikut diekspor di file mana pun
+		for _, secret := range []string{"not-a-hash", "refresh-secret", *user.TOTPSecret} {
+			if bytes.Contains(data, []byte(secret)) {
+				t.Fatalf("%s contains secret %q", file.Name, secret)
+			}
+		}
+	}
+
+	var names []string
+	for name := range contents {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if want := []string{"groups.json", "manifest.json", "passkeys.json", "preferences.json", "profile.json"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("archive files = %q, want %q", names, want)
+	}
+
+	var profile map[string]interface{}
+	if err := json.Unmarshal(contents["profile.json"], &profile); err != nil {
+		t.Fatal(err)
+	}
+	if profile["id"] != user.UUID.String() || profile["email"] != "budi@example.com" || profile["phone"] != "+6281234567890" {
+		t.Fatalf("profile = %v", profile)
+	}
+	if _, ok := profile["password"]; ok {
+		t.Fatal("profile contains the password field")
+	}
+
+	var preferences map[string]interface{}
+	if err := json.Unmarshal(contents["preferences.json"], &preferences); err != nil {
+		t.Fatal(err)
+	}
+	if preferences["theme"] != "dark" || preferences["language"] != "en" {
+		t.Fatalf("preferences = %v", preferences)
+	}
+
+	var memberships []map[string]interface{}
+	if err := json.Unmarshal(contents["groups.json"], &memberships); err != nil {
+		t.Fatal(err)
+	}
+	if len(memberships) != 1 || memberships[0]["name"] != "Finance" || memberships[0]["role"] != domain.GroupRoleOwner {
+		t.Fatalf("groups = %v", memberships)
+	}
+
+	if strings.TrimSpace(string(contents["passkeys.json"])) != "[]" {
+		t.Fatalf("passkeys = %s", contents["passkeys.json"])
+	}
+}
+
+func TestPrivacyEraseAnonymizes(t *testing.T) {
+	f := newPrivacyFixture(t)
+	admin := createUser(t, f.userRepo, "admin")
+	user := f.createPrivateUser(t, "budi")
+	if _, _, err := f.preferences.Update(user.ID, 0, map[string]interface{}{"theme": "dark"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := f.usecase.Erase(user.ID, admin.ID, "user request"); err != nil {
+		t.Fatal(err)
+	}
+
+	erased, err := f.userRepo.FindByIDWithTrashed(user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	anonymous := "erased-" + user.UUID.String()
+	if erased.FirstName != "Deleted" || erased.LastName != "User" || erased.Username != anonymous || erased.Email != anonymous+"@erased.invalid" {
+		t.Fatalf("identity not anonymized: %+v", erased)
+	}
+	if erased.Phone != nil || erased.AvatarPath != nil || erased.TOTPSecret != nil || erased.Preferences != nil {
+		t.Fatalf("personal data kept: phone=%v avatar=%v totp=%v preferences=%s", erased.Phone, erased.AvatarPath, erased.TOTPSecret, erased.Preferences)
+	}
+	if erased.Password != "" || erased.RefreshToken != "" || erased.IsActive || erased.ErasedAt == nil {
+		t.Fatalf("credentials not revoked: %+v", erased)
+	}
+	if len(f.files.files) != 0 {
+		t.Fatalf("avatar files kept: %v", f.files.files)
+	}
+
+	var records []domain.ErasureRecord
+	if err := f.userRepo.DB.Find(&records).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || records[0].UserUUID != user.UUID || records[0].RequestedBy != admin.ID || records[0].Reason != "user request" {
+		t.Fatalf("erasure records = %+v", records)
+	}
+
+	// Identitas lama bisa dipakai ulang oleh akun baru
+	createUser(t, f.userRepo, "budi")
+
+	if _, err := f.usecase.Erase(user.ID, admin.ID, ""); !errors.Is(err, ErrUserAlreadyErased) {
+		t.Fatalf("second erase: err = %v, want ErrUserAlreadyErased", err)
+	}
+}
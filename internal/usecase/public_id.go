@@ -0,0 +1,55 @@
+package usecase
+
+import (
+	"codebase-api/config"
+	"codebase-api/internal/domain"
+	"errors"
+	"strconv"
+
+	"github.com/google/uuid"
+)
+
+type uuidResolver interface {
+	FindIDByUUID(uuid string) (uint, error)
+}
+
+// resolvePublicID menerjemahkan ID pada path (UUID) menjadi primary key.
+// ID numerik hanya diterima jika LEGACY_NUMERIC_IDS aktif.
+func resolvePublicID(repo uuidResolver, publicID string) (uint, error) {
+	if _, err := uuid.Parse(publicID); err == nil {
+		return repo.FindIDByUUID(publicID)
+	}
+
+	if config.LegacyNumericIDs() {
+		if id, err := strconv.ParseUint(publicID, 10, 64); err == nil && id > 0 {
+			return uint(id), nil
+		}
+	}
+	return 0, domain.NotFound("invalid public id")
+}
+
+// checkVersion memastikan entity belum berubah sejak dibaca client; expected 0 berarti tidak dicek
+func checkVersion(current uint, expected uint) error {
+	if expected != 0 && current != expected {
+		return domain.ErrVersionMismatch
+	}
+	return nil
+}
+
+// staleAs mengganti ErrStaleVersion (baris berubah di antara baca dan tulis) menjadi ErrVersionMismatch
+// bila client mengirim If-Match, karena version yang dikirim client sudah pasti tidak berlaku lagi
+func staleAs(err error, expected uint) error {
+	if expected != 0 && errors.Is(err, domain.ErrStaleVersion) {
+		return domain.ErrVersionMismatch
+	}
+	return err
+}
+
+// notFoundAs mengganti error NotFound generik dari repository dengan error yang lebih spesifik
+// (mis. ErrUserNotFound); error lain, seperti koneksi database, diteruskan apa adanya
+func notFoundAs(err error, notFound error) error {
+	if errors.Is(err, domain.ErrNotFound) {
+		return notFound
+	}
+	return err
+}
@@ -0,0 +1,72 @@
+package usecase
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+)
+
+func TestResolveIDAcceptsUUID(t *testing.T) {
+	userRepo := newUserRepo(t)
+	users := NewUserUseCase(userRepo)
+	budi := createUser(t, userRepo, "budi")
+
+	id, err := users.ResolveID(budi.UUID.String())
+	if err != nil || id != budi.ID {
+		t.Fatalf("ResolveID(uuid) = %d, %v, want %d", id, err, budi.ID)
+	}
+
+	// User di trash tetap bisa dialamatkan, mis. untuk restore dan purge
+	if err := users.Delete(budi.ID); err != nil {
+		t.Fatal(err)
+	}
+	if id, err := users.ResolveID(budi.UUID.String()); err != nil || id != budi.ID {
+		t.Fatalf("ResolveID(trashed uuid) = %d, %v, want %d", id, err, budi.ID)
+	}
+}
+
+func TestResolveIDRejectsUnknownAndMalformedIDs(t *testing.T) {
+	userRepo := newUserRepo(t)
+	users := NewUserUseCase(userRepo)
+	budi := createUser(t, userRepo, "budi")
+
+	for _, publicID := range []string{
+		"00000000-0000-0000-0000-000000000000",
+		"not-a-uuid",
+		"",
+		// ID numerik ditolak selama LEGACY_NUMERIC_IDS tidak aktif
+		strconv.FormatUint(uint64(budi.ID), 10),
+	} {
+		t.Run(publicID, func(t *testing.T) {
+			if id, err := users.ResolveID(publicID); !errors.Is(err, ErrUserNotFound) {
+				t.Fatalf("ResolveID(%q) = %d, %v, want ErrUserNotFound", publicID, id, err)
+			}
+		})
+	}
+}
+
+func TestResolveIDAcceptsLegacyNumericIDsBehindFlag(t *testing.T) {
+	t.Setenv("LEGACY_NUMERIC_IDS", "true")
+	userRepo := newUserRepo(t)
+	users := NewUserUseCase(userRepo)
+	budi := createUser(t, userRepo, "budi")
+
+	id, err := users.ResolveID(strconv.FormatUint(uint64(budi.ID), 10))
+	if err != nil || id != budi.ID {
+		t.Fatalf("ResolveID(numeric) = %d, %v, want %d", id, err, budi.ID)
+	}
+	if id, err := users.ResolveID(budi.UUID.String()); err != nil || id != budi.ID {
+		t.Fatalf("ResolveID(uuid) with legacy ids = %d, %v, want %d", id, err, budi.ID)
+	}
+
+	for _, publicID := range []string{"0", "-1", "1.5", "18446744073709551616"} {
+		if _, err := users.ResolveID(publicID); !errors.Is(err, ErrUserNotFound) {
+			t.Fatalf("ResolveID(%q): err = %v, want ErrUserNotFound", publicID, err)
+		}
+	}
+
+	// ID numerik yang tidak ada baru ditolak saat user dimuat
+	if _, err := users.FindById(budi.ID + 100); !errors.Is(err, ErrUserNotFound) {
+		t.Fatalf("FindById(unknown) err = %v, want ErrUserNotFound", err)
+	}
+}
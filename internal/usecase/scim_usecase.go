@@ -0,0 +1,255 @@
+package usecase
+
+import (
+	"codebase-api/internal/domain"
+	"codebase-api/internal/repository"
+	"codebase-api/pkg/identity"
+	"codebase-api/pkg/scim"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+type ScimUseCase struct {
+	userRepo *repository.UserRepository
+}
+
+func NewScimUseCase(userRepo *repository.UserRepository) *ScimUseCase {
+	return &ScimUseCase{userRepo: userRepo}
+}
+
+// ToScimUser mengubah domain.User menjadi resource User SCIM
+func ToScimUser(user domain.User) scim.User {
+	active := user.IsActive
+	resource := scim.User{
+		Schemas:  []string{scim.UserSchema},
+		ID:       user.UUID.String(),
+		UserName: user.Username,
+		Name: scim.Name{
+			Formatted:  strings.TrimSpace(user.FirstName + " " + user.LastName),
+			GivenName:  user.FirstName,
+			FamilyName: user.LastName,
+		},
+		DisplayName: strings.TrimSpace(user.FirstName + " " + user.LastName),
+		Emails:      []scim.MultiValue{{Value: user.Email, Type: "work", Primary: true}},
+		Active:      &active,
+		Meta: &scim.Meta{
+			ResourceType: "User",
+			Created:      user.CreatedAt,
+			LastModified: user.UpdatedAt,
+		},
+	}
+	if user.ExternalID != nil {
+		resource.ExternalID = *user.ExternalID
+	}
+	if user.Phone != nil && *user.Phone != "" {
+		resource.PhoneNumbers = []scim.MultiValue{{Value: *user.Phone, Type: "work", Primary: true}}
+	}
+	return resource
+}
+
+// scimValidate memakai aturan email / e164 yang sama dengan validasi Register di handler
+var scimValidate = validator.New()
+
+// applyScimUser menyalin attribute resource SCIM ke domain.User
+func applyScimUser(user *domain.User, resource *scim.User) error {
+	if strings.TrimSpace(resource.UserName) == "" {
+		return scim.NewError(http.StatusBadRequest, "invalidValue", "userName is required")
+	}
+	// Username lama yang kebetulan masuk daftar reserved tetap boleh dipertahankan
+	if identity.Username(resource.UserName) != user.UsernameCanonical && identity.IsReservedUsername(resource.UserName) {
+		return scim.NewError(http.StatusBadRequest, "invalidValue", "userName is reserved")
+	}
+
+	email := scim.PrimaryValue(resource.Emails)
+	if email == "" && strings.Contains(resource.UserName, "@") {
+		email = resource.UserName
+	}
+	if email == "" {
+		return scim.NewError(http.StatusBadRequest, "invalidValue", "an email address is required")
+	}
+	if scimValidate.Var(strings.TrimSpace(email), "email") != nil {
+		return scim.NewError(http.StatusBadRequest, "invalidValue", "email address is invalid")
+	}
+
+	user.Username = strings.TrimSpace(resource.UserName)
+	user.Email = strings.TrimSpace(email)
+	user.FirstName = resource.Name.GivenName
+	user.LastName = resource.Name.FamilyName
+
+	user.Phone = nil
+	if value := scim.PrimaryValue(resource.PhoneNumbers); value != "" {
+		// IdP sering mengirim nomor dengan spasi / tanda hubung; simpan dalam bentuk E.164
+		phone := identity.Phone(value)
+		if scimValidate.Var(phone, "e164") != nil {
+			return scim.NewError(http.StatusBadRequest, "invalidValue", "phone number must be in E.164 format")
+		}
+		user.Phone = &phone
+	}
+
+	user.ExternalID = nil
+	if resource.ExternalID != "" {
+		externalID := resource.ExternalID
+		user.ExternalID = &externalID
+	}
+
+	if resource.Active != nil {
+		user.IsActive = *resource.Active
+	}
+
+	if resource.Password != "" {
+		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(resource.Password), bcrypt.DefaultCost)
+		if err != nil {
+			return err
+		}
+		user.Password = string(hashedPassword)
+	}
+	return nil
+}
+
+func randomPassword() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(hex.EncodeToString(buf)), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hashedPassword), nil
+}
+
+func (u *ScimUseCase) checkConflict(user *domain.User) error {
+	field, err := u.userRepo.FindConflict(user)
+	if err != nil {
+		return err
+	}
+	if field != "" {
+		return scim.NewError(http.StatusConflict, "uniqueness", field+" is already in use")
+	}
+	return nil
+}
+
+func (u *ScimUseCase) List(tenant string, filter string, startIndex int, count int) ([]domain.User, int64, error) {
+	var where string
+	var args []interface{}
+
+	if filter != "" {
+		parsed, err := scim.ParseFilter(filter)
+		if err != nil {
+			return nil, 0, scim.NewError(http.StatusBadRequest, "invalidFilter", err.Error())
+		}
+		where, args, err = scim.ToSQL(parsed, repository.ScimColumns)
+		if err != nil {
+			return nil, 0, scim.NewError(http.StatusBadRequest, "invalidFilter", err.Error())
+		}
+	}
+
+	return u.userRepo.ScimSearch(tenant, where, args, startIndex-1, count)
+}
+
+func (u *ScimUseCase) Get(tenant string, id string) (*domain.User, error) {
+	user, err := u.userRepo.FindByTenantAndUUID(tenant, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, scim.NewError(http.StatusNotFound, "", "User "+id+" not found")
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+func (u *ScimUseCase) Create(tenant string, resource *scim.User) (*domain.User, error) {
+	user := &domain.User{IsActive: true, ProvisioningTenant: &tenant}
+	if err := applyScimUser(user, resource); err != nil {
+		return nil, err
+	}
+
+	if user.Password == "" {
+		// User dari IdP login lewat SSO, password lokal dibuat acak
+		password, err := randomPassword()
+		if err != nil {
+			return nil, err
+		}
+		user.Password = password
+	}
+
+	if err := u.checkConflict(user); err != nil {
+		return nil, err
+	}
+
+	if err := u.userRepo.Create(user); err != nil {
+		return nil, u.translateConflict(err)
+	}
+	return user, nil
+}
+
+// Replace dan Patch menerima version dari If-Match (0 berarti tidak dicek; header itu opsional di SCIM)
+func (u *ScimUseCase) Replace(tenant string, id string, resource *scim.User, version uint) (*domain.User, error) {
+	user, err := u.Get(tenant, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVersion(user.Version, version); err != nil {
+		return nil, err
+	}
+
+	if err := applyScimUser(user, resource); err != nil {
+		return nil, err
+	}
+	return user, u.save(user, version)
+}
+
+func (u *ScimUseCase) Patch(tenant string, id string, ops []scim.PatchOperation, version uint) (*domain.User, error) {
+	user, err := u.Get(tenant, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVersion(user.Version, version); err != nil {
+		return nil, err
+	}
+
+	resource := ToScimUser(*user)
+	if err := scim.ApplyPatch(&resource, ops); err != nil {
+		return nil, scim.NewError(http.StatusBadRequest, "invalidPath", err.Error())
+	}
+
+	if err := applyScimUser(user, &resource); err != nil {
+		return nil, err
+	}
+	return user, u.save(user, version)
+}
+
+func (u *ScimUseCase) save(user *domain.User, version uint) error {
+	if err := u.checkConflict(user); err != nil {
+		return err
+	}
+	return staleAs(u.translateConflict(u.userRepo.Update(user)), version)
+}
+
+// translateConflict mengubah pelanggaran unique index (insert bersamaan) menjadi error uniqueness SCIM.
+// ErrStaleVersion juga Conflict tetapi bukan soal keunikan, jadi dibiarkan ke scimErrorResponse
+func (u *ScimUseCase) translateConflict(err error) error {
+	var conflict *domain.Error
+	if errors.As(err, &conflict) && errors.Is(err, domain.ErrConflict) && !errors.Is(err, domain.ErrStaleVersion) {
+		return scim.NewError(http.StatusConflict, "uniqueness", conflict.Message)
+	}
+	return err
+}
+
+func (u *ScimUseCase) Delete(tenant string, id string) (*domain.User, error) {
+	user, err := u.Get(tenant, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.userRepo.Delete(user.ID, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
@@ -0,0 +1,77 @@
+package usecase
+
+import (
+	"codebase-api/pkg/scim"
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+)
+
+func scimResource(userName string, email string) *scim.User {
+	return &scim.User{
+		UserName: userName,
+		Name:     scim.Name{GivenName: "Test", FamilyName: "User"},
+		Emails:   []scim.MultiValue{{Value: email, Primary: true}},
+	}
+}
+
+func TestScimCreateValidatesIdentity(t *testing.T) {
+	tests := []struct {
+		name     string
+		resource *scim.User
+	}{
+		{name: "reserved username", resource: scimResource("Admin", "admin@example.com")},
+		{name: "erased prefix", resource: scimResource("erased-42", "erased@example.com")},
+		{name: "invalid email", resource: scimResource("budi", "not-an-email")},
+		{name: "invalid phone", resource: func() *scim.User {
+			resource := scimResource("budi", "budi@example.com")
+			resource.PhoneNumbers = []scim.MultiValue{{Value: "call me", Primary: true}}
+			return resource
+		}()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scimUseCase := NewScimUseCase(newUserRepo(t))
+			_, err := scimUseCase.Create("acme", tt.resource)
+			var scimErr *scim.Error
+			if !errors.As(err, &scimErr) || scimErr.Status != strconv.Itoa(http.StatusBadRequest) || scimErr.ScimType != "invalidValue" {
+				t.Fatalf("err = %#v, want 400 invalidValue", err)
+			}
+		})
+	}
+}
+
+func TestScimCreateStoresPhoneInE164(t *testing.T) {
+	scimUseCase := NewScimUseCase(newUserRepo(t))
+	resource := scimResource("budi", "budi@example.com")
+	resource.PhoneNumbers = []scim.MultiValue{{Value: "+62 811-1111-111", Primary: true}}
+
+	user, err := scimUseCase.Create("acme", resource)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Phone == nil || *user.Phone != "+628111111111" {
+		t.Fatalf("phone = %v", user.Phone)
+	}
+}
+
+func TestScimReplaceKeepsExistingReservedUsername(t *testing.T) {
+	userRepo := newUserRepo(t)
+	scimUseCase := NewScimUseCase(userRepo)
+	user, err := scimUseCase.Create("acme", scimResource("budi", "budi@example.com"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Akun lama yang sudah bernama "support" sebelum nama itu masuk daftar reserved
+	if err := userRepo.DB.Model(user).UpdateColumns(map[string]interface{}{"username": "support", "username_canonical": "support"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if _, err := scimUseCase.Replace("acme", user.UUID.String(), scimResource("support", "budi@example.com"), 0); err != nil {
+		t.Fatalf("replace keeping the reserved username: %v", err)
+	}
+	if _, err := scimUseCase.Replace("acme", user.UUID.String(), scimResource("root", "budi@example.com"), 0); err == nil {
+		t.Fatal("rename to another reserved username was accepted")
+	}
+}
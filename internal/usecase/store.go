@@ -0,0 +1,29 @@
+package usecase
+
+import "time"
+
+// KeyValueStore adalah penyimpanan sementara (mis. Redis) untuk state yang berumur pendek
+type KeyValueStore interface {
+	Get(key string) ([]byte, error)
+	Set(key string, val []byte, exp time.Duration) error
+	Delete(key string) error
+}
+
+// CounterStore menambah operasi atomik (INCR, SET NX) pada KeyValueStore. Dipakai untuk counter
+// rate limit dan penanda sekali pakai yang harus tetap benar saat API berjalan di banyak instance
+type CounterStore interface {
+	KeyValueStore
+	// Incr menambah counter sebesar delta dan mengembalikan nilai barunya. TTL exp dipasang saat
+	// key dibuat dan tidak diperpanjang oleh Incr berikutnya (jendela tetap)
+	Incr(key string, delta int64, exp time.Duration) (int64, error)
+	// SetNX menyimpan val hanya jika key belum ada; ok bernilai false jika key sudah ada
+	SetNX(key string, val []byte, exp time.Duration) (ok bool, err error)
+	// TTL mengembalikan sisa umur key, 0 jika key tidak ada
+	TTL(key string) (time.Duration, error)
+}
+
+// FileStore menyimpan file hasil upload; key adalah path relatif yang juga dipakai di URL publik
+type FileStore interface {
+	Put(key string, data []byte) error
+	Delete(key string) error
+}
@@ -0,0 +1,147 @@
+package usecase
+
+import (
+	"codebase-api/internal/domain"
+	"codebase-api/internal/repository"
+	"codebase-api/pkg/totp"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+const (
+	// totpSkew menerima kode dari satu periode sebelum / sesudah periode saat ini
+	totpSkew        = 1
+	totpMaxFailures = 5
+	totpLockout     = 5 * time.Minute
+)
+
+var (
+	ErrTOTPNotEnrolled    = domain.Invalid("totp", "two-factor authentication has not been set up")
+	ErrTOTPNotEnabled     = domain.Invalid("totp", "two-factor authentication is not enabled")
+	ErrTOTPAlreadyEnabled = domain.Conflict("totp", "two-factor authentication is already enabled")
+	ErrTOTPInvalid        = domain.Invalid("code", "verification code is invalid")
+	ErrTOTPLocked         = domain.Forbidden("too many invalid verification codes, try again later")
+)
+
+// TOTPUseCase mengelola faktor TOTP (aplikasi authenticator) milik user
+type TOTPUseCase struct {
+	userRepo *repository.UserRepository
+	store    CounterStore
+	issuer   string
+}
+
+func NewTOTPUseCase(userRepo *repository.UserRepository, store CounterStore, issuer string) *TOTPUseCase {
+	return &TOTPUseCase{userRepo: userRepo, store: store, issuer: issuer}
+}
+
+// Enroll membuat secret baru yang belum aktif sampai dikonfirmasi lewat Confirm.
+// Memanggil Enroll lagi sebelum konfirmasi mengganti secret sebelumnya
+func (u *TOTPUseCase) Enroll(user *domain.User) (secret string, uri string, err error) {
+	fresh, err := u.userRepo.FindByID(user.ID)
+	if err != nil {
+		return "", "", notFoundAs(err, ErrUserNotFound)
+	}
+	if fresh.TOTPEnabledAt != nil {
+		return "", "", ErrTOTPAlreadyEnabled
+	}
+
+	secret, err = totp.GenerateSecret()
+	if err != nil {
+		return "", "", err
+	}
+	fresh.TOTPSecret = &secret
+	if err := u.userRepo.Update(fresh); err != nil {
+		return "", "", err
+	}
+	return secret, totp.URI(u.issuer, fresh.Username, secret), nil
+}
+
+// Confirm mengaktifkan TOTP setelah user membuktikan authenticator-nya menghasilkan kode yang benar
+func (u *TOTPUseCase) Confirm(user *domain.User, code string) (*domain.User, error) {
+	fresh, err := u.userRepo.FindByID(user.ID)
+	if err != nil {
+		return nil, notFoundAs(err, ErrUserNotFound)
+	}
+	if fresh.TOTPEnabledAt != nil {
+		return nil, ErrTOTPAlreadyEnabled
+	}
+	if fresh.TOTPSecret == nil {
+		return nil, ErrTOTPNotEnrolled
+	}
+	if err := u.check(fresh.ID, *fresh.TOTPSecret, code); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	fresh.TOTPEnabledAt = &now
+	if err := u.userRepo.Update(fresh); err != nil {
+		return nil, err
+	}
+	return fresh, nil
+}
+
+// Disable menghapus faktor TOTP; route-nya wajib RequireRecentAuth
+func (u *TOTPUseCase) Disable(user *domain.User) (*domain.User, error) {
+	fresh, err := u.userRepo.FindByID(user.ID)
+	if err != nil {
+		return nil, notFoundAs(err, ErrUserNotFound)
+	}
+	if fresh.TOTPSecret == nil {
+		return nil, ErrTOTPNotEnabled
+	}
+
+	fresh.TOTPSecret = nil
+	fresh.TOTPEnabledAt = nil
+	if err := u.userRepo.Update(fresh); err != nil {
+		return nil, err
+	}
+	return fresh, nil
+}
+
+// Verify mencocokkan kode dari authenticator user yang TOTP-nya sudah aktif (dipakai reauthenticate)
+func (u *TOTPUseCase) Verify(user *domain.User, code string) error {
+	if user.TOTPEnabledAt == nil || user.TOTPSecret == nil {
+		return ErrTOTPNotEnabled
+	}
+	return u.check(user.ID, *user.TOTPSecret, code)
+}
+
+// check menolak kode yang salah, kode yang sudah pernah dipakai (replay) dan user yang terlalu sering salah.
+// Percobaan dihitung dengan Incr sebelum kode dicek dan step dipakai lewat SetNX, keduanya atomik
+// sehingga tebakan atau replay paralel ke instance mana pun tetap dibatasi
+func (u *TOTPUseCase) check(userID uint, secret string, code string) error {
+	failuresKey := fmt.Sprintf("totp:failures:%d", userID)
+	attempts, err := u.store.Incr(failuresKey, 1, totpLockout)
+	if err != nil {
+		return err
+	}
+	if attempts > totpMaxFailures {
+		return ErrTOTPLocked
+	}
+
+	usedKey := fmt.Sprintf("totp:used:%d", userID)
+	var lastUsed uint64
+	if data, err := u.store.Get(usedKey); err == nil && len(data) > 0 {
+		lastUsed, _ = strconv.ParseUint(string(data), 10, 64)
+	}
+
+	step, ok := totp.Validate(secret, code, time.Now(), totpSkew)
+	if !ok || step <= lastUsed {
+		return ErrTOTPInvalid
+	}
+
+	// Step disimpan sampai seluruh jendela skew lewat agar kode yang sama tidak bisa dipakai dua kali
+	window := totp.Period * (2*totpSkew + 2)
+	claimed, err := u.store.SetNX(fmt.Sprintf("totp:used:%d:%d", userID, step), []byte("1"), window)
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		return ErrTOTPInvalid
+	}
+
+	_ = u.store.Delete(failuresKey)
+	// usedKey menolak kode dari step yang lebih lama dari kode terakhir yang dipakai
+	return u.store.Set(usedKey, []byte(strconv.FormatUint(step, 10)), window)
+}
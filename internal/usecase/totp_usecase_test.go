@@ -0,0 +1,157 @@
+package usecase
+
+import (
+	"codebase-api/internal/domain"
+	"codebase-api/pkg/totp"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+type totpFixture struct {
+	usecase *TOTPUseCase
+	store   *memoryStore
+	user    *domain.User
+}
+
+// newTOTPFixture membuat user dengan TOTP yang sudah aktif
+func newTOTPFixture(t *testing.T) *totpFixture {
+	t.Helper()
+
+	userRepo := newUserRepo(t)
+	user := createUser(t, userRepo, "budi")
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	user.TOTPSecret, user.TOTPEnabledAt = &secret, &now
+	if err := userRepo.Update(user); err != nil {
+		t.Fatal(err)
+	}
+
+	store := newMemoryStore()
+	return &totpFixture{usecase: NewTOTPUseCase(userRepo, store, "codebase-api"), store: store, user: user}
+}
+
+func (f *totpFixture) code(t *testing.T, step uint64) string {
+	t.Helper()
+	code, err := totp.Code(*f.user.TOTPSecret, step)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return code
+}
+
+func TestTOTPRejectsReplay(t *testing.T) {
+	f := newTOTPFixture(t)
+	step := totp.Step(time.Now())
+
+	if err := f.usecase.Verify(f.user, f.code(t, step)); err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if err := f.usecase.Verify(f.user, f.code(t, step)); !errors.Is(err, ErrTOTPInvalid) {
+		t.Fatalf("replayed code: err = %v, want ErrTOTPInvalid", err)
+	}
+	// Kode dari step sebelumnya masih dalam skew, tapi lebih lama dari kode yang sudah dipakai
+	if err := f.usecase.Verify(f.user, f.code(t, step-1)); !errors.Is(err, ErrTOTPInvalid) {
+		t.Fatalf("older code: err = %v, want ErrTOTPInvalid", err)
+	}
+}
+
+func TestTOTPReplayHoldsUnderConcurrency(t *testing.T) {
+	f := newTOTPFixture(t)
+	f.usecase.store = slowStore{f.store}
+	code := f.code(t, totp.Step(time.Now()))
+
+	const requests = 20
+	results := make(chan error, requests)
+	var wg sync.WaitGroup
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results <- f.usecase.Verify(f.user, code)
+		}()
+	}
+	wg.Wait()
+	close(results)
+
+	accepted := 0
+	for err := range results {
+		if err == nil {
+			accepted++
+		}
+	}
+	if accepted != 1 {
+		t.Fatalf("code accepted %d times, want 1", accepted)
+	}
+}
+
+func TestTOTPLocksAfterFailures(t *testing.T) {
+	f := newTOTPFixture(t)
+	code := f.code(t, totp.Step(time.Now()))
+	wrong := wrongCode(code)
+
+	for i := 0; i < totpMaxFailures; i++ {
+		if err := f.usecase.Verify(f.user, wrong); !errors.Is(err, ErrTOTPInvalid) {
+			t.Fatalf("attempt %d: err = %v, want ErrTOTPInvalid", i+1, err)
+		}
+	}
+	if err := f.usecase.Verify(f.user, code); !errors.Is(err, ErrTOTPLocked) {
+		t.Fatalf("correct code while locked: err = %v, want ErrTOTPLocked", err)
+	}
+
+	f.store.expire(fmt.Sprintf("totp:failures:%d", f.user.ID))
+	if err := f.usecase.Verify(f.user, code); err != nil {
+		t.Fatalf("verify after lockout: %v", err)
+	}
+}
+
+func TestTOTPLockoutHoldsUnderConcurrency(t *testing.T) {
+	f := newTOTPFixture(t)
+	f.usecase.store = slowStore{f.store}
+	wrong := wrongCode(f.code(t, totp.Step(time.Now())))
+
+	const guesses = 50
+	results := make(chan error, guesses)
+	var wg sync.WaitGroup
+	for i := 0; i < guesses; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results <- f.usecase.Verify(f.user, wrong)
+		}()
+	}
+	wg.Wait()
+	close(results)
+
+	counts := map[error]int{}
+	for err := range results {
+		counts[err]++
+	}
+	if counts[ErrTOTPInvalid] != totpMaxFailures || counts[ErrTOTPLocked] != guesses-totpMaxFailures {
+		t.Fatalf("parallel guesses were not limited: %v", counts)
+	}
+}
+
+func TestTOTPSuccessResetsFailures(t *testing.T) {
+	f := newTOTPFixture(t)
+	step := totp.Step(time.Now())
+
+	for i := 1; i < totpMaxFailures; i++ {
+		if err := f.usecase.Verify(f.user, wrongCode(f.code(t, step))); !errors.Is(err, ErrTOTPInvalid) {
+			t.Fatalf("attempt %d: err = %v, want ErrTOTPInvalid", i, err)
+		}
+	}
+	if err := f.usecase.Verify(f.user, f.code(t, step)); err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	for i := 1; i < totpMaxFailures; i++ {
+		if err := f.usecase.Verify(f.user, wrongCode(f.code(t, step+1))); !errors.Is(err, ErrTOTPInvalid) {
+			t.Fatalf("attempt %d after success: err = %v, want ErrTOTPInvalid", i, err)
+		}
+	}
+}
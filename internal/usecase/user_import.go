@@ -0,0 +1,187 @@
+package usecase
+
+import (
+	"codebase-api/internal/domain"
+	"codebase-api/pkg/identity"
+	"context"
+	"errors"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ImportMode menentukan perilaku saat sebagian baris import tidak valid
+type ImportMode string
+
+const (
+	// ImportModeAtomic: satu baris gagal berarti tidak ada yang disimpan
+	ImportModeAtomic ImportMode = "atomic"
+	// ImportModeSkip: baris yang gagal dilewati, baris valid tetap disimpan
+	ImportModeSkip ImportMode = "skip"
+)
+
+const (
+	ImportStatusCreated = "created"
+	ImportStatusValid   = "valid"
+	ImportStatusInvalid = "invalid"
+	ImportStatusSkipped = "skipped"
+)
+
+const importBatchSize = 100
+
+// ImportRow adalah satu baris file import; Errors diisi oleh validasi input di handler
+type ImportRow struct {
+	Line   int
+	User   *domain.User
+	Errors map[string]string
+}
+
+type ImportRowResult struct {
+	Line     int               `json:"line"`
+	Username string            `json:"username"`
+	Email    string            `json:"email"`
+	Status   string            `json:"status"`
+	Errors   map[string]string `json:"errors,omitempty"`
+}
+
+type ImportReport struct {
+	Mode    ImportMode `json:"mode"`
+	DryRun  bool       `json:"dry_run"`
+	Total   int        `json:"total"`
+	Created int        `json:"created"`
+	Invalid int        `json:"invalid"`
+	// Skipped menghitung baris valid yang bentrok dengan user yang dibuat request lain saat import berjalan
+	Skipped int               `json:"skipped"`
+	Rows    []ImportRowResult `json:"-"`
+}
+
+// Import memeriksa duplikasi di dalam file dan terhadap database, lalu menyimpan baris valid per batch
+func (u *UserUseCase) Import(ctx context.Context, rows []ImportRow, mode ImportMode, dryRun bool) (*ImportReport, error) {
+	report := &ImportReport{Mode: mode, DryRun: dryRun, Total: len(rows)}
+	seen := map[string]map[string]int{"username": {}, "email": {}, "phone": {}}
+
+	var valid []int
+	for i, row := range rows {
+		result := ImportRowResult{Line: row.Line, Status: ImportStatusValid, Errors: row.Errors}
+		if row.User != nil {
+			result.Username, result.Email = row.User.Username, row.User.Email
+		}
+
+		if len(result.Errors) == 0 {
+			result.Errors = u.importConflicts(row.User, seen)
+		}
+		if len(result.Errors) > 0 {
+			result.Status = ImportStatusInvalid
+			report.Invalid++
+		} else {
+			valid = append(valid, i)
+		}
+		report.Rows = append(report.Rows, result)
+
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	if mode == ImportModeAtomic && report.Invalid > 0 {
+		for _, i := range valid {
+			report.Rows[i].Status = ImportStatusSkipped
+		}
+		return report, nil
+	}
+	if dryRun || len(valid) == 0 {
+		return report, nil
+	}
+
+	users := make([]domain.User, 0, len(valid))
+	for _, i := range valid {
+		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(rows[i].User.Password), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, err
+		}
+		user := *rows[i].User
+		user.Password = string(hashedPassword)
+		users = append(users, user)
+
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := u.userRepo.CreateInBatches(users, importBatchSize); err != nil {
+		// Baris bisa bentrok dengan user yang dibuat request lain setelah pengecekan di atas. Mode atomic
+		// membatalkan semuanya, mode skip menyimpan ulang per baris dan melewati baris yang bentrok
+		if mode == ImportModeAtomic || !errors.Is(err, domain.ErrConflict) {
+			return nil, err
+		}
+		return report, u.importEach(ctx, users, valid, report)
+	}
+	for _, i := range valid {
+		report.Rows[i].Status = ImportStatusCreated
+	}
+	report.Created = len(valid)
+	return report, nil
+}
+
+// importEach menyimpan user satu per satu setelah batch gagal karena unique index
+func (u *UserUseCase) importEach(ctx context.Context, users []domain.User, valid []int, report *ImportReport) error {
+	for n, i := range valid {
+		user := users[n]
+		// ID dari batch yang di-rollback tidak ikut dipakai
+		user.ID = 0
+
+		err := u.userRepo.Create(&user)
+		var domainErr *domain.Error
+		switch {
+		case err == nil:
+			report.Rows[i].Status = ImportStatusCreated
+			report.Created++
+		case errors.As(err, &domainErr) && errors.Is(err, domain.ErrConflict):
+			report.Rows[i].Status = ImportStatusSkipped
+			report.Rows[i].Errors = domainErr.Fields
+			if len(domainErr.Fields) == 0 {
+				report.Rows[i].Errors = map[string]string{"row": domainErr.Message}
+			}
+			report.Skipped++
+		default:
+			return err
+		}
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// importConflicts mengembalikan field yang sudah dipakai baris sebelumnya atau user di database
+func (u *UserUseCase) importConflicts(user *domain.User, seen map[string]map[string]int) map[string]string {
+	values := map[string]string{"username": identity.Username(user.Username), "email": identity.Email(user.Email)}
+	if user.Phone != nil {
+		values["phone"] = identity.Phone(*user.Phone)
+	}
+
+	errs := map[string]string{}
+	if identity.IsReservedUsername(user.Username) {
+		errs["username"] = ErrReservedUsername.Error()
+		return errs
+	}
+	for field, value := range values {
+		if _, ok := seen[field][value]; ok {
+			errs[field] = "Duplicate " + field + " in import file"
+		}
+	}
+	if len(errs) == 0 {
+		if field, err := u.userRepo.FindConflict(user); err != nil {
+			errs["row"] = "Could not check uniqueness"
+		} else if field != "" {
+			errs[field] = field + " is already in use"
+		}
+	}
+
+	if len(errs) == 0 {
+		for field, value := range values {
+			seen[field][value]++
+		}
+	}
+	return errs
+}
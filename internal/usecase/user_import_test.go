@@ -0,0 +1,174 @@
+package usecase
+
+import (
+	"codebase-api/internal/domain"
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func importRow(line int, username string) ImportRow {
+	return ImportRow{Line: line, User: &domain.User{
+		FirstName: "Test",
+		LastName:  "User",
+		Username:  username,
+		Email:     username + "@example.com",
+		Password:  "secret123",
+	}}
+}
+
+func importStatuses(report *ImportReport) []string {
+	statuses := make([]string, len(report.Rows))
+	for i, row := range report.Rows {
+		statuses[i] = row.Status
+	}
+	return statuses
+}
+
+func countUsers(t *testing.T, users *UserUseCase) int64 {
+	t.Helper()
+	var count int64
+	if err := users.userRepo.DB.Model(&domain.User{}).Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	return count
+}
+
+func TestImportAtomicModeSavesNothingWhenARowIsInvalid(t *testing.T) {
+	userRepo := newUserRepo(t)
+	users := NewUserUseCase(userRepo, NewLocalAuthenticator(userRepo))
+
+	rows := []ImportRow{
+		importRow(2, "budi"),
+		{Line: 3, Errors: map[string]string{"email": "email must be a valid email"}},
+		importRow(4, "siti"),
+	}
+	report, err := users.Import(context.Background(), rows, ImportModeAtomic, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{ImportStatusSkipped, ImportStatusInvalid, ImportStatusSkipped}
+	if got := importStatuses(report); !slices.Equal(got, want) {
+		t.Fatalf("statuses = %v, want %v", got, want)
+	}
+	if report.Created != 0 || report.Invalid != 1 || countUsers(t, users) != 0 {
+		t.Fatalf("created = %d, invalid = %d, users = %d", report.Created, report.Invalid, countUsers(t, users))
+	}
+}
+
+func TestImportSkipModeSavesValidRows(t *testing.T) {
+	userRepo := newUserRepo(t)
+	users := NewUserUseCase(userRepo, NewLocalAuthenticator(userRepo))
+	createUser(t, userRepo, "andi")
+
+	rows := []ImportRow{
+		importRow(2, "budi"),
+		importRow(3, "BUDI"),  // duplikat di dalam file
+		importRow(4, "andi"),  // sudah ada di database
+		importRow(5, "admin"), // username reserved
+		importRow(6, "siti"),
+	}
+	report, err := users.Import(context.Background(), rows, ImportModeSkip, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{ImportStatusCreated, ImportStatusInvalid, ImportStatusInvalid, ImportStatusInvalid, ImportStatusCreated}
+	if got := importStatuses(report); !slices.Equal(got, want) {
+		t.Fatalf("statuses = %v, want %v", got, want)
+	}
+	if !strings.Contains(report.Rows[1].Errors["username"], "Duplicate") || report.Rows[2].Errors["username"] != "username is already in use" {
+		t.Fatalf("errors = %v / %v", report.Rows[1].Errors, report.Rows[2].Errors)
+	}
+	if _, ok := report.Rows[3].Errors["username"]; !ok {
+		t.Fatalf("reserved username not reported: %v", report.Rows[3].Errors)
+	}
+	if report.Created != 2 || report.Invalid != 3 || countUsers(t, users) != 3 {
+		t.Fatalf("created = %d, invalid = %d, users = %d", report.Created, report.Invalid, countUsers(t, users))
+	}
+
+	saved, err := userRepo.GetUserByUsername("siti")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Password == "secret123" {
+		t.Fatal("password stored in plain text")
+	}
+}
+
+func TestImportDryRunSavesNothing(t *testing.T) {
+	userRepo := newUserRepo(t)
+	users := NewUserUseCase(userRepo, NewLocalAuthenticator(userRepo))
+
+	report, err := users.Import(context.Background(), []ImportRow{importRow(2, "budi")}, ImportModeAtomic, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := importStatuses(report); !slices.Equal(got, []string{ImportStatusValid}) || countUsers(t, users) != 0 {
+		t.Fatalf("statuses = %v, users = %d", got, countUsers(t, users))
+	}
+}
+
+// insertAfterCheck membuat user dengan username yang sama tepat setelah import memeriksa
+// keunikannya, seperti request lain yang mendaftar di antara pengecekan dan insert
+func insertAfterCheck(t *testing.T, db *gorm.DB, username string) {
+	t.Helper()
+
+	fired := false
+	err := db.Callback().Query().After("gorm:query").Register("test:race_"+username, func(tx *gorm.DB) {
+		if fired || tx.Statement.Table != "users" || !slices.Contains(tx.Statement.Vars, interface{}(username)) {
+			return
+		}
+		fired = true
+		racer := &domain.User{FirstName: "Race", LastName: "User", Username: username, Email: username + "@race.example.com", Password: "x", Role: domain.RoleUser}
+		if err := tx.Session(&gorm.Session{NewDB: true}).Create(racer).Error; err != nil {
+			t.Errorf("insert racing user: %v", err)
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestImportSkipModeFallsBackToSingleInsertsOnRace(t *testing.T) {
+	userRepo := newUserRepo(t)
+	users := NewUserUseCase(userRepo, NewLocalAuthenticator(userRepo))
+	insertAfterCheck(t, userRepo.DB, "siti")
+
+	rows := []ImportRow{importRow(2, "budi"), importRow(3, "siti"), importRow(4, "andi")}
+	report, err := users.Import(context.Background(), rows, ImportModeSkip, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{ImportStatusCreated, ImportStatusSkipped, ImportStatusCreated}
+	if got := importStatuses(report); !slices.Equal(got, want) {
+		t.Fatalf("statuses = %v, want %v", got, want)
+	}
+	if len(report.Rows[1].Errors) == 0 {
+		t.Fatal("skipped row has no error")
+	}
+	if report.Created != 2 || report.Skipped != 1 || countUsers(t, users) != 3 {
+		t.Fatalf("created = %d, skipped = %d, users = %d", report.Created, report.Skipped, countUsers(t, users))
+	}
+}
+
+func TestImportAtomicModeFailsOnRace(t *testing.T) {
+	userRepo := newUserRepo(t)
+	users := NewUserUseCase(userRepo, NewLocalAuthenticator(userRepo))
+	insertAfterCheck(t, userRepo.DB, "siti")
+
+	rows := []ImportRow{importRow(2, "budi"), importRow(3, "siti")}
+	if _, err := users.Import(context.Background(), rows, ImportModeAtomic, false); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("err = %v, want a conflict", err)
+	}
+	// Hanya user dari request lain yang tersimpan
+	if count := countUsers(t, users); count != 1 {
+		t.Fatalf("users = %d, want 1", count)
+	}
+}
@@ -3,73 +3,292 @@ package usecase
 import (
 	"codebase-api/internal/domain"
 	"codebase-api/internal/repository"
+	"codebase-api/pkg/identity"
+	"codebase-api/pkg/query"
+	"context"
 	"errors"
-	"fmt"
 
+	"github.com/sirupsen/logrus"
 	"golang.org/x/crypto/bcrypt"
 )
 
+var (
+	ErrUserNotFound       = domain.NotFound("user not found")
+	ErrReservedUsername   = domain.Invalid("username", "username is reserved")
+	ErrInvalidCredentials = domain.Unauthorized("invalid credentials")
+	ErrUserInactive       = domain.Forbidden("user is inactive")
+)
+
+const exportBatchSize = 500
+
+// UserUpdate berisi field profil yang boleh diubah; nil berarti tidak diubah.
+// Password dan RefreshToken sengaja tidak ada di sini.
+type UserUpdate struct {
+	FirstName *string
+	LastName  *string
+	Username  *string
+	Email     *string
+	Phone     *string
+	// Version adalah version yang dilihat client (If-Match); 0 berarti tidak dicek
+	Version uint
+}
+
 type UserUseCase struct {
-	userRepo *repository.UserRepository
+	userRepo       *repository.UserRepository
+	authenticators []Authenticator
+	preferences    *PreferenceUseCase
 }
 
-func NewUserUseCase(userRepo *repository.UserRepository) *UserUseCase {
-	return &UserUseCase{userRepo: userRepo}
+// NewUserUseCase membuat use case user; tanpa authenticator, login hanya memakai password lokal
+func NewUserUseCase(userRepo *repository.UserRepository, authenticators ...Authenticator) *UserUseCase {
+	if len(authenticators) == 0 {
+		authenticators = []Authenticator{NewLocalAuthenticator(userRepo)}
+	}
+	return &UserUseCase{userRepo: userRepo, authenticators: authenticators}
+}
+
+// WithPreferences memasang cache preferensi yang harus dibersihkan saat user di-purge
+func (u *UserUseCase) WithPreferences(preferences *PreferenceUseCase) *UserUseCase {
+	u.preferences = preferences
+	return u
 }
 
 func (u *UserUseCase) Register(user *domain.User) error {
+	if identity.IsReservedUsername(user.Username) {
+		return ErrReservedUsername
+	}
+	if err := u.checkConflict(user); err != nil {
+		return err
+	}
+
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
 	if err != nil {
 		return err
 	}
 	user.Password = string(hashedPassword)
+	// Insert yang kalah balapan setelah checkConflict tetap ditolak unique index sebagai domain.ErrConflict
 	return u.userRepo.Create(user)
 }
 
-func (u *UserUseCase) Login(username, password string) (*domain.User, error) {
-	user, err := u.userRepo.GetUserByUsername(username)
+// checkConflict mengembalikan domain.ErrConflict bila username, email atau phone sudah dipakai user lain
+func (u *UserUseCase) checkConflict(user *domain.User) error {
+	field, err := u.userRepo.FindConflict(user)
 	if err != nil {
-		fmt.Println(err)
-		return nil, errors.New("user not found")
+		return err
 	}
+	if field != "" {
+		return domain.FieldConflict(field)
+	}
+	return nil
+}
 
-	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password))
+// authenticate mencoba setiap authenticator secara berurutan sampai ada yang menangani user tersebut
+func (u *UserUseCase) authenticate(username, password string) (*domain.User, error) {
+	for _, authenticator := range u.authenticators {
+		user, err := authenticator.Authenticate(username, password)
+		if errors.Is(err, ErrAuthenticatorSkip) {
+			continue
+		}
+		if errors.Is(err, ErrInvalidCredentials) {
+			logrus.WithField("authenticator", authenticator.Name()).Debug("invalid credentials")
+			return nil, ErrInvalidCredentials
+		}
+		if err != nil {
+			// Error backend (database / directory down, entry tidak lengkap) bukan kesalahan kredensial
+			logrus.WithError(err).WithField("authenticator", authenticator.Name()).Warn("authentication failed")
+			return nil, err
+		}
+		return user, nil
+	}
+
+	return nil, ErrInvalidCredentials
+}
+
+func (u *UserUseCase) Login(username, password string) (*domain.User, error) {
+	user, err := u.authenticate(username, password)
 	if err != nil {
-		fmt.Println(err)
-		return nil, errors.New("invalid credentials")
+		return nil, err
+	}
+
+	// User yang dinonaktifkan (mis. lewat SCIM) tidak boleh login
+	if !user.IsActive {
+		return nil, ErrUserInactive
 	}
 	return user, nil
 }
 
-func (u *UserUseCase) FinAll() ([]domain.User, error) {
-	users, err := u.userRepo.FindAll()
+// VerifyPassword dipakai untuk step-up reauthentication pada operasi sensitif. Memakai chain yang sama
+// dengan Login agar user LDAP diverifikasi ke directory, bukan ke password lokal acaknya
+func (u *UserUseCase) VerifyPassword(user *domain.User, password string) error {
+	verified, err := u.authenticate(user.Username, password)
 	if err != nil {
-		return nil, errors.New("users not found")
+		return err
+	}
+	if verified.ID != user.ID {
+		return ErrInvalidCredentials
 	}
-	return users, nil
+	return nil
+}
+
+func (u *UserUseCase) FinAll() ([]domain.User, error) {
+	return u.userRepo.FindAll()
+}
+
+// Stamp dan StampMatching dipakai untuk conditional GET sebelum list benar-benar dimuat
+func (u *UserUseCase) Stamp() (repository.CollectionStamp, error) {
+	return u.userRepo.StampAll()
 }
 
-func (u *UserUseCase) Searching(isActive *bool, search string) ([]domain.User, error) {
-	users, err := u.userRepo.Searching(isActive, search)
+func (u *UserUseCase) StampMatching(filter repository.UserFilter, q query.ListQuery) (repository.CollectionStamp, error) {
+	return u.userRepo.StampMatching(filter, q)
+}
+
+func (u *UserUseCase) Searching(filter repository.UserFilter, q query.ListQuery, req repository.PageRequest) (*repository.Page[domain.User], error) {
+	return u.userRepo.Searching(filter, q, req)
+}
+
+func (u *UserUseCase) CountMatching(filter repository.UserFilter, q query.ListQuery) (int64, error) {
+	return u.userRepo.CountMatching(filter, q)
+}
+
+// Export memanggil fn untuk setiap batch user yang cocok dengan filter; berhenti bila ctx dibatalkan
+func (u *UserUseCase) Export(ctx context.Context, filter repository.UserFilter, q query.ListQuery, fn func([]domain.User) error) error {
+	return u.userRepo.Export(filter, q, exportBatchSize, func(users []domain.User) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		return fn(users)
+	})
+}
+
+// ResolveID menerjemahkan ID publik user menjadi primary key
+func (u *UserUseCase) ResolveID(publicID string) (uint, error) {
+	id, err := resolvePublicID(u.userRepo, publicID)
 	if err != nil {
-		return nil, errors.New("users not found")
+		return 0, notFoundAs(err, ErrUserNotFound)
 	}
-	return users, nil
+	return id, nil
 }
 
 func (u *UserUseCase) FindById(id uint) (*domain.User, error) {
 	user, err := u.userRepo.FindByID(id)
 	if err != nil {
-		return nil, errors.New("user not found")
+		return nil, notFoundAs(err, ErrUserNotFound)
+	}
+	return user, nil
+}
+
+// FindProjected sama dengan FindById tetapi hanya memuat columns (nil berarti semua kolom),
+// dipakai untuk GET dengan ?fields=
+func (u *UserUseCase) FindProjected(id uint, columns []string) (*domain.User, error) {
+	user, err := u.userRepo.FindByIDSelect(id, columns)
+	if err != nil {
+		return nil, notFoundAs(err, ErrUserNotFound)
+	}
+	return user, nil
+}
+
+func (u *UserUseCase) Update(id uint, input UserUpdate) (*domain.User, error) {
+	user, err := u.FindById(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVersion(user.Version, input.Version); err != nil {
+		return nil, err
+	}
+
+	if input.FirstName != nil {
+		user.FirstName = *input.FirstName
+	}
+	if input.LastName != nil {
+		user.LastName = *input.LastName
+	}
+	if input.Username != nil {
+		// Username lama yang kebetulan masuk daftar reserved tetap boleh dipertahankan
+		if identity.Username(*input.Username) != user.UsernameCanonical && identity.IsReservedUsername(*input.Username) {
+			return nil, ErrReservedUsername
+		}
+		user.Username = *input.Username
+	}
+	// Email yang diganti langsung (oleh admin) belum terverifikasi
+	if input.Email != nil && identity.Email(*input.Email) != user.EmailCanonical {
+		user.Email = *input.Email
+		user.EmailVerifiedAt = nil
+	}
+	if input.Phone != nil {
+		previous := user.Phone
+		user.Phone = nil
+		if phone := *input.Phone; phone != "" {
+			user.Phone = &phone
+		}
+		// Nomor baru harus diverifikasi ulang
+		if previous == nil || user.Phone == nil || *previous != *user.Phone {
+			user.PhoneVerifiedAt = nil
+		}
+	}
+
+	if err := u.checkConflict(user); err != nil {
+		return nil, err
+	}
+
+	if err := u.userRepo.Update(user); err != nil {
+		return nil, staleAs(err, input.Version)
+	}
+	return user, nil
+}
+
+func (u *UserUseCase) SetActive(id uint, active bool) (*domain.User, error) {
+	user, err := u.FindById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	user.IsActive = active
+	if err := u.userRepo.Update(user); err != nil {
+		return nil, err
 	}
 	return user, nil
 }
 
 func (u *UserUseCase) Delete(id uint) error {
-	var user domain.User
-	err := u.userRepo.Delete(id, &user)
+	user, err := u.FindById(id)
+	if err != nil {
+		return err
+	}
+
+	return u.userRepo.Delete(id, user)
+}
+
+func (u *UserUseCase) Trashed() ([]domain.User, error) {
+	return u.userRepo.FindTrashed()
+}
+
+func (u *UserUseCase) Restore(id uint) (*domain.User, error) {
+	user, err := u.userRepo.FindTrashedByID(id)
 	if err != nil {
-		return errors.New("failed to delete user")
+		return nil, notFoundAs(err, ErrUserNotFound)
+	}
+
+	// Email / phone / username bisa saja sudah dipakai user lain selama berada di trash
+	if err := u.checkConflict(user); err != nil {
+		return nil, err
+	}
+
+	if err := u.userRepo.Restore(id); err != nil {
+		return nil, err
+	}
+	return u.FindById(id)
+}
+
+func (u *UserUseCase) Purge(id uint) error {
+	if _, err := u.userRepo.FindByIDWithTrashed(id); err != nil {
+		return notFoundAs(err, ErrUserNotFound)
+	}
+	if err := u.userRepo.Purge(id); err != nil {
+		return err
+	}
+	if u.preferences != nil {
+		u.preferences.Forget(id)
 	}
 	return nil
 }
@@ -0,0 +1,112 @@
+package usecase
+
+import (
+	"codebase-api/internal/domain"
+	"errors"
+	"testing"
+)
+
+func newRegisteredUser(username string, phone string) *domain.User {
+	return &domain.User{
+		FirstName: "Test",
+		LastName:  "User",
+		Username:  username,
+		Email:     username + "@example.com",
+		Password:  "secret123",
+		Phone:     &phone,
+		IsActive:  true,
+		Role:      domain.RoleUser,
+	}
+}
+
+func TestDeletedUserIdentityCanBeReusedButBlocksRestore(t *testing.T) {
+	userRepo := newUserRepo(t)
+	users := NewUserUseCase(userRepo)
+
+	original := newRegisteredUser("budi", "+628111111111")
+	if err := users.Register(original); err != nil {
+		t.Fatal(err)
+	}
+	if err := users.Delete(original.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := users.FindById(original.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("deleted user is still visible: err = %v", err)
+	}
+
+	// Username, email dan phone milik user di trash boleh dipakai akun baru
+	replacement := newRegisteredUser("BUDI", "+628111111111")
+	replacement.Email = "Budi@Example.com"
+	if err := users.Register(replacement); err != nil {
+		t.Fatalf("register with the identity of a deleted user: %v", err)
+	}
+
+	_, err := users.Restore(original.ID)
+	var domainErr *domain.Error
+	if !errors.As(err, &domainErr) || !errors.Is(err, domain.ErrConflict) || domainErr.Fields["username"] == "" {
+		t.Fatalf("restore: err = %v, want a username conflict", err)
+	}
+
+	trashed, err := users.Trashed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trashed) != 1 || trashed[0].ID != original.ID {
+		t.Fatalf("trash = %+v, want only the original user", trashed)
+	}
+}
+
+func TestRestoreBringsUserBack(t *testing.T) {
+	userRepo := newUserRepo(t)
+	users := NewUserUseCase(userRepo)
+	user := createUser(t, userRepo, "budi")
+
+	if err := users.Delete(user.ID); err != nil {
+		t.Fatal(err)
+	}
+	restored, err := users.Restore(user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.DeletedAt.Valid || restored.Version != user.Version+1 {
+		t.Fatalf("restored = %+v", restored.BaseDomain)
+	}
+
+	// User yang tidak ada di trash tidak bisa di-restore
+	if _, err := users.Restore(user.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("restore active user: err = %v, want not found", err)
+	}
+}
+
+func TestPurgeRemovesUserAndPasskeys(t *testing.T) {
+	userRepo := newUserRepo(t)
+	users := NewUserUseCase(userRepo)
+	user := createUser(t, userRepo, "budi")
+
+	passkey := &domain.Passkey{UserID: user.ID, Name: "Laptop", CredentialID: []byte("credential"), PublicKey: []byte("key")}
+	if err := userRepo.DB.Create(passkey).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	// Purge juga berlaku untuk user yang sudah ada di trash
+	if err := users.Delete(user.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := users.Purge(user.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	var remaining int64
+	userRepo.DB.Unscoped().Model(&domain.User{}).Where("id = ?", user.ID).Count(&remaining)
+	if remaining != 0 {
+		t.Fatal("user row still exists after purge")
+	}
+	userRepo.DB.Unscoped().Model(&domain.Passkey{}).Where("user_id = ?", user.ID).Count(&remaining)
+	if remaining != 0 {
+		t.Fatal("passkeys still exist after purge")
+	}
+
+	if err := users.Purge(user.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("purge twice: err = %v, want not found", err)
+	}
+}
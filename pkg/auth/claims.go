@@ -0,0 +1,45 @@
+package auth
+
+import (
+	"codebase-api/internal/domain"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// Claims adalah payload JWT yang dipakai oleh GenerateJWT dan JwtProtected
+type Claims struct {
+	ID       uint   `json:"id"`
+	UUID     string `json:"uuid"`
+	Username string `json:"username"`
+	// AuthTime adalah waktu terakhir user membuktikan identitasnya (login / reauthenticate)
+	AuthTime *jwt.NumericDate `json:"auth_time,omitempty"`
+	// SessionVersion harus sama dengan domain.User.SessionVersion, dicek oleh LoadUser
+	SessionVersion uint `json:"sv,omitempty"`
+	jwt.RegisteredClaims
+}
+
+func NewClaims(user domain.User, authTime time.Time, ttl time.Duration) Claims {
+	claims := Claims{
+		ID:       user.ID,
+		UUID:     user.UUID.String(),
+		Username: user.Username,
+		// Token lama tanpa claim ini bernilai 0, sama dengan default kolomnya
+		SessionVersion: user.SessionVersion,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+		},
+	}
+	if !authTime.IsZero() {
+		claims.AuthTime = jwt.NewNumericDate(authTime)
+	}
+	return claims
+}
+
+// AuthenticatedWithin melaporkan apakah user membuktikan identitasnya dalam rentang maxAge
+func (c *Claims) AuthenticatedWithin(maxAge time.Duration) bool {
+	if c.AuthTime == nil {
+		return false
+	}
+	return time.Since(c.AuthTime.Time) <= maxAge
+}
@@ -0,0 +1,32 @@
+package auth
+
+import (
+	"codebase-api/internal/domain"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	claimsLocalsKey = "auth.claims"
+	userLocalsKey   = "auth.user"
+)
+
+func SetClaims(c *fiber.Ctx, claims *Claims) {
+	c.Locals(claimsLocalsKey, claims)
+}
+
+// CurrentUser mengembalikan claims user yang sedang login (di-set oleh JwtProtected)
+func CurrentUser(c *fiber.Ctx) (*Claims, bool) {
+	claims, ok := c.Locals(claimsLocalsKey).(*Claims)
+	return claims, ok && claims != nil
+}
+
+func SetUser(c *fiber.Ctx, user *domain.User) {
+	c.Locals(userLocalsKey, user)
+}
+
+// User mengembalikan domain.User lengkap yang dimuat oleh middleware LoadUser
+func User(c *fiber.Ctx) (*domain.User, bool) {
+	user, ok := c.Locals(userLocalsKey).(*domain.User)
+	return user, ok && user != nil
+}
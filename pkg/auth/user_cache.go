@@ -0,0 +1,55 @@
+package auth
+
+import (
+	"codebase-api/internal/domain"
+	"sync"
+	"time"
+)
+
+type cachedUser struct {
+	user      domain.User
+	expiresAt time.Time
+}
+
+// UserCache menyimpan domain.User di memory dengan TTL pendek
+type UserCache struct {
+	ttl   time.Duration
+	mu    sync.RWMutex
+	items map[uint]cachedUser
+}
+
+func NewUserCache(ttl time.Duration) *UserCache {
+	return &UserCache{ttl: ttl, items: make(map[uint]cachedUser)}
+}
+
+func (c *UserCache) Get(id uint) (*domain.User, bool) {
+	c.mu.RLock()
+	item, ok := c.items[id]
+	c.mu.RUnlock()
+	if !ok || time.Now().After(item.expiresAt) {
+		return nil, false
+	}
+
+	// Kembalikan salinan agar perubahan di handler tidak mengotori cache
+	user := item.user
+	return &user, true
+}
+
+func (c *UserCache) Set(user *domain.User) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for id, item := range c.items {
+		if now.After(item.expiresAt) {
+			delete(c.items, id)
+		}
+	}
+	c.items[user.ID] = cachedUser{user: *user, expiresAt: now.Add(c.ttl)}
+}
+
+func (c *UserCache) Invalidate(id uint) {
+	c.mu.Lock()
+	delete(c.items, id)
+	c.mu.Unlock()
+}
@@ -0,0 +1,97 @@
+package helpers
+
+import (
+	"errors"
+	"hash/fnv"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+var ErrInvalidETag = errors.New("If-Match must be * or an ETag returned by the API")
+
+// VersionETag membentuk ETag (strong) dari version entity, misalnya "3". Response yang isinya bergantung
+// pada request (?fields=, format ID) mengirim variant sehingga setiap representasi punya ETag sendiri,
+// misalnya "3-9f1c2a7b"
+func VersionETag(version uint, variant ...string) string {
+	etag := strconv.FormatUint(uint64(version), 10)
+	if key := strings.Join(variant, "\x00"); key != "" {
+		hash := fnv.New32a()
+		hash.Write([]byte(key))
+		etag += "-" + strconv.FormatUint(uint64(hash.Sum32()), 16)
+	}
+	return `"` + etag + `"`
+}
+
+// SetVersionETag menambahkan header ETag untuk response satu resource
+func SetVersionETag(c *fiber.Ctx, version uint, variant ...string) {
+	c.Set(fiber.HeaderETag, VersionETag(version, variant...))
+}
+
+// ParseIfMatch membaca version dari header If-Match. Nilai "*" menghasilkan 0 (cocok dengan version apa pun).
+// Weak ETag (W/"...") ditolak karena If-Match memakai strong comparison. Hash representasi dari VersionETag
+// diabaikan: precondition penulisan hanya bergantung pada version resource
+func ParseIfMatch(header string) (uint, error) {
+	header = strings.TrimSpace(header)
+	if header == "*" {
+		return 0, nil
+	}
+	if len(header) < 3 || header[0] != '"' || header[len(header)-1] != '"' {
+		return 0, ErrInvalidETag
+	}
+
+	value, hash, hashed := strings.Cut(header[1:len(header)-1], "-")
+	if hashed {
+		if _, err := strconv.ParseUint(hash, 16, 32); err != nil {
+			return 0, ErrInvalidETag
+		}
+	}
+	version, err := strconv.ParseUint(value, 10, 64)
+	if err != nil || version == 0 {
+		return 0, ErrInvalidETag
+	}
+	return uint(version), nil
+}
+
+// CollectionETag membentuk weak ETag sebuah list dari jumlah baris dan updated_at terbaru
+func CollectionETag(count int64, lastModified time.Time) string {
+	return `W/"` + strconv.FormatInt(count, 10) + "-" + strconv.FormatInt(lastModified.UnixNano(), 36) + `"`
+}
+
+// NotModified menambahkan header ETag dan Last-Modified, lalu melaporkan apakah salinan client masih segar
+// sehingga handler cukup membalas 304. If-Modified-Since hanya dipakai bila If-None-Match tidak dikirim
+func NotModified(c *fiber.Ctx, etag string, lastModified time.Time) bool {
+	c.Set(fiber.HeaderETag, etag)
+	if !lastModified.IsZero() {
+		c.Set(fiber.HeaderLastModified, lastModified.UTC().Format(http.TimeFormat))
+	}
+	if c.Method() != fiber.MethodGet && c.Method() != fiber.MethodHead {
+		return false
+	}
+
+	if noneMatch := c.Get(fiber.HeaderIfNoneMatch); noneMatch != "" {
+		return etagListMatches(noneMatch, etag)
+	}
+
+	since, err := http.ParseTime(c.Get(fiber.HeaderIfModifiedSince))
+	if err != nil || lastModified.IsZero() {
+		return false
+	}
+	// Last-Modified hanya presisi detik
+	return !lastModified.Truncate(time.Second).After(since)
+}
+
+// etagListMatches membandingkan daftar If-None-Match dengan etag memakai weak comparison
+func etagListMatches(header string, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
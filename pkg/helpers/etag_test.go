@@ -0,0 +1,67 @@
+package helpers
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestVersionETag(t *testing.T) {
+	if etag := VersionETag(3); etag != `"3"` {
+		t.Fatalf("VersionETag(3) = %s", etag)
+	}
+	if etag := VersionETag(3, ""); etag != `"3"` {
+		t.Fatalf("empty variant changed the etag: %s", etag)
+	}
+
+	fields := VersionETag(3, "fields=username;include=")
+	if fields == VersionETag(3) || fields == VersionETag(3, "fields=email;include=") {
+		t.Fatalf("representations share etag %s", fields)
+	}
+	if fields != VersionETag(3, "fields=username;include=") {
+		t.Fatal("etag for the same representation is not stable")
+	}
+	if VersionETag(3, "a", "b") == VersionETag(3, "ab") {
+		t.Fatal("variant parts are not separated")
+	}
+}
+
+func TestParseIfMatchAcceptsEmittedETags(t *testing.T) {
+	tests := []struct {
+		header string
+		want   uint
+	}{
+		{header: "*", want: 0},
+		{header: VersionETag(7), want: 7},
+		{header: VersionETag(7, "fields=username;include="), want: 7},
+		{header: " " + VersionETag(12, "legacy_id") + " ", want: 12},
+	}
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			version, err := ParseIfMatch(tt.header)
+			if err != nil || version != tt.want {
+				t.Fatalf("ParseIfMatch(%s) = %d, %v, want %d", tt.header, version, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseIfMatchRejectsInvalidETags(t *testing.T) {
+	for _, header := range []string{
+		"",
+		`""`,
+		`"0"`,
+		"7",
+		`W/"7"`,
+		`"abc"`,
+		`"7-"`,
+		`"7-xyz"`,
+		`"-1a2b"`,
+		`"7-1a2b3c4d5e"`,
+	} {
+		t.Run(header, func(t *testing.T) {
+			if version, err := ParseIfMatch(header); !errors.Is(err, ErrInvalidETag) {
+				t.Fatalf("ParseIfMatch(%s) = %d, %v, want ErrInvalidETag", header, version, err)
+			}
+		})
+	}
+}
@@ -20,8 +20,26 @@ func SuccessResponse(c *fiber.Ctx, data interface{}, message string) error {
 	})
 }
 
+// Accepted Response untuk pekerjaan yang diproses di background
+func AcceptedResponse(c *fiber.Ctx, data interface{}, message string) error {
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"statusCode": 202,
+		"message":    message,
+		"data":       data,
+	})
+}
+
+type Pagination struct {
+	Page       int    `json:"page,omitempty"`
+	PageSize   int    `json:"pageSize"`
+	TotalItems *int64 `json:"totalItems,omitempty"`
+	TotalPages *int   `json:"totalPages,omitempty"`
+	NextCursor string `json:"nextCursor,omitempty"`
+	PrevCursor string `json:"prevCursor,omitempty"`
+}
+
 // Success Response with Pagination
-func PaginationResponse(c *fiber.Ctx, data []interface{}, page int, pageSize int, totalItems int, totalPages int, message string) error {
+func PaginationResponse(c *fiber.Ctx, data []interface{}, pagination Pagination, message string) error {
 	if data == nil {
 		data = []interface{}{}
 	}
@@ -30,13 +48,8 @@ func PaginationResponse(c *fiber.Ctx, data []interface{}, page int, pageSize int
 		"statusCode": 200,
 		"message":    message,
 		"data": fiber.Map{
-			"items": data,
-			"pagination": fiber.Map{
-				"page":       page,
-				"pageSize":   pageSize,
-				"totalItems": totalItems,
-				"totalPages": totalPages,
-			},
+			"items":      data,
+			"pagination": pagination,
 		},
 	})
 }
@@ -63,3 +76,18 @@ func ErrorResponse(c *fiber.Ctx, statusCode int, message interface{}, err error)
 		"error":      errorMessage,
 	})
 }
+
+// Error Response with a machine-readable code for the client
+func ErrorCodeResponse(c *fiber.Ctx, statusCode int, code string, message interface{}, details interface{}) error {
+	body := fiber.Map{
+		"statusCode": statusCode,
+		"message":    message,
+		"error":      code,
+		"code":       code,
+	}
+	if details != nil {
+		body["details"] = details
+	}
+
+	return c.Status(statusCode).JSON(body)
+}
@@ -0,0 +1,155 @@
+package helpers
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+// ProjectionSchema adalah allow-list untuk satu DTO: Fields memetakan nama field JSON ke kolom
+// database yang dibutuhkan untuk mengisinya, Includes berisi relasi yang boleh di-embed
+type ProjectionSchema struct {
+	Fields   map[string][]string
+	Includes []string
+	// Required adalah kolom yang selalu di-select, misalnya primary key dan kolom cursor
+	Required []string
+}
+
+// Extend membuat schema baru untuk DTO yang meng-embed DTO lain, ditambah field miliknya sendiri
+func (s ProjectionSchema) Extend(fields map[string][]string) ProjectionSchema {
+	extended := ProjectionSchema{
+		Fields:   make(map[string][]string, len(s.Fields)+len(fields)),
+		Includes: slices.Clone(s.Includes),
+		Required: slices.Clone(s.Required),
+	}
+	for name, columns := range s.Fields {
+		extended.Fields[name] = columns
+	}
+	for name, columns := range fields {
+		extended.Fields[name] = columns
+	}
+	return extended
+}
+
+// Projection adalah hasil parsing ?fields=id,username&include=roles
+type Projection struct {
+	Fields  []string
+	Include []string
+	schema  ProjectionSchema
+}
+
+func ParseProjection(c *fiber.Ctx, schema ProjectionSchema) (*Projection, error) {
+	p := &Projection{schema: schema}
+
+	for _, name := range splitList(c.Query("fields")) {
+		if _, ok := schema.Fields[name]; !ok {
+			return nil, fmt.Errorf("unknown field %q", name)
+		}
+		if !slices.Contains(p.Fields, name) {
+			p.Fields = append(p.Fields, name)
+		}
+	}
+
+	for _, name := range splitList(c.Query("include")) {
+		if !slices.Contains(schema.Includes, name) {
+			return nil, fmt.Errorf("unknown include %q", name)
+		}
+		if !slices.Contains(p.Include, name) {
+			p.Include = append(p.Include, name)
+		}
+	}
+	return p, nil
+}
+
+func splitList(value string) []string {
+	var items []string
+	// Disalin agar aman dipakai setelah request selesai (fiber memakai ulang buffer request)
+	for _, item := range strings.Split(utils.CopyString(value), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Includes melaporkan apakah relasi diminta lewat ?include=
+func (p *Projection) Includes(name string) bool {
+	return slices.Contains(p.Include, name)
+}
+
+// Variant adalah bentuk kanonik ?fields= dan ?include= (urutan tidak berpengaruh), dipakai sebagai
+// variant VersionETag; kosong bila seluruh representasi diminta
+func (p *Projection) Variant() string {
+	if len(p.Fields) == 0 && len(p.Include) == 0 {
+		return ""
+	}
+
+	fields, include := slices.Clone(p.Fields), slices.Clone(p.Include)
+	slices.Sort(fields)
+	slices.Sort(include)
+	return "fields=" + strings.Join(fields, ",") + ";include=" + strings.Join(include, ",")
+}
+
+// Columns mengembalikan kolom untuk SELECT, atau nil jika semua field diminta.
+// extra adalah kolom tambahan yang dibutuhkan handler di luar DTO, mis. version dan updated_at untuk ETag
+func (p *Projection) Columns(extra ...string) []string {
+	if len(p.Fields) == 0 {
+		return nil
+	}
+
+	columns := slices.Clone(p.schema.Required)
+	for _, column := range extra {
+		if !slices.Contains(columns, column) {
+			columns = append(columns, column)
+		}
+	}
+	for _, name := range p.Fields {
+		for _, column := range p.schema.Fields[name] {
+			if !slices.Contains(columns, column) {
+				columns = append(columns, column)
+			}
+		}
+	}
+	return columns
+}
+
+// Apply membuang field DTO yang tidak diminta; relasi yang di-include selalu dipertahankan
+func (p *Projection) Apply(dto interface{}) (interface{}, error) {
+	if len(p.Fields) == 0 {
+		return dto, nil
+	}
+
+	data, err := json.Marshal(dto)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	projected := make(map[string]json.RawMessage, len(p.Fields)+len(p.Include))
+	for _, name := range append(slices.Clone(p.Fields), p.Include...) {
+		if value, ok := fields[name]; ok {
+			projected[name] = value
+		}
+	}
+	return projected, nil
+}
+
+// ApplyAll menerapkan Apply ke setiap item, dipakai bersama PaginationResponse
+func (p *Projection) ApplyAll(items []interface{}) ([]interface{}, error) {
+	for i, item := range items {
+		projected, err := p.Apply(item)
+		if err != nil {
+			return nil, err
+		}
+		items[i] = projected
+	}
+	return items, nil
+}
@@ -0,0 +1,122 @@
+package helpers
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+var testProjection = ProjectionSchema{
+	Fields: map[string][]string{
+		"id":       {"uuid"},
+		"name":     {"first_name", "last_name"},
+		"email":    {"email"},
+		"verified": {"email_verified_at"},
+	},
+	Includes: []string{"groups"},
+	Required: []string{"id"},
+}
+
+// parseProjection menjalankan ParseProjection di dalam request fiber sungguhan
+func parseProjection(t *testing.T, query string) (*Projection, error) {
+	t.Helper()
+
+	var (
+		projection *Projection
+		parseErr   error
+	)
+	app := fiber.New()
+	app.Get("/", func(c *fiber.Ctx) error {
+		projection, parseErr = ParseProjection(c, testProjection)
+		return nil
+	})
+	if _, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/"+query, nil)); err != nil {
+		t.Fatal(err)
+	}
+	return projection, parseErr
+}
+
+func TestParseProjection(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		fields  []string
+		include []string
+		columns []string
+	}{
+		{name: "no fields selects everything", query: "", columns: nil},
+		{name: "single field", query: "?fields=email", fields: []string{"email"}, columns: []string{"id", "version", "email"}},
+		{
+			name:    "spaces, duplicates and empty items",
+			query:   "?fields=%20email,,name,email",
+			fields:  []string{"email", "name"},
+			columns: []string{"id", "version", "email", "first_name", "last_name"},
+		},
+		{
+			name:    "include",
+			query:   "?fields=id&include=groups,groups",
+			fields:  []string{"id"},
+			include: []string{"groups"},
+			columns: []string{"id", "version", "uuid"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			projection, err := parseProjection(t, tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(projection.Fields, tt.fields) || !reflect.DeepEqual(projection.Include, tt.include) {
+				t.Fatalf("fields = %q, include = %q, want %q, %q", projection.Fields, projection.Include, tt.fields, tt.include)
+			}
+			if columns := projection.Columns("version", "id"); !reflect.DeepEqual(columns, tt.columns) {
+				t.Fatalf("columns = %q, want %q", columns, tt.columns)
+			}
+		})
+	}
+}
+
+func TestParseProjectionRejectsUnknownNames(t *testing.T) {
+	for _, query := range []string{
+		"?fields=password",
+		"?fields=email,password",
+		"?fields=first_name",
+		"?include=roles",
+	} {
+		t.Run(query, func(t *testing.T) {
+			if projection, err := parseProjection(t, query); err == nil {
+				t.Fatalf("projection = %+v, want error", projection)
+			}
+		})
+	}
+}
+
+func TestProjectionApply(t *testing.T) {
+	projection, err := parseProjection(t, "?fields=email,name&include=groups")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dto := struct {
+		ID     string   `json:"id"`
+		Name   string   `json:"name"`
+		Email  string   `json:"email"`
+		Groups []string `json:"groups"`
+	}{ID: "u1", Name: "Budi", Email: "budi@example.com", Groups: []string{"finance"}}
+
+	projected, err := projection.Apply(dto)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]string{}
+	for name, value := range projected.(map[string]json.RawMessage) {
+		got[name] = string(value)
+	}
+	want := map[string]string{"name": `"Budi"`, "email": `"budi@example.com"`, "groups": `["finance"]`}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("projected = %v, want %v", got, want)
+	}
+}
@@ -23,6 +23,8 @@ func ValidationErrorFormatter(err error, input interface{}) map[string]string {
 		case "min":
 			if err.Field() == "Password" {
 				errorFields[jsonTag] = "Password must be at least 8 characters"
+			} else {
+				errorFields[jsonTag] = "This field must not be empty"
 			}
 		case "eqfield":
 			errorFields[jsonTag] = "Password Confirm do not match"
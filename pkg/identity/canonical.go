@@ -0,0 +1,60 @@
+// Package identity menormalisasi identitas user (username, email, phone) agar perbandingan dan
+// keunikan tidak bergantung pada huruf besar/kecil, spasi atau bentuk Unicode yang berbeda
+package identity
+
+import (
+	"strings"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Clean memangkas spasi dan menerapkan NFKC; dipakai untuk nilai yang ditampilkan ke user
+func Clean(value string) string {
+	return strings.TrimSpace(norm.NFKC.String(value))
+}
+
+// fold menerapkan case folding lalu NFKC lagi, karena folding bisa menghasilkan bentuk yang belum ternormalisasi
+func fold(value string) string {
+	return norm.NFKC.String(cases.Fold().String(Clean(value)))
+}
+
+// Username mengembalikan bentuk kanonik username
+func Username(value string) string {
+	return fold(value)
+}
+
+// Email mengembalikan bentuk kanonik email; seluruh alamat (termasuk local part) di-fold
+func Email(value string) string {
+	return fold(value)
+}
+
+// Phone mengembalikan nomor telepon tanpa spasi dan pemisah, hanya digit dengan awalan + opsional
+func Phone(value string) string {
+	value = Clean(value)
+
+	var b strings.Builder
+	for i, r := range value {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == '+' && i == 0:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// reservedUsernames tidak boleh dipakai user karena bentrok dengan route atau akun sistem
+var reservedUsernames = map[string]bool{
+	"admin": true, "administrator": true, "root": true, "system": true, "support": true,
+	"security": true, "help": true, "api": true, "me": true, "www": true, "mail": true,
+	"postmaster": true, "webmaster": true, "abuse": true, "noreply": true, "no-reply": true,
+	"null": true, "undefined": true, "anonymous": true, "deleted": true,
+}
+
+// IsReservedUsername juga menolak prefix "erased-" yang dipakai untuk user yang sudah dianonimkan
+func IsReservedUsername(value string) bool {
+	canonical := Username(value)
+	return reservedUsernames[canonical] || strings.HasPrefix(canonical, "erased-")
+}
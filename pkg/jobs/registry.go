@@ -0,0 +1,267 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Status string
+
+const (
+	StatusPending   Status = "pending"
+	StatusRunning   Status = "running"
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+)
+
+var (
+	ErrNoResult  = errors.New("job has no result")
+	ErrDiscarded = errors.New("job has been discarded")
+)
+
+// Info adalah snapshot status job yang aman dikirim ke client
+type Info struct {
+	ID      string `json:"id"`
+	Kind    string `json:"kind"`
+	OwnerID uint   `json:"-"`
+	// Private berarti hasil job hanya boleh dilihat pemiliknya, admin sekalipun tidak (mis. export GDPR)
+	Private    bool        `json:"-"`
+	Status     Status      `json:"status"`
+	Error      string      `json:"error,omitempty"`
+	Summary    interface{} `json:"summary,omitempty"`
+	ResultName string      `json:"result_name,omitempty"`
+	CreatedAt  time.Time   `json:"created_at"`
+	FinishedAt *time.Time  `json:"finished_at,omitempty"`
+}
+
+// Job adalah pekerjaan background; hasilnya berupa file sementara yang dihapus saat job kedaluwarsa
+type Job struct {
+	mu          sync.RWMutex
+	info        Info
+	resultPath  string
+	contentType string
+	dir         string
+	// ctx dibatalkan saat registry ditutup atau job dibuang lewat RemoveOwned
+	ctx       context.Context
+	cancel    context.CancelFunc
+	discarded bool
+}
+
+// Func adalah isi pekerjaan; ctx dibatalkan saat registry ditutup
+type Func func(ctx context.Context, job *Job) error
+
+func (j *Job) Info() Info {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	return j.info
+}
+
+// SetSummary menyimpan ringkasan hasil (jumlah baris, dsb) yang ditampilkan pada status job
+func (j *Job) SetSummary(summary interface{}) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.info.Summary = summary
+}
+
+// CreateResult membuat file hasil job; pemanggil wajib menutup file tersebut
+func (j *Job) CreateResult(name string, contentType string) (*os.File, error) {
+	file, err := os.CreateTemp(j.dir, "job-*-"+filepath.Base(name))
+	if err != nil {
+		return nil, err
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	// Job yang sudah dibuang tidak boleh meninggalkan file yatim
+	if j.discarded {
+		file.Close()
+		os.Remove(file.Name())
+		return nil, ErrDiscarded
+	}
+	if j.resultPath != "" {
+		os.Remove(j.resultPath)
+	}
+	j.resultPath = file.Name()
+	j.contentType = contentType
+	j.info.ResultName = name
+	return file, nil
+}
+
+// Result mengembalikan path file hasil, nama file untuk download dan content type
+func (j *Job) Result() (string, string, string, error) {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	if j.info.Status != StatusSucceeded || j.resultPath == "" {
+		return "", "", "", ErrNoResult
+	}
+	return j.resultPath, j.info.ResultName, j.contentType, nil
+}
+
+// discard membatalkan job dan menghapus file hasilnya
+func (j *Job) discard() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.cancel()
+	j.discarded = true
+	if j.resultPath != "" {
+		os.Remove(j.resultPath)
+		j.resultPath = ""
+	}
+}
+
+func (j *Job) setStatus(status Status, err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.info.Status = status
+	if err != nil {
+		j.info.Error = err.Error()
+	}
+	if status == StatusSucceeded || status == StatusFailed {
+		now := time.Now()
+		j.info.FinishedAt = &now
+	}
+}
+
+// Registry menyimpan job di memori proses; job hilang saat aplikasi restart
+type Registry struct {
+	mu     sync.RWMutex
+	jobs   map[string]*Job
+	ttl    time.Duration
+	dir    string
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// NewRegistry membuat registry; job yang selesai lebih dari ttl lalu dihapus beserta file hasilnya
+func NewRegistry(ttl time.Duration) *Registry {
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &Registry{
+		jobs:   map[string]*Job{},
+		ttl:    ttl,
+		dir:    os.TempDir(),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+	go r.cleanupLoop()
+	return r
+}
+
+// Submit menjalankan fn di background dan langsung mengembalikan job dengan status pending
+func (r *Registry) Submit(kind string, ownerID uint, fn Func) *Job {
+	job := r.newJob(kind, ownerID, false)
+	go r.execute(job, fn)
+	return job
+}
+
+// SubmitPrivate sama dengan Submit, tetapi job hanya boleh diakses pemiliknya (Info.Private)
+func (r *Registry) SubmitPrivate(kind string, ownerID uint, fn Func) *Job {
+	job := r.newJob(kind, ownerID, true)
+	go r.execute(job, fn)
+	return job
+}
+
+// Run menjalankan fn secara sinkron, dipakai untuk pekerjaan kecil yang tetap butuh file hasil
+func (r *Registry) Run(kind string, ownerID uint, fn Func) *Job {
+	job := r.newJob(kind, ownerID, false)
+	r.execute(job, fn)
+	return job
+}
+
+func (r *Registry) Get(id string) (*Job, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	job, ok := r.jobs[id]
+	return job, ok
+}
+
+// RemoveOwned membatalkan dan menghapus semua job milik ownerID beserta file hasilnya,
+// mis. setelah data user dihapus (erasure)
+func (r *Registry) RemoveOwned(ownerID uint) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, job := range r.jobs {
+		if job.Info().OwnerID == ownerID {
+			job.discard()
+			delete(r.jobs, id)
+		}
+	}
+}
+
+// Close membatalkan job yang masih berjalan
+func (r *Registry) Close() {
+	r.cancel()
+}
+
+func (r *Registry) newJob(kind string, ownerID uint, private bool) *Job {
+	ctx, cancel := context.WithCancel(r.ctx)
+	job := &Job{
+		info: Info{
+			ID:        uuid.NewString(),
+			Kind:      kind,
+			OwnerID:   ownerID,
+			Private:   private,
+			Status:    StatusPending,
+			CreatedAt: time.Now(),
+		},
+		dir:    r.dir,
+		ctx:    ctx,
+		cancel: cancel,
+	}
+
+	r.mu.Lock()
+	r.jobs[job.info.ID] = job
+	r.mu.Unlock()
+	return job
+}
+
+func (r *Registry) execute(job *Job, fn Func) {
+	job.setStatus(StatusRunning, nil)
+
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			job.setStatus(StatusFailed, errors.New("job panicked"))
+		}
+	}()
+
+	if err := fn(job.ctx, job); err != nil {
+		job.setStatus(StatusFailed, err)
+		return
+	}
+	job.setStatus(StatusSucceeded, nil)
+}
+
+func (r *Registry) cleanupLoop() {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.ctx.Done():
+			return
+		case <-ticker.C:
+			r.cleanup()
+		}
+	}
+}
+
+func (r *Registry) cleanup() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, job := range r.jobs {
+		info := job.Info()
+		if info.FinishedAt == nil || time.Since(*info.FinishedAt) < r.ttl {
+			continue
+		}
+
+		job.discard()
+		delete(r.jobs, id)
+	}
+}
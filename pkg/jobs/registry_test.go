@@ -0,0 +1,95 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+)
+
+func newTestRegistry(t *testing.T) *Registry {
+	t.Helper()
+
+	r := NewRegistry(time.Hour)
+	r.dir = t.TempDir()
+	t.Cleanup(r.Close)
+	return r
+}
+
+func writeResult(ctx context.Context, job *Job) error {
+	file, err := job.CreateResult("result.txt", "text/plain")
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = file.WriteString("data")
+	return err
+}
+
+func TestRemoveOwnedDeletesJobsAndResults(t *testing.T) {
+	r := newTestRegistry(t)
+	owned := r.Run("export", 1, writeResult)
+	other := r.Run("export", 2, writeResult)
+
+	path, _, _, err := owned.Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r.RemoveOwned(1)
+
+	if _, ok := r.Get(owned.Info().ID); ok {
+		t.Fatal("owned job is still registered")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("result file still exists: %v", err)
+	}
+	if _, ok := r.Get(other.Info().ID); !ok {
+		t.Fatal("job of another owner was removed")
+	}
+	if _, _, _, err := other.Result(); err != nil {
+		t.Fatalf("result of another owner: %v", err)
+	}
+}
+
+func TestRemoveOwnedCancelsRunningJob(t *testing.T) {
+	r := newTestRegistry(t)
+	started, result := make(chan struct{}), make(chan error, 1)
+
+	job := r.Submit("export", 1, func(ctx context.Context, job *Job) error {
+		close(started)
+		<-ctx.Done()
+		// Job yang terlambat menyadari pembatalan tetap tidak boleh meninggalkan file
+		err := writeResult(ctx, job)
+		result <- err
+		return err
+	})
+
+	<-started
+	r.RemoveOwned(1)
+
+	if err := <-result; !errors.Is(err, ErrDiscarded) {
+		t.Fatalf("create result after discard: err = %v, want ErrDiscarded", err)
+	}
+	if _, _, _, err := job.Result(); !errors.Is(err, ErrNoResult) {
+		t.Fatalf("discarded job result: err = %v, want ErrNoResult", err)
+	}
+	entries, err := os.ReadDir(r.dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("orphaned files: %v", entries)
+	}
+}
+
+func TestSubmitPrivateMarksJob(t *testing.T) {
+	r := newTestRegistry(t)
+	if !r.SubmitPrivate("data_export", 1, writeResult).Info().Private {
+		t.Fatal("SubmitPrivate job is not private")
+	}
+	if r.Submit("user_export", 1, writeResult).Info().Private {
+		t.Fatal("Submit job is private")
+	}
+}
@@ -0,0 +1,44 @@
+package kvstore
+
+import (
+	"context"
+	"time"
+
+	fiberredis "github.com/gofiber/storage/redis/v3"
+	"github.com/redis/go-redis/v9"
+)
+
+// incrScript menaikkan counter dan memasang TTL hanya saat key baru dibuat, dalam satu langkah atomik
+var incrScript = redis.NewScript(`
+local value = redis.call("INCRBY", KEYS[1], ARGV[1])
+if tonumber(ARGV[2]) > 0 and redis.call("PTTL", KEYS[1]) < 0 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return value
+`)
+
+// Redis membungkus storage Redis milik fiber dan menambah operasi atomik untuk counter
+type Redis struct {
+	*fiberredis.Storage
+}
+
+func NewRedis(storage *fiberredis.Storage) *Redis {
+	return &Redis{Storage: storage}
+}
+
+func (s *Redis) Incr(key string, delta int64, exp time.Duration) (int64, error) {
+	return incrScript.Run(context.Background(), s.Conn(), []string{key}, delta, exp.Milliseconds()).Int64()
+}
+
+func (s *Redis) SetNX(key string, val []byte, exp time.Duration) (bool, error) {
+	return s.Conn().SetNX(context.Background(), key, val, exp).Result()
+}
+
+func (s *Redis) TTL(key string) (time.Duration, error) {
+	ttl, err := s.Conn().PTTL(context.Background(), key).Result()
+	if err != nil || ttl < 0 {
+		// -2: key tidak ada, -1: key tanpa TTL
+		return 0, err
+	}
+	return ttl, nil
+}
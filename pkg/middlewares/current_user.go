@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"codebase-api/internal/domain"
+	"codebase-api/pkg/auth"
+	helper "codebase-api/pkg/helpers"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// LoadUser memuat domain.User milik pemilik token sekali per request.
+// Harus dipasang setelah JwtProtected; hasilnya dibaca lewat auth.User(c).
+// Token milik user yang sudah dihapus, dinonaktifkan atau di-erase ditolak walaupun JWT-nya masih berlaku,
+// begitu juga token yang diterbitkan sebelum sesi user dicabut (SessionVersion naik)
+func LoadUser(cache *auth.UserCache, find func(id uint) (*domain.User, error)) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claims, ok := auth.CurrentUser(c)
+		if !ok {
+			return helper.ErrorResponse(c, fiber.StatusUnauthorized, "Unauthorized", nil)
+		}
+
+		user, ok := cache.Get(claims.ID)
+		if !ok {
+			found, err := find(claims.ID)
+			if errors.Is(err, domain.ErrNotFound) {
+				return helper.ErrorResponse(c, fiber.StatusUnauthorized, "User not found", nil)
+			}
+			if err != nil {
+				return err
+			}
+			cache.Set(found)
+			user = found
+		}
+
+		if !user.IsActive || user.ErasedAt != nil || user.DeletedAt.Valid {
+			return helper.ErrorResponse(c, fiber.StatusForbidden, "User is inactive", nil)
+		}
+		if claims.SessionVersion != user.SessionVersion {
+			return helper.ErrorResponse(c, fiber.StatusUnauthorized, "Session has been revoked", nil)
+		}
+
+		auth.SetUser(c, user)
+		return c.Next()
+	}
+}
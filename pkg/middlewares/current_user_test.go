@@ -0,0 +1,122 @@
+package middleware
+
+import (
+	"codebase-api/internal/domain"
+	"codebase-api/pkg/auth"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+func newLoadUserApp(t *testing.T, user *domain.User) (*fiber.App, string) {
+	t.Helper()
+
+	find := func(id uint) (*domain.User, error) {
+		if id != user.ID {
+			return nil, domain.NotFound("user not found")
+		}
+		found := *user
+		return &found, nil
+	}
+
+	app := fiber.New()
+	app.Get("/me", JwtProtected(), LoadUser(auth.NewUserCache(time.Minute), find), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	token, err := GenerateJWT(*user, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return app, token
+}
+
+func requestWithToken(t *testing.T, app *fiber.App, token string) int {
+	t.Helper()
+
+	req := httptest.NewRequest(fiber.MethodGet, "/me", nil)
+	req.Header.Set(fiber.HeaderCookie, "jwt="+token)
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode
+}
+
+func TestLoadUser(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name   string
+		modify func(user *domain.User)
+		want   int
+	}{
+		{name: "active user", modify: func(user *domain.User) {}, want: fiber.StatusOK},
+		{name: "deactivated user", modify: func(user *domain.User) { user.IsActive = false }, want: fiber.StatusForbidden},
+		{name: "erased user", modify: func(user *domain.User) { user.ErasedAt = &now }, want: fiber.StatusForbidden},
+		{name: "deleted user", modify: func(user *domain.User) { user.ID = 99 }, want: fiber.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := &domain.User{BaseDomain: domain.BaseDomain{ID: 1, UUID: uuid.New()}, Username: "budi", IsActive: true}
+			app, token := newLoadUserApp(t, user)
+			tt.modify(user)
+
+			if got := requestWithToken(t, app, token); got != tt.want {
+				t.Fatalf("status = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadUserRejectsCachedUserAfterDeactivation(t *testing.T) {
+	user := &domain.User{BaseDomain: domain.BaseDomain{ID: 1, UUID: uuid.New()}, Username: "budi", IsActive: true}
+	cache := auth.NewUserCache(time.Minute)
+	find := func(id uint) (*domain.User, error) {
+		found := *user
+		return &found, nil
+	}
+
+	app := fiber.New()
+	app.Get("/me", JwtProtected(), LoadUser(cache, find), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+	token, err := GenerateJWT(*user, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := requestWithToken(t, app, token); got != fiber.StatusOK {
+		t.Fatalf("status before deactivation = %d, want %d", got, fiber.StatusOK)
+	}
+
+	// Handler deactivate menghapus cache sehingga request berikutnya membaca status terbaru
+	user.IsActive = false
+	cache.Invalidate(user.ID)
+
+	if got := requestWithToken(t, app, token); got != fiber.StatusForbidden {
+		t.Fatalf("status after deactivation = %d, want %d", got, fiber.StatusForbidden)
+	}
+}
+
+func TestLoadUserRejectsTokenAfterSessionRevocation(t *testing.T) {
+	user := &domain.User{BaseDomain: domain.BaseDomain{ID: 1, UUID: uuid.New()}, Username: "budi", IsActive: true}
+	app, oldToken := newLoadUserApp(t, user)
+
+	// Revert email change menaikkan SessionVersion; token lama tidak berlaku lagi
+	user.SessionVersion++
+	if got := requestWithToken(t, app, oldToken); got != fiber.StatusUnauthorized {
+		t.Fatalf("status with revoked token = %d, want %d", got, fiber.StatusUnauthorized)
+	}
+
+	newToken, err := GenerateJWT(*user, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := requestWithToken(t, app, newToken); got != fiber.StatusOK {
+		t.Fatalf("status with new token = %d, want %d", got, fiber.StatusOK)
+	}
+}
@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	helper "codebase-api/pkg/helpers"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const ifMatchLocalsKey = "ifMatch.version"
+
+// RequireIfMatch mewajibkan header If-Match pada PUT / PATCH agar perubahan tidak menimpa
+// perubahan orang lain tanpa disadari. Version yang diminta dibaca handler lewat IfMatchVersion
+func RequireIfMatch() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		header := c.Get(fiber.HeaderIfMatch)
+		if header == "" {
+			return helper.ErrorResponse(c, fiber.StatusPreconditionRequired, "If-Match header is required", nil)
+		}
+
+		version, err := helper.ParseIfMatch(header)
+		if err != nil {
+			return helper.ErrorResponse(c, fiber.StatusPreconditionFailed, "Precondition failed", err)
+		}
+
+		c.Locals(ifMatchLocalsKey, version)
+		return c.Next()
+	}
+}
+
+// IfMatchVersion mengembalikan version dari If-Match; 0 berarti "*" atau middleware tidak dipasang
+func IfMatchVersion(c *fiber.Ctx) uint {
+	version, _ := c.Locals(ifMatchLocalsKey).(uint)
+	return version
+}
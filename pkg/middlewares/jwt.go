@@ -3,7 +3,9 @@ package middleware
 import (
 	"codebase-api/config"
 	"codebase-api/internal/domain"
+	"codebase-api/pkg/auth"
 	helper "codebase-api/pkg/helpers"
+	"errors"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -19,31 +21,37 @@ func JwtProtected() fiber.Handler {
 			return helper.ErrorResponse(c, fiber.StatusUnauthorized, "Unauthorized", nil)
 		}
 
-		token, _ := jwt.Parse(cookie, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fiber.NewError(fiber.StatusUnauthorized, "Invalid token")
-			}
-			return jwtSecret, nil
-		})
-
-		if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-			c.Locals("id", claims["id"])
-			c.Locals("uuid", claims["uuid"])
-			c.Locals("username", claims["username"])
-			return c.Next()
+		claims, err := ParseJWT(cookie)
+		if err != nil {
+			return helper.ErrorResponse(c, fiber.StatusUnauthorized, "Unauthorized", nil)
 		}
 
-		return helper.ErrorResponse(c, fiber.StatusUnauthorized, "Unauthorized", nil)
+		auth.SetClaims(c, claims)
+		return c.Next()
 	}
 }
 
-func GenerateJWT(user domain.User) (string, error) {
-	claims := jwt.MapClaims{
-		"id":       user.ID,
-		"uuid":     user.UUID,
-		"username": user.Username,
-		"exp":      time.Now().Add(time.Hour * 24).Unix(),
+// ParseJWT memvalidasi token dan mengembalikan claims yang sudah bertipe
+func ParseJWT(tokenString string) (*auth.Claims, error) {
+	claims := &auth.Claims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("invalid token")
+		}
+		return jwtSecret, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid || claims.ID == 0 {
+		return nil, errors.New("invalid token")
 	}
+	return claims, nil
+}
+
+// GenerateJWT membuat token baru; authTime dipertahankan saat refresh dan diperbarui saat login/reauthenticate
+func GenerateJWT(user domain.User, authTime time.Time) (string, error) {
+	claims := auth.NewClaims(user, authTime, time.Hour*24)
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString(jwtSecret)
 }
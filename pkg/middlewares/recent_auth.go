@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"codebase-api/pkg/auth"
+	helper "codebase-api/pkg/helpers"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const ReauthenticationRequired = "reauthentication_required"
+
+// DefaultRecentAuthMaxAge adalah masa berlaku elevasi setelah reauthenticate
+const DefaultRecentAuthMaxAge = 5 * time.Minute
+
+// RequireRecentAuth menolak request jika user tidak login / reauthenticate dalam rentang maxAge.
+// Harus dipasang setelah JwtProtected.
+func RequireRecentAuth(maxAge time.Duration) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claims, ok := auth.CurrentUser(c)
+		if !ok {
+			return helper.ErrorResponse(c, fiber.StatusUnauthorized, "Unauthorized", nil)
+		}
+
+		if !claims.AuthenticatedWithin(maxAge) {
+			return helper.ErrorCodeResponse(c, fiber.StatusUnauthorized, ReauthenticationRequired,
+				"Please reauthenticate to continue", fiber.Map{
+					"reauthenticate_url": "/api/v1/auth/reauthenticate",
+					"max_age":            int(maxAge.Seconds()),
+				})
+		}
+
+		return c.Next()
+	}
+}
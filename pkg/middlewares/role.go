@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"codebase-api/pkg/auth"
+	helper "codebase-api/pkg/helpers"
+	"slices"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequireRole hanya meneruskan request dari user dengan salah satu role yang diberikan.
+// Harus dipasang setelah JwtProtected dan LoadUser.
+func RequireRole(roles ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := auth.User(c)
+		if !ok {
+			return helper.ErrorResponse(c, fiber.StatusUnauthorized, "Unauthorized", nil)
+		}
+
+		if !slices.Contains(roles, user.Role) {
+			return helper.ErrorResponse(c, fiber.StatusForbidden, "Forbidden", nil)
+		}
+
+		return c.Next()
+	}
+}
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"codebase-api/config"
+	"codebase-api/pkg/scim"
+	"crypto/subtle"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const scimTenantLocalsKey = "scim.tenant"
+
+// ScimAuth mengautentikasi IdP dengan bearer token khusus per tenant (SCIM_TOKENS)
+func ScimAuth() fiber.Handler {
+	tokens := config.GetScimTokens()
+
+	return func(c *fiber.Ctx) error {
+		header := c.Get(fiber.HeaderAuthorization)
+		bearer, ok := strings.CutPrefix(header, "Bearer ")
+		if !ok || bearer == "" {
+			return scimUnauthorized(c)
+		}
+
+		for token, tenant := range tokens {
+			if subtle.ConstantTimeCompare([]byte(token), []byte(bearer)) == 1 {
+				c.Locals(scimTenantLocalsKey, tenant)
+				return c.Next()
+			}
+		}
+
+		return scimUnauthorized(c)
+	}
+}
+
+// ScimTenant mengembalikan tenant pemilik token SCIM pada request
+func ScimTenant(c *fiber.Ctx) string {
+	tenant, _ := c.Locals(scimTenantLocalsKey).(string)
+	return tenant
+}
+
+func scimUnauthorized(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderWWWAuthenticate, `Bearer realm="scim"`)
+	return c.Status(fiber.StatusUnauthorized).JSON(scim.NewError(fiber.StatusUnauthorized, "", "Authorization failure"), scim.ContentType)
+}
@@ -0,0 +1,151 @@
+package preferences
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+)
+
+type Kind int
+
+const (
+	String Kind = iota
+	Bool
+	Int
+)
+
+// Definition adalah aturan satu key preferensi; Allowed membatasi nilai String, Validate untuk aturan lain
+type Definition struct {
+	Kind     Kind
+	Default  interface{}
+	Allowed  []string
+	Validate func(value interface{}) error
+}
+
+// Schema berisi semua key yang dikenal; key bertingkat memakai titik, mis. "notifications.email"
+type Schema map[string]Definition
+
+// Values adalah preferensi hasil resolve (default + nilai milik user)
+type Values map[string]interface{}
+
+// String mengembalikan nilai string, atau "" jika key tidak ada / bukan string
+func (v Values) String(key string) string {
+	s, _ := v[key].(string)
+	return s
+}
+
+func (v Values) Bool(key string) bool {
+	b, _ := v[key].(bool)
+	return b
+}
+
+func (v Values) Int(key string) int {
+	i, _ := v[key].(int)
+	return i
+}
+
+func (s Schema) Defaults() Values {
+	values := make(Values, len(s))
+	for key, def := range s {
+		values[key] = def.Default
+	}
+	return values
+}
+
+// Resolve menggabungkan default dengan nilai tersimpan; key yang tidak lagi dikenal atau
+// nilainya tidak valid (mis. setelah schema berubah) diabaikan
+func (s Schema) Resolve(stored map[string]interface{}) Values {
+	values := s.Defaults()
+	for key, raw := range stored {
+		def, ok := s[key]
+		if !ok {
+			continue
+		}
+		if value, err := def.normalize(raw); err == nil {
+			values[key] = value
+		}
+	}
+	return values
+}
+
+// Merge menerapkan patch ke nilai tersimpan dengan semantik merge: key yang tidak disebut tetap,
+// null mengembalikan key ke default. Hasilnya hanya berisi nilai yang berbeda dari default
+func (s Schema) Merge(stored map[string]interface{}, patch map[string]interface{}) (map[string]interface{}, map[string]string) {
+	errs := map[string]string{}
+	merged := maps.Clone(s.Resolve(stored))
+
+	for key, raw := range patch {
+		def, ok := s[key]
+		if !ok {
+			errs[key] = "Unknown preference"
+			continue
+		}
+		if raw == nil {
+			merged[key] = def.Default
+			continue
+		}
+
+		value, err := def.normalize(raw)
+		if err != nil {
+			errs[key] = err.Error()
+			continue
+		}
+		merged[key] = value
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	overrides := map[string]interface{}{}
+	for key, value := range merged {
+		if value != s[key].Default {
+			overrides[key] = value
+		}
+	}
+	return overrides, nil
+}
+
+// normalize memeriksa tipe nilai (hasil decode JSON) dan mengubahnya ke tipe Go untuk Kind tersebut
+func (d Definition) normalize(raw interface{}) (interface{}, error) {
+	var value interface{}
+
+	switch d.Kind {
+	case String:
+		s, ok := raw.(string)
+		if !ok {
+			return nil, errors.New("Must be a string")
+		}
+		if len(d.Allowed) > 0 && !slices.Contains(d.Allowed, s) {
+			return nil, fmt.Errorf("Must be one of %v", d.Allowed)
+		}
+		value = s
+	case Bool:
+		b, ok := raw.(bool)
+		if !ok {
+			return nil, errors.New("Must be a boolean")
+		}
+		value = b
+	case Int:
+		switch n := raw.(type) {
+		case int:
+			value = n
+		case float64:
+			// JSON number selalu float64; tolak pecahan dan nilai di luar jangkauan int64
+			if n != math.Trunc(n) || n < math.MinInt64 || n >= math.MaxInt64 {
+				return nil, errors.New("Must be an integer")
+			}
+			value = int(n)
+		default:
+			return nil, errors.New("Must be an integer")
+		}
+	}
+
+	if d.Validate != nil {
+		if err := d.Validate(value); err != nil {
+			return nil, err
+		}
+	}
+	return value, nil
+}
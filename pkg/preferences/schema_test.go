@@ -0,0 +1,99 @@
+package preferences
+
+import (
+	"errors"
+	"maps"
+	"testing"
+)
+
+var testSchema = Schema{
+	"theme": {Kind: String, Default: "system", Allowed: []string{"system", "light", "dark"}},
+	"email": {Kind: Bool, Default: true},
+	"limit": {Kind: Int, Default: 20},
+	"prefix": {Kind: String, Default: "", Validate: func(value interface{}) error {
+		if len(value.(string)) > 3 {
+			return errors.New("Too long")
+		}
+		return nil
+	}},
+}
+
+func TestMerge(t *testing.T) {
+	tests := []struct {
+		name   string
+		stored map[string]interface{}
+		patch  map[string]interface{}
+		want   map[string]interface{}
+	}{
+		{name: "set value", patch: map[string]interface{}{"theme": "dark"}, want: map[string]interface{}{"theme": "dark"}},
+		{name: "keeps keys not in patch", stored: map[string]interface{}{"theme": "dark"}, patch: map[string]interface{}{"email": false}, want: map[string]interface{}{"theme": "dark", "email": false}},
+		{name: "null resets to default", stored: map[string]interface{}{"theme": "dark", "email": false}, patch: map[string]interface{}{"theme": nil}, want: map[string]interface{}{"email": false}},
+		{name: "default value is not stored", stored: map[string]interface{}{"limit": float64(50)}, patch: map[string]interface{}{"limit": float64(20)}, want: map[string]interface{}{}},
+		{name: "whole float becomes int", patch: map[string]interface{}{"limit": float64(50)}, want: map[string]interface{}{"limit": 50}},
+		{name: "int from go code", patch: map[string]interface{}{"limit": 7}, want: map[string]interface{}{"limit": 7}},
+		{name: "drops stale stored keys", stored: map[string]interface{}{"removed": "x"}, patch: map[string]interface{}{}, want: map[string]interface{}{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, errs := testSchema.Merge(tt.stored, tt.patch)
+			if errs != nil {
+				t.Fatalf("errors = %v", errs)
+			}
+			if !maps.Equal(got, tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeRejectsInvalidValues(t *testing.T) {
+	tests := []struct {
+		name  string
+		patch map[string]interface{}
+		key   string
+	}{
+		{name: "unknown key", patch: map[string]interface{}{"colour": "red"}, key: "colour"},
+		{name: "not allowed", patch: map[string]interface{}{"theme": "neon"}, key: "theme"},
+		{name: "string for bool", patch: map[string]interface{}{"email": "false"}, key: "email"},
+		{name: "fraction for int", patch: map[string]interface{}{"limit": 2.5}, key: "limit"},
+		{name: "int overflow", patch: map[string]interface{}{"limit": 1e19}, key: "limit"},
+		{name: "string for int", patch: map[string]interface{}{"limit": "10"}, key: "limit"},
+		{name: "custom validation", patch: map[string]interface{}{"prefix": "abcd"}, key: "prefix"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Satu nilai salah membatalkan seluruh patch, termasuk nilai lain yang valid
+			patch := maps.Clone(tt.patch)
+			if tt.key != "theme" {
+				patch["theme"] = "dark"
+			}
+
+			got, errs := testSchema.Merge(nil, patch)
+			if got != nil || errs[tt.key] == "" {
+				t.Fatalf("got %v, errors = %v; want an error for %q", got, errs, tt.key)
+			}
+			if len(errs) != 1 {
+				t.Fatalf("errors = %v, want only %q", errs, tt.key)
+			}
+		})
+	}
+}
+
+func TestResolveIgnoresInvalidStoredValues(t *testing.T) {
+	values := testSchema.Resolve(map[string]interface{}{
+		"theme":   "neon",
+		"email":   false,
+		"limit":   float64(50),
+		"removed": true,
+	})
+
+	want := Values{"theme": "system", "email": false, "limit": 50, "prefix": ""}
+	if !maps.Equal(values, want) {
+		t.Fatalf("got %v, want %v", values, want)
+	}
+	if values.Int("limit") != 50 || values.Bool("email") || values.String("theme") != "system" {
+		t.Fatalf("typed accessors = %d %t %q", values.Int("limit"), values.Bool("email"), values.String("theme"))
+	}
+}
@@ -0,0 +1,214 @@
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Operator string
+
+const (
+	Eq   Operator = "eq"
+	Ne   Operator = "ne"
+	Like Operator = "like"
+	Gt   Operator = "gt"
+	Gte  Operator = "gte"
+	Lt   Operator = "lt"
+	Lte  Operator = "lte"
+	In   Operator = "in"
+)
+
+type FieldType int
+
+const (
+	String FieldType = iota
+	Bool
+	Number
+	Time
+)
+
+// Field adalah aturan untuk satu field publik: kolom database, tipe, operator yang diizinkan dan apakah bisa di-sort
+type Field struct {
+	Column    string
+	Type      FieldType
+	Operators []Operator
+	Sortable  bool
+}
+
+// Schema adalah allow-list field per resource, dengan key berupa nama field pada query string
+type Schema map[string]Field
+
+type Filter struct {
+	Field string
+	Op    Operator
+	Value interface{}
+}
+
+type Sort struct {
+	Field string
+	Desc  bool
+}
+
+// ListQuery adalah hasil parsing sort=-created_at,username dan filter[email][like]=...
+type ListQuery struct {
+	Filters []Filter
+	Sorts   []Sort
+	// Select membatasi kolom yang diambil; kosong berarti semua kolom
+	Select []string
+}
+
+// Error dikembalikan untuk query yang tidak valid, ditampilkan ke client sebagai 400
+type Error struct {
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func errorf(format string, args ...interface{}) error {
+	return &Error{Message: fmt.Sprintf(format, args...)}
+}
+
+var filterKeyPattern = regexp.MustCompile(`^filter\[([^\[\]]+)\](?:\[([^\[\]]+)\])?$`)
+
+// Parse membaca parameter sort dan filter dari query string berdasarkan schema
+func Parse(params map[string]string, schema Schema) (ListQuery, error) {
+	var q ListQuery
+
+	for key, raw := range params {
+		matches := filterKeyPattern.FindStringSubmatch(key)
+		if matches == nil {
+			continue
+		}
+
+		name, op := matches[1], Operator(strings.ToLower(matches[2]))
+		if op == "" {
+			op = Eq
+		}
+
+		field, ok := schema[name]
+		if !ok {
+			return q, errorf("unknown filter field %q", name)
+		}
+		if !slices.Contains(field.Operators, op) {
+			return q, errorf("operator %q is not allowed on field %q", op, name)
+		}
+
+		value, err := parseValue(field, op, raw)
+		if err != nil {
+			return q, errorf("invalid value for filter %q: %s", name, err.Error())
+		}
+		q.Filters = append(q.Filters, Filter{Field: name, Op: op, Value: value})
+	}
+
+	// Urutan map tidak tetap, urutkan agar SQL yang dihasilkan deterministik
+	slices.SortFunc(q.Filters, func(a, b Filter) int {
+		return strings.Compare(a.Field+string(a.Op), b.Field+string(b.Op))
+	})
+
+	if sort := params["sort"]; sort != "" {
+		for _, part := range strings.Split(sort, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+
+			s := Sort{Field: part}
+			if strings.HasPrefix(part, "-") {
+				s = Sort{Field: part[1:], Desc: true}
+			}
+
+			field, ok := schema[s.Field]
+			if !ok || !field.Sortable {
+				return q, errorf("unknown sort field %q", s.Field)
+			}
+			q.Sorts = append(q.Sorts, s)
+		}
+	}
+
+	return q, nil
+}
+
+func parseValue(field Field, op Operator, raw string) (interface{}, error) {
+	if op == In {
+		var values []interface{}
+		for _, part := range strings.Split(raw, ",") {
+			value, err := convert(field.Type, strings.TrimSpace(part))
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		return values, nil
+	}
+
+	if op == Like {
+		return raw, nil
+	}
+	return convert(field.Type, raw)
+}
+
+func convert(fieldType FieldType, raw string) (interface{}, error) {
+	switch fieldType {
+	case Bool:
+		return strconv.ParseBool(raw)
+	case Number:
+		return strconv.ParseFloat(raw, 64)
+	case Time:
+		for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02"} {
+			if t, err := time.ParseInLocation(layout, raw, time.Local); err == nil {
+				return t, nil
+			}
+		}
+		return nil, fmt.Errorf("expected a date (2006-01-02) or RFC 3339 timestamp")
+	}
+	return raw, nil
+}
+
+// Apply menerapkan filter dan sort ke query GORM; nama kolom hanya diambil dari schema
+func (q ListQuery) Apply(db *gorm.DB, schema Schema) *gorm.DB {
+	if len(q.Select) > 0 {
+		db = db.Select(q.Select)
+	}
+
+	for _, f := range q.Filters {
+		column := clause.Column{Name: schema[f.Field].Column}
+
+		switch f.Op {
+		case Eq:
+			db = db.Where(clause.Eq{Column: column, Value: f.Value})
+		case Ne:
+			db = db.Where(clause.Neq{Column: column, Value: f.Value})
+		case Gt:
+			db = db.Where(clause.Gt{Column: column, Value: f.Value})
+		case Gte:
+			db = db.Where(clause.Gte{Column: column, Value: f.Value})
+		case Lt:
+			db = db.Where(clause.Lt{Column: column, Value: f.Value})
+		case Lte:
+			db = db.Where(clause.Lte{Column: column, Value: f.Value})
+		case In:
+			db = db.Where(clause.IN{Column: column, Values: f.Value.([]interface{})})
+		case Like:
+			db = db.Where(clause.Like{Column: column, Value: "%" + EscapeLike(f.Value.(string)) + "%"})
+		}
+	}
+
+	for _, s := range q.Sorts {
+		db = db.Order(clause.OrderByColumn{Column: clause.Column{Name: schema[s.Field].Column}, Desc: s.Desc})
+	}
+	return db
+}
+
+// EscapeLike meng-escape wildcard LIKE (%, _ dan backslash) agar nilai dari client dicocokkan apa adanya
+func EscapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
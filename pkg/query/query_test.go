@@ -0,0 +1,172 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var testSchema = Schema{
+	"name":       {Column: "name", Type: String, Operators: []Operator{Eq, Ne, Like, In}, Sortable: true},
+	"score":      {Column: "score", Type: Number, Operators: []Operator{Eq, Gt, Lte, In}, Sortable: true},
+	"active":     {Column: "is_active", Type: Bool, Operators: []Operator{Eq}},
+	"created_at": {Column: "created_at", Type: Time, Operators: []Operator{Gte}, Sortable: true},
+}
+
+func TestParse(t *testing.T) {
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.Local)
+	tests := []struct {
+		name   string
+		params map[string]string
+		want   ListQuery
+	}{
+		{name: "empty", params: map[string]string{}, want: ListQuery{}},
+		{name: "ignores other params", params: map[string]string{"page": "2", "search": "budi", "filters[name]": "x"}, want: ListQuery{}},
+		{name: "default operator is eq", params: map[string]string{"filter[name]": "budi"}, want: ListQuery{Filters: []Filter{{Field: "name", Op: Eq, Value: "budi"}}}},
+		{name: "operator is case-insensitive", params: map[string]string{"filter[score][GT]": "10"}, want: ListQuery{Filters: []Filter{{Field: "score", Op: Gt, Value: float64(10)}}}},
+		{name: "bool", params: map[string]string{"filter[active]": "false"}, want: ListQuery{Filters: []Filter{{Field: "active", Op: Eq, Value: false}}}},
+		{name: "date", params: map[string]string{"filter[created_at][gte]": "2024-05-01"}, want: ListQuery{Filters: []Filter{{Field: "created_at", Op: Gte, Value: day}}}},
+		{name: "in converts every value", params: map[string]string{"filter[score][in]": "1, 2.5"}, want: ListQuery{Filters: []Filter{{Field: "score", Op: In, Value: []interface{}{float64(1), 2.5}}}}},
+		{name: "like keeps raw value", params: map[string]string{"filter[name][like]": "50%_off"}, want: ListQuery{Filters: []Filter{{Field: "name", Op: Like, Value: "50%_off"}}}},
+		{
+			name:   "filters are ordered by field and operator",
+			params: map[string]string{"filter[score][lte]": "9", "filter[name]": "a", "filter[score][gt]": "1"},
+			want: ListQuery{Filters: []Filter{
+				{Field: "name", Op: Eq, Value: "a"},
+				{Field: "score", Op: Gt, Value: float64(1)},
+				{Field: "score", Op: Lte, Value: float64(9)},
+			}},
+		},
+		{
+			name:   "multi-key sort keeps request order",
+			params: map[string]string{"sort": "-score, name,,-created_at"},
+			want:   ListQuery{Sorts: []Sort{{Field: "score", Desc: true}, {Field: "name"}, {Field: "created_at", Desc: true}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.params, testSchema)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRejectsInvalidQueries(t *testing.T) {
+	tests := []struct {
+		name   string
+		params map[string]string
+	}{
+		{name: "unknown filter field", params: map[string]string{"filter[password]": "x"}},
+		{name: "column name is not a field", params: map[string]string{"filter[is_active]": "true"}},
+		{name: "disallowed operator", params: map[string]string{"filter[active][like]": "t"}},
+		{name: "unknown operator", params: map[string]string{"filter[name][regex]": "a.*"}},
+		{name: "invalid bool", params: map[string]string{"filter[active]": "yes"}},
+		{name: "invalid number", params: map[string]string{"filter[score][gt]": "ten"}},
+		{name: "invalid number in list", params: map[string]string{"filter[score][in]": "1,two"}},
+		{name: "invalid date", params: map[string]string{"filter[created_at][gte]": "01/05/2024"}},
+		{name: "unknown sort field", params: map[string]string{"sort": "password"}},
+		{name: "field is not sortable", params: map[string]string{"sort": "-active"}},
+		{name: "one bad key in a multi-key sort", params: map[string]string{"sort": "name,-secret"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.params, testSchema)
+			var queryErr *Error
+			if !errors.As(err, &queryErr) {
+				t.Fatalf("err = %v, want *query.Error", err)
+			}
+		})
+	}
+}
+
+func TestEscapeLike(t *testing.T) {
+	tests := map[string]string{
+		"budi":      "budi",
+		"50%":       `50\%`,
+		"first_one": `first\_one`,
+		`C:\temp`:   `C:\\temp`,
+		`\%_`:       `\\\%\_`,
+	}
+	for input, want := range tests {
+		if got := EscapeLike(input); got != want {
+			t.Errorf("EscapeLike(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+type item struct {
+	ID        uint
+	Name      string
+	Score     float64
+	IsActive  bool
+	CreatedAt time.Time
+}
+
+func openItems(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&item{}); err != nil {
+		t.Fatal(err)
+	}
+	items := []item{
+		{Name: "budi", Score: 10, IsActive: true},
+		{Name: "andi", Score: 20, IsActive: true},
+		{Name: "siti", Score: 10, IsActive: false},
+		{Name: "joko", Score: 20, IsActive: true},
+	}
+	if err := db.Create(&items).Error; err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestApplyFiltersAndSortsByMultipleKeys(t *testing.T) {
+	db := openItems(t)
+
+	q, err := Parse(map[string]string{"filter[active]": "true", "sort": "-score,name"}, testSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var items []item
+	if err := q.Apply(db.Model(&item{}), testSchema).Find(&items).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, it := range items {
+		names = append(names, it.Name)
+	}
+	if fmt.Sprint(names) != "[andi joko budi]" {
+		t.Fatalf("names = %v", names)
+	}
+}
+
+func TestApplyEscapesLikeValue(t *testing.T) {
+	db := openItems(t)
+
+	q, err := Parse(map[string]string{"filter[name][like]": `50%_off\`}, testSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stmt := q.Apply(db.Session(&gorm.Session{DryRun: true}).Model(&item{}), testSchema).Find(&[]item{}).Statement
+	if !reflect.DeepEqual(stmt.Vars, []interface{}{`%50\%\_off\\%`}) {
+		t.Fatalf("vars = %v", stmt.Vars)
+	}
+}
@@ -0,0 +1,383 @@
+package scim
+
+import (
+	"codebase-api/pkg/query"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Filter adalah hasil parsing ekspresi filter SCIM (RFC 7644 section 3.4.2.2)
+type Filter interface {
+	isFilter()
+}
+
+// LogicalExpr menggabungkan dua filter dengan "and" / "or"
+type LogicalExpr struct {
+	Op    string
+	Left  Filter
+	Right Filter
+}
+
+type NotExpr struct {
+	Expr Filter
+}
+
+// AttrExpr adalah perbandingan tunggal, mis. userName eq "bjensen"
+type AttrExpr struct {
+	Path  string
+	Op    string
+	Value interface{}
+}
+
+// ValuePathExpr adalah filter pada multi-valued attribute, mis. emails[value co "@example.com"]
+type ValuePathExpr struct {
+	Path   string
+	Filter Filter
+}
+
+func (LogicalExpr) isFilter()   {}
+func (NotExpr) isFilter()       {}
+func (AttrExpr) isFilter()      {}
+func (ValuePathExpr) isFilter() {}
+
+var compareOps = map[string]bool{
+	"eq": true, "ne": true, "co": true, "sw": true, "ew": true,
+	"gt": true, "ge": true, "lt": true, "le": true,
+}
+
+type token struct {
+	kind  string // ident, string, number, lparen, rparen, lbracket, rbracket
+	value string
+}
+
+func tokenize(input string) ([]token, error) {
+	var tokens []token
+	runes := []rune(input)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{kind: "lparen"})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: "rparen"})
+			i++
+		case r == '[':
+			tokens = append(tokens, token{kind: "lbracket"})
+			i++
+		case r == ']':
+			tokens = append(tokens, token{kind: "rbracket"})
+			i++
+		case r == '"':
+			j := i + 1
+			for ; j < len(runes); j++ {
+				if runes[j] == '\\' {
+					j++
+					continue
+				}
+				if runes[j] == '"' {
+					break
+				}
+			}
+			if j >= len(runes) {
+				return nil, errors.New("unterminated string in filter")
+			}
+
+			var value string
+			if err := json.Unmarshal([]byte(string(runes[i:j+1])), &value); err != nil {
+				return nil, fmt.Errorf("invalid string in filter: %w", err)
+			}
+			tokens = append(tokens, token{kind: "string", value: value})
+			i = j + 1
+		default:
+			j := i
+			for j < len(runes) && !unicode.IsSpace(runes[j]) && !strings.ContainsRune(`()[]"`, runes[j]) {
+				j++
+			}
+			word := string(runes[i:j])
+			if _, err := strconv.ParseFloat(word, 64); err == nil {
+				tokens = append(tokens, token{kind: "number", value: word})
+			} else {
+				tokens = append(tokens, token{kind: "ident", value: word})
+			}
+			i = j
+		}
+	}
+
+	return tokens, nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// ParseFilter mengubah string filter SCIM menjadi Filter
+func ParseFilter(input string) (Filter, error) {
+	tokens, err := tokenize(input)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, errors.New("empty filter")
+	}
+
+	p := &parser{tokens: tokens}
+	filter, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected token %q in filter", p.tokens[p.pos].value)
+	}
+	return filter, nil
+}
+
+func (p *parser) peek() *token {
+	if p.pos >= len(p.tokens) {
+		return nil
+	}
+	return &p.tokens[p.pos]
+}
+
+func (p *parser) next() *token {
+	t := p.peek()
+	if t != nil {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) peekKeyword(keyword string) bool {
+	t := p.peek()
+	return t != nil && t.kind == "ident" && strings.EqualFold(t.value, keyword)
+}
+
+func (p *parser) expect(kind string) error {
+	t := p.next()
+	if t == nil || t.kind != kind {
+		return fmt.Errorf("expected %s in filter", kind)
+	}
+	return nil
+}
+
+func (p *parser) parseOr() (Filter, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekKeyword("or") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = LogicalExpr{Op: "or", Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (Filter, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekKeyword("and") {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = LogicalExpr{Op: "and", Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (Filter, error) {
+	if p.peekKeyword("not") {
+		p.next()
+		if err := p.expect("lparen"); err != nil {
+			return nil, err
+		}
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("rparen"); err != nil {
+			return nil, err
+		}
+		return NotExpr{Expr: expr}, nil
+	}
+
+	if t := p.peek(); t != nil && t.kind == "lparen" {
+		p.next()
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("rparen"); err != nil {
+			return nil, err
+		}
+		return expr, nil
+	}
+
+	return p.parseAttrExpr()
+}
+
+func (p *parser) parseAttrExpr() (Filter, error) {
+	t := p.next()
+	if t == nil || t.kind != "ident" {
+		return nil, errors.New("expected attribute path in filter")
+	}
+	path := t.value
+
+	if next := p.peek(); next != nil && next.kind == "lbracket" {
+		p.next()
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("rbracket"); err != nil {
+			return nil, err
+		}
+		return ValuePathExpr{Path: path, Filter: inner}, nil
+	}
+
+	opToken := p.next()
+	if opToken == nil || opToken.kind != "ident" {
+		return nil, fmt.Errorf("expected operator after %q", path)
+	}
+	op := strings.ToLower(opToken.value)
+
+	if op == "pr" {
+		return AttrExpr{Path: path, Op: op}, nil
+	}
+	if !compareOps[op] {
+		return nil, fmt.Errorf("unsupported operator %q", opToken.value)
+	}
+
+	valueToken := p.next()
+	if valueToken == nil {
+		return nil, fmt.Errorf("expected value after %q %s", path, op)
+	}
+
+	var value interface{}
+	switch valueToken.kind {
+	case "string":
+		value = valueToken.value
+	case "number":
+		value, _ = strconv.ParseFloat(valueToken.value, 64)
+	case "ident":
+		switch strings.ToLower(valueToken.value) {
+		case "true":
+			value = true
+		case "false":
+			value = false
+		case "null":
+			value = nil
+		default:
+			return nil, fmt.Errorf("invalid value %q", valueToken.value)
+		}
+	default:
+		return nil, fmt.Errorf("invalid value after %q %s", path, op)
+	}
+
+	return AttrExpr{Path: path, Op: op, Value: value}, nil
+}
+
+// ToSQL menerjemahkan filter menjadi klausa WHERE. columns memetakan attribute path
+// (huruf kecil, mis. "name.givenname") ke nama kolom database.
+func ToSQL(filter Filter, columns map[string]string) (string, []interface{}, error) {
+	return toSQL(filter, "", columns)
+}
+
+func toSQL(filter Filter, prefix string, columns map[string]string) (string, []interface{}, error) {
+	switch f := filter.(type) {
+	case LogicalExpr:
+		left, leftArgs, err := toSQL(f.Left, prefix, columns)
+		if err != nil {
+			return "", nil, err
+		}
+		right, rightArgs, err := toSQL(f.Right, prefix, columns)
+		if err != nil {
+			return "", nil, err
+		}
+		return "(" + left + " " + strings.ToUpper(f.Op) + " " + right + ")", append(leftArgs, rightArgs...), nil
+	case NotExpr:
+		inner, args, err := toSQL(f.Expr, prefix, columns)
+		if err != nil {
+			return "", nil, err
+		}
+		return "NOT (" + inner + ")", args, nil
+	case ValuePathExpr:
+		return toSQL(f.Filter, f.Path+".", columns)
+	case AttrExpr:
+		return attrToSQL(f, prefix, columns)
+	}
+	return "", nil, errors.New("unsupported filter")
+}
+
+func attrToSQL(f AttrExpr, prefix string, columns map[string]string) (string, []interface{}, error) {
+	path := strings.ToLower(prefix + f.Path)
+	// Buang URN schema, mis. urn:ietf:params:scim:schemas:core:2.0:User:userName
+	if idx := strings.LastIndex(path, ":"); idx >= 0 {
+		path = path[idx+1:]
+	}
+
+	column, ok := columns[path]
+	if !ok {
+		return "", nil, fmt.Errorf("unsupported filter attribute %q", prefix+f.Path)
+	}
+
+	if f.Op == "pr" {
+		return fmt.Sprintf("(%s IS NOT NULL AND %s <> '')", column, column), nil, nil
+	}
+
+	if f.Value == nil {
+		switch f.Op {
+		case "eq":
+			return column + " IS NULL", nil, nil
+		case "ne":
+			return column + " IS NOT NULL", nil, nil
+		}
+		return "", nil, fmt.Errorf("operator %s does not accept null", f.Op)
+	}
+
+	switch f.Op {
+	case "eq":
+		return column + " = ?", []interface{}{f.Value}, nil
+	case "ne":
+		return column + " <> ?", []interface{}{f.Value}, nil
+	case "gt":
+		return column + " > ?", []interface{}{f.Value}, nil
+	case "ge":
+		return column + " >= ?", []interface{}{f.Value}, nil
+	case "lt":
+		return column + " < ?", []interface{}{f.Value}, nil
+	case "le":
+		return column + " <= ?", []interface{}{f.Value}, nil
+	}
+
+	value, ok := f.Value.(string)
+	if !ok {
+		return "", nil, fmt.Errorf("operator %s requires a string value", f.Op)
+	}
+	value = query.EscapeLike(value)
+
+	switch f.Op {
+	case "co":
+		return column + " LIKE ?", []interface{}{"%" + value + "%"}, nil
+	case "sw":
+		return column + " LIKE ?", []interface{}{value + "%"}, nil
+	case "ew":
+		return column + " LIKE ?", []interface{}{"%" + value}, nil
+	}
+	return "", nil, fmt.Errorf("unsupported operator %q", f.Op)
+}
@@ -0,0 +1,124 @@
+package scim
+
+import (
+	"reflect"
+	"testing"
+)
+
+var testColumns = map[string]string{
+	"username":       "username",
+	"name.givenname": "first_name",
+	"emails.value":   "email",
+	"emails.type":    "email_type",
+	"active":         "is_active",
+	"meta.created":   "created_at",
+}
+
+func TestFilterToSQL(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter string
+		sql    string
+		args   []interface{}
+	}{
+		{name: "eq", filter: `userName eq "bjensen"`, sql: "username = ?", args: []interface{}{"bjensen"}},
+		{name: "case-insensitive attribute and operator", filter: `USERNAME EQ "bjensen"`, sql: "username = ?", args: []interface{}{"bjensen"}},
+		{name: "schema urn", filter: `urn:ietf:params:scim:schemas:core:2.0:User:userName eq "bjensen"`, sql: "username = ?", args: []interface{}{"bjensen"}},
+		{name: "sub-attribute", filter: `name.givenName sw "Bar"`, sql: "first_name LIKE ?", args: []interface{}{"Bar%"}},
+		{name: "co escapes like wildcards", filter: `userName co "50%_off"`, sql: "username LIKE ?", args: []interface{}{`%50\%\_off%`}},
+		{name: "ew", filter: `emails.value ew "@example.com"`, sql: "email LIKE ?", args: []interface{}{"%@example.com"}},
+		{name: "escaped quote", filter: `userName eq "say \"hi\""`, sql: "username = ?", args: []interface{}{`say "hi"`}},
+		{name: "boolean", filter: `active eq true`, sql: "is_active = ?", args: []interface{}{true}},
+		{name: "number", filter: `meta.created gt 10`, sql: "created_at > ?", args: []interface{}{float64(10)}},
+		{name: "null", filter: `userName eq null`, sql: "username IS NULL"},
+		{name: "ne null", filter: `userName ne null`, sql: "username IS NOT NULL"},
+		{name: "present", filter: `userName pr`, sql: "(username IS NOT NULL AND username <> '')"},
+		{
+			name:   "and binds tighter than or",
+			filter: `userName eq "a" or userName eq "b" and active eq true`,
+			sql:    "(username = ? OR (username = ? AND is_active = ?))",
+			args:   []interface{}{"a", "b", true},
+		},
+		{
+			name:   "parentheses override precedence",
+			filter: `(userName eq "a" or userName eq "b") and active eq true`,
+			sql:    "((username = ? OR username = ?) AND is_active = ?)",
+			args:   []interface{}{"a", "b", true},
+		},
+		{
+			name:   "or is left associative",
+			filter: `userName eq "a" or userName eq "b" or userName eq "c"`,
+			sql:    "((username = ? OR username = ?) OR username = ?)",
+			args:   []interface{}{"a", "b", "c"},
+		},
+		{name: "not", filter: `not (active eq false)`, sql: "NOT (is_active = ?)", args: []interface{}{false}},
+		{
+			name:   "value path",
+			filter: `emails[type eq "work" and value co "@example.com"]`,
+			sql:    "(email_type = ? AND email LIKE ?)",
+			args:   []interface{}{"work", "%@example.com%"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := ParseFilter(tt.filter)
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			sql, args, err := ToSQL(filter, testColumns)
+			if err != nil {
+				t.Fatalf("to sql: %v", err)
+			}
+			if sql != tt.sql || !reflect.DeepEqual(args, tt.args) {
+				t.Fatalf("got %q %v, want %q %v", sql, args, tt.sql, tt.args)
+			}
+		})
+	}
+}
+
+func TestParseFilterRejectsInvalidInput(t *testing.T) {
+	tests := []string{
+		``,
+		`   `,
+		`userName`,
+		`userName eq`,
+		`userName regex "a.*"`,
+		`userName eq "unterminated`,
+		`userName eq bjensen`,
+		`(userName eq "a"`,
+		`userName eq "a")`,
+		`emails[value eq "a"`,
+		`not userName eq "a"`,
+		`userName eq "a" and`,
+		`userName eq "a" userName eq "b"`,
+		`eq "a"`,
+	}
+	for _, filter := range tests {
+		t.Run(filter, func(t *testing.T) {
+			if parsed, err := ParseFilter(filter); err == nil {
+				t.Fatalf("accepted %q as %#v", filter, parsed)
+			}
+		})
+	}
+}
+
+func TestFilterToSQLRejectsUnsupportedAttributes(t *testing.T) {
+	tests := []string{
+		`password eq "secret"`,
+		`emails[display eq "x"]`,
+		`userName gt null`,
+		`active co 1`,
+	}
+	for _, filter := range tests {
+		t.Run(filter, func(t *testing.T) {
+			parsed, err := ParseFilter(filter)
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			if sql, _, err := ToSQL(parsed, testColumns); err == nil {
+				t.Fatalf("translated %q to %q", filter, sql)
+			}
+		})
+	}
+}
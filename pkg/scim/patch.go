@@ -0,0 +1,163 @@
+package scim
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var valueFilterPattern = regexp.MustCompile(`\[[^\]]*\]`)
+
+// ApplyPatch menerapkan operasi PATCH (add / replace / remove) pada resource User
+func ApplyPatch(user *User, ops []PatchOperation) error {
+	for _, op := range ops {
+		kind := strings.ToLower(op.Op)
+		switch kind {
+		case "add", "replace":
+			if op.Path == "" {
+				// Tanpa path, value berupa object berisi attribute -> value
+				values, ok := op.Value.(map[string]interface{})
+				if !ok {
+					return fmt.Errorf("%s without path requires an object value", kind)
+				}
+				for path, value := range values {
+					if err := setAttribute(user, path, value); err != nil {
+						return err
+					}
+				}
+				continue
+			}
+			if err := setAttribute(user, op.Path, op.Value); err != nil {
+				return err
+			}
+		case "remove":
+			if op.Path == "" {
+				return fmt.Errorf("remove requires a path")
+			}
+			if err := setAttribute(user, op.Path, nil); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unsupported patch operation %q", op.Op)
+		}
+	}
+	return nil
+}
+
+func normalizePath(path string) string {
+	path = strings.ToLower(path)
+	if idx := strings.LastIndex(path, ":"); idx >= 0 {
+		path = path[idx+1:]
+	}
+	// emails[type eq "work"].value -> emails.value
+	return valueFilterPattern.ReplaceAllString(path, "")
+}
+
+func setAttribute(user *User, path string, value interface{}) error {
+	switch normalizePath(path) {
+	case "username":
+		return assignString(&user.UserName, value)
+	case "externalid":
+		return assignString(&user.ExternalID, value)
+	case "displayname":
+		return assignString(&user.DisplayName, value)
+	case "name":
+		if value == nil {
+			user.Name = Name{}
+			return nil
+		}
+		return remarshal(value, &user.Name)
+	case "name.givenname":
+		return assignString(&user.Name.GivenName, value)
+	case "name.familyname":
+		return assignString(&user.Name.FamilyName, value)
+	case "name.formatted":
+		return assignString(&user.Name.Formatted, value)
+	case "active":
+		if value == nil {
+			user.Active = nil
+			return nil
+		}
+		active, err := toBool(value)
+		if err != nil {
+			return err
+		}
+		user.Active = &active
+		return nil
+	case "password":
+		return assignString(&user.Password, value)
+	case "emails":
+		return assignMultiValue(&user.Emails, value)
+	case "emails.value":
+		return assignPrimaryValue(&user.Emails, value)
+	case "phonenumbers":
+		return assignMultiValue(&user.PhoneNumbers, value)
+	case "phonenumbers.value":
+		return assignPrimaryValue(&user.PhoneNumbers, value)
+	}
+	return fmt.Errorf("unsupported patch path %q", path)
+}
+
+func assignString(target *string, value interface{}) error {
+	if value == nil {
+		*target = ""
+		return nil
+	}
+	s, ok := value.(string)
+	if !ok {
+		return fmt.Errorf("expected string value, got %T", value)
+	}
+	*target = s
+	return nil
+}
+
+func assignMultiValue(target *[]MultiValue, value interface{}) error {
+	if value == nil {
+		*target = nil
+		return nil
+	}
+	var values []MultiValue
+	if err := remarshal(value, &values); err != nil {
+		return err
+	}
+	*target = values
+	return nil
+}
+
+func assignPrimaryValue(target *[]MultiValue, value interface{}) error {
+	if value == nil {
+		*target = nil
+		return nil
+	}
+	s, ok := value.(string)
+	if !ok {
+		return fmt.Errorf("expected string value, got %T", value)
+	}
+	*target = []MultiValue{{Value: s, Type: "work", Primary: true}}
+	return nil
+}
+
+// toBool menerima boolean JSON maupun string "True"/"False" yang dikirim beberapa IdP
+func toBool(value interface{}) (bool, error) {
+	switch v := value.(type) {
+	case bool:
+		return v, nil
+	case string:
+		switch strings.ToLower(v) {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		}
+	}
+	return false, fmt.Errorf("expected boolean value, got %v", value)
+}
+
+func remarshal(value interface{}, target interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, target)
+}
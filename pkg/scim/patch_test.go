@@ -0,0 +1,116 @@
+package scim
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func patchFixture() User {
+	active := true
+	return User{
+		UserName:     "bjensen",
+		ExternalID:   "ext-1",
+		Name:         Name{GivenName: "Barbara", FamilyName: "Jensen"},
+		Emails:       []MultiValue{{Value: "bjensen@example.com", Type: "work", Primary: true}},
+		PhoneNumbers: []MultiValue{{Value: "+628111111111", Type: "work", Primary: true}},
+		Active:       &active,
+	}
+}
+
+// decodeOps mem-parsing operasi dari JSON agar value bertipe sama dengan request sungguhan
+func decodeOps(t *testing.T, raw string) []PatchOperation {
+	t.Helper()
+	var ops []PatchOperation
+	if err := json.Unmarshal([]byte(raw), &ops); err != nil {
+		t.Fatal(err)
+	}
+	return ops
+}
+
+func TestApplyPatch(t *testing.T) {
+	inactive := false
+	tests := []struct {
+		name  string
+		ops   string
+		apply func(*User)
+	}{
+		{
+			name:  "replace simple attribute",
+			ops:   `[{"op":"replace","path":"userName","value":"barbara"}]`,
+			apply: func(u *User) { u.UserName = "barbara" },
+		},
+		{
+			name:  "op and path are case-insensitive",
+			ops:   `[{"op":"Replace","path":"NAME.givenName","value":"Babs"}]`,
+			apply: func(u *User) { u.Name.GivenName = "Babs" },
+		},
+		{
+			name:  "schema urn path",
+			ops:   `[{"op":"replace","path":"urn:ietf:params:scim:schemas:core:2.0:User:name.familyName","value":"Smith"}]`,
+			apply: func(u *User) { u.Name.FamilyName = "Smith" },
+		},
+		{
+			name:  "value filter path",
+			ops:   `[{"op":"replace","path":"emails[type eq \"work\"].value","value":"babs@example.com"}]`,
+			apply: func(u *User) { u.Emails = []MultiValue{{Value: "babs@example.com", Type: "work", Primary: true}} },
+		},
+		{
+			name:  "active as string",
+			ops:   `[{"op":"replace","path":"active","value":"False"}]`,
+			apply: func(u *User) { u.Active = &inactive },
+		},
+		{
+			name:  "replace without path",
+			ops:   `[{"op":"replace","value":{"active":false,"name.givenName":"Babs"}}]`,
+			apply: func(u *User) { u.Active, u.Name.GivenName = &inactive, "Babs" },
+		},
+		{
+			name:  "add multi-valued attribute",
+			ops:   `[{"op":"add","path":"phoneNumbers","value":[{"value":"+628222222222","type":"mobile"}]}]`,
+			apply: func(u *User) { u.PhoneNumbers = []MultiValue{{Value: "+628222222222", Type: "mobile"}} },
+		},
+		{
+			name:  "remove attribute",
+			ops:   `[{"op":"remove","path":"externalId"},{"op":"remove","path":"phoneNumbers"}]`,
+			apply: func(u *User) { u.ExternalID, u.PhoneNumbers = "", nil },
+		},
+		{
+			name:  "operations apply in order",
+			ops:   `[{"op":"replace","path":"displayName","value":"first"},{"op":"replace","path":"displayName","value":"second"}]`,
+			apply: func(u *User) { u.DisplayName = "second" },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, want := patchFixture(), patchFixture()
+			tt.apply(&want)
+			if err := ApplyPatch(&got, decodeOps(t, tt.ops)); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("got %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestApplyPatchRejectsInvalidOperations(t *testing.T) {
+	tests := map[string]string{
+		"unknown op":              `[{"op":"move","path":"userName","value":"x"}]`,
+		"unknown path":            `[{"op":"replace","path":"nickName","value":"x"}]`,
+		"remove without path":     `[{"op":"remove"}]`,
+		"add without path scalar": `[{"op":"add","value":"x"}]`,
+		"wrong value type":        `[{"op":"replace","path":"userName","value":42}]`,
+		"invalid boolean":         `[{"op":"replace","path":"active","value":"yes"}]`,
+	}
+	for name, raw := range tests {
+		t.Run(name, func(t *testing.T) {
+			user := patchFixture()
+			if err := ApplyPatch(&user, decodeOps(t, raw)); err == nil {
+				t.Fatalf("accepted %s", raw)
+			}
+		})
+	}
+}
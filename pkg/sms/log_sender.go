@@ -0,0 +1,27 @@
+package sms
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// LogSender tidak mengirim SMS sungguhan, hanya menulis pesan ke writer (stdout atau file).
+// Dipakai untuk development dan test sampai adapter gateway SMS tersedia
+type LogSender struct {
+	mu  sync.Mutex
+	out io.Writer
+}
+
+func NewLogSender(out io.Writer) *LogSender {
+	return &LogSender{out: out}
+}
+
+func (s *LogSender) Send(to string, message string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err := fmt.Fprintf(s.out, "%s\tSMS to %s: %s\n", time.Now().Format(time.RFC3339), to, message)
+	return err
+}
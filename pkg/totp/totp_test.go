@@ -0,0 +1,72 @@
+package totp
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Secret dan vektor SHA1 dari RFC 6238 Appendix B (diambil 6 digit terakhir)
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestCodeMatchesRFC6238Vectors(t *testing.T) {
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, tt := range tests {
+		got, err := Code(rfcSecret, Step(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("Code at %d = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	code, _ := Code(rfcSecret, Step(now))
+	previous, _ := Code(rfcSecret, Step(now)-1)
+	old, _ := Code(rfcSecret, Step(now)-3)
+
+	if step, ok := Validate(rfcSecret, code, now, 1); !ok || step != Step(now) {
+		t.Fatalf("current code rejected")
+	}
+	if _, ok := Validate(rfcSecret, previous, now, 1); !ok {
+		t.Fatalf("code from previous period rejected within skew")
+	}
+	if _, ok := Validate(rfcSecret, old, now, 1); ok {
+		t.Fatalf("code outside skew accepted")
+	}
+	if _, ok := Validate(rfcSecret, "12345", now, 1); ok {
+		t.Fatalf("short code accepted")
+	}
+}
+
+func TestGenerateSecretRoundTrip(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, err := Code(secret, Step(time.Now()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := Validate(secret, code, time.Now(), 1); !ok {
+		t.Fatalf("generated secret does not validate its own code")
+	}
+	if !strings.HasPrefix(URI("codebase-api", "budi", secret), "otpauth://totp/codebase-api:budi?") {
+		t.Fatalf("unexpected URI %s", URI("codebase-api", "budi", secret))
+	}
+}
@@ -1,12 +1,22 @@
 package router
 
 import (
+	"codebase-api/config"
 	"codebase-api/config/rabbitmq"
 	"codebase-api/config/storage"
+	"codebase-api/internal/domain"
 	"codebase-api/internal/handler"
 	"codebase-api/internal/repository"
 	"codebase-api/internal/usecase"
+	"codebase-api/pkg/auth"
+	"codebase-api/pkg/filestore"
+	"codebase-api/pkg/jobs"
+	"codebase-api/pkg/kvstore"
 	middleware "codebase-api/pkg/middlewares"
+	"codebase-api/pkg/sms"
+	"log"
+	"os"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/streadway/amqp"
@@ -15,9 +25,59 @@ import (
 
 func SetupRoutes(app *fiber.App, db *gorm.DB, ch *amqp.Channel) {
 	userRepo := repository.NewUserRepository(db)
-	userUseCase := usecase.NewUserUseCase(userRepo)
-	userHandler := handler.NewUserHandler(userUseCase)
-	authHandler := handler.NewAuthHandler(userUseCase)
+
+	// Urutan authenticator menentukan urutan percobaan login
+	authenticators := []usecase.Authenticator{usecase.NewLocalAuthenticator(userRepo)}
+	if ldapConfig, ok := config.GetLDAPConfig(); ok {
+		authenticators = append(authenticators, usecase.NewLDAPAuthenticator(ldapConfig, userRepo))
+	}
+
+	preferenceUseCase := usecase.NewPreferenceUseCase(userRepo, 5*time.Minute)
+	preferenceHandler := handler.NewPreferenceHandler(preferenceUseCase)
+
+	userUseCase := usecase.NewUserUseCase(userRepo, authenticators...).WithPreferences(preferenceUseCase)
+	// Counter rate limit / sekali pakai memakai operasi atomik Redis agar aman di banyak instance
+	counters := kvstore.NewRedis(storage.RediStorage)
+	totpUseCase := usecase.NewTOTPUseCase(userRepo, counters, config.GetTOTPIssuer())
+	authHandler := handler.NewAuthHandler(userUseCase, totpUseCase)
+
+	passkeyRepo := repository.NewPasskeyRepository(db)
+
+	scimUseCase := usecase.NewScimUseCase(userRepo)
+
+	// Cache domain.User per request agar handler tidak membaca ulang dari DB
+	userCache := auth.NewUserCache(30 * time.Second)
+	loadUser := middleware.LoadUser(userCache, userUseCase.FindById)
+	totpHandler := handler.NewTOTPHandler(totpUseCase, userCache)
+	scimHandler := handler.NewScimHandler(scimUseCase, userCache)
+
+	// Job background (import, export) disimpan di memori dan dihapus 1 jam setelah selesai
+	jobRegistry := jobs.NewRegistry(time.Hour)
+	jobHandler := handler.NewJobHandler(jobRegistry)
+	userImportHandler := handler.NewUserImportHandler(userUseCase, jobRegistry)
+	userExportHandler := handler.NewUserExportHandler(userUseCase, jobRegistry)
+
+	uploads := filestore.NewLocal(config.GetUploadDir())
+	avatarUseCase := usecase.NewAvatarUseCase(userRepo, uploads)
+	avatarHandler := handler.NewAvatarHandler(avatarUseCase, userCache)
+
+	groupMemberRepo := repository.NewGroupMemberRepository(db)
+	groupUseCase := usecase.NewGroupUseCase(repository.NewGroupRepository(db), groupMemberRepo, userRepo)
+	groupHandler := handler.NewGroupHandler(groupUseCase)
+	userHandler := handler.NewUserHandler(userUseCase, groupUseCase, userCache)
+
+	privacyUseCase := usecase.NewPrivacyUseCase(userRepo, passkeyRepo, groupMemberRepo, preferenceUseCase, uploads)
+	privacyHandler := handler.NewPrivacyHandler(privacyUseCase, userUseCase, jobRegistry, userCache)
+
+	notifications := rabbitmq.NewPublisher(ch, config.GetNotificationQueue())
+	emailChangeUseCase := usecase.NewEmailChangeUseCase(userRepo, storage.RediStorage, notifications)
+	emailChangeHandler := handler.NewEmailChangeHandler(emailChangeUseCase, userCache)
+
+	phoneVerificationUseCase := usecase.NewPhoneVerificationUseCase(userRepo, counters, newSMSSender())
+	phoneVerificationHandler := handler.NewPhoneVerificationHandler(phoneVerificationUseCase, userCache)
+
+	// File upload lokal (avatar); di production bisa dilayani CDN lewat UPLOAD_BASE_URL
+	app.Static("/uploads", config.GetUploadDir())
 
 	api := app.Group("/api/v1")
 	api.Get("/healty", func(c *fiber.Ctx) error { return c.SendString("healty is good!!") })
@@ -25,11 +85,78 @@ func SetupRoutes(app *fiber.App, db *gorm.DB, ch *amqp.Channel) {
 	api.Post("/auth/register", authHandler.Register)
 	api.Post("/auth/login", authHandler.Login)
 	api.Post("/auth/logout", authHandler.Logout)
-	api.Post("/auth/refresh-token", middleware.JwtProtected(), authHandler.RefreshToken)
+	api.Post("/auth/refresh-token", middleware.JwtProtected(), loadUser, authHandler.RefreshToken)
+	api.Post("/auth/reauthenticate", middleware.JwtProtected(), loadUser, authHandler.Reauthenticate)
+
+	api.Post("/auth/email-change/confirm", emailChangeHandler.Confirm)
+	api.Post("/auth/email-change/revert", emailChangeHandler.Revert)
+
+	// Passkey opsional seperti LDAP: endpoint hanya ada jika WEBAUTHN_RP_ID dan WEBAUTHN_RP_ORIGINS di-set
+	if webauthnConfig, ok := config.GetWebAuthnConfig(); ok {
+		passkeyUseCase := usecase.NewPasskeyUseCase(passkeyRepo, userRepo, config.InitWebAuthn(webauthnConfig), storage.RediStorage)
+		passkeyHandler := handler.NewPasskeyHandler(passkeyUseCase)
+
+		api.Post("/auth/passkeys/login/begin", passkeyHandler.BeginLogin)
+		api.Post("/auth/passkeys/login/finish", passkeyHandler.FinishLogin)
+		api.Post("/auth/passkeys/register/begin", middleware.JwtProtected(), middleware.RequireRecentAuth(middleware.DefaultRecentAuthMaxAge), loadUser, passkeyHandler.BeginRegistration)
+		api.Post("/auth/passkeys/register/finish", middleware.JwtProtected(), loadUser, passkeyHandler.FinishRegistration)
+		api.Get("/users/me/passkeys", middleware.JwtProtected(), loadUser, passkeyHandler.List)
+		api.Patch("/users/me/passkeys/:id", middleware.JwtProtected(), middleware.RequireIfMatch(), loadUser, passkeyHandler.Rename)
+		api.Delete("/users/me/passkeys/:id", middleware.JwtProtected(), middleware.RequireRecentAuth(middleware.DefaultRecentAuthMaxAge), loadUser, passkeyHandler.Delete)
+	}
+
+	api.Get("/users", middleware.JwtProtected(), loadUser, userHandler.All)
+	api.Get("/users/search", middleware.JwtProtected(), loadUser, userHandler.Searching)
+	api.Get("/users/export", middleware.JwtProtected(), loadUser, middleware.RequireRole(domain.RoleAdmin), userExportHandler.Export)
+	api.Post("/users/import", middleware.JwtProtected(), loadUser, middleware.RequireRole(domain.RoleAdmin), userImportHandler.Import)
+	api.Get("/users/trash", middleware.JwtProtected(), loadUser, middleware.RequireRole(domain.RoleAdmin), userHandler.Trashed)
+	api.Get("/users/me", middleware.JwtProtected(), loadUser, userHandler.Me)
+	api.Patch("/users/me", middleware.JwtProtected(), middleware.RequireIfMatch(), loadUser, userHandler.UpdateMe)
+	api.Post("/users/me/email", middleware.JwtProtected(), middleware.RequireRecentAuth(middleware.DefaultRecentAuthMaxAge), loadUser, emailChangeHandler.Request)
+	api.Post("/users/me/data-export", middleware.JwtProtected(), loadUser, privacyHandler.DataExport)
+	api.Post("/users/me/phone/verification", middleware.JwtProtected(), loadUser, phoneVerificationHandler.Send)
+	api.Post("/users/me/phone/verify", middleware.JwtProtected(), loadUser, phoneVerificationHandler.Verify)
+	api.Post("/users/me/totp", middleware.JwtProtected(), middleware.RequireRecentAuth(middleware.DefaultRecentAuthMaxAge), loadUser, totpHandler.Enroll)
+	api.Post("/users/me/totp/confirm", middleware.JwtProtected(), loadUser, totpHandler.Confirm)
+	api.Delete("/users/me/totp", middleware.JwtProtected(), middleware.RequireRecentAuth(middleware.DefaultRecentAuthMaxAge), loadUser, totpHandler.Disable)
+	api.Get("/users/me/groups", middleware.JwtProtected(), loadUser, groupHandler.MyGroups)
+	api.Get("/users/me/preferences", middleware.JwtProtected(), loadUser, preferenceHandler.Get)
+	api.Patch("/users/me/preferences", middleware.JwtProtected(), middleware.RequireIfMatch(), loadUser, preferenceHandler.Patch)
+	api.Put("/users/me/avatar", middleware.JwtProtected(), loadUser, avatarHandler.Upload)
+	api.Delete("/users/me/avatar", middleware.JwtProtected(), loadUser, avatarHandler.Delete)
+	api.Get("/users/:id", middleware.JwtProtected(), loadUser, userHandler.Detail)
+	api.Put("/users/:id", middleware.JwtProtected(), middleware.RequireIfMatch(), loadUser, userHandler.Update)
+	api.Patch("/users/:id", middleware.JwtProtected(), middleware.RequireIfMatch(), loadUser, userHandler.Patch)
+	api.Delete("/users/:id", middleware.JwtProtected(), middleware.RequireRecentAuth(middleware.DefaultRecentAuthMaxAge), loadUser, userHandler.Delete)
+	api.Get("/users/:id/groups", middleware.JwtProtected(), loadUser, groupHandler.UserGroups)
+	api.Post("/users/:id/activate", middleware.JwtProtected(), loadUser, middleware.RequireRole(domain.RoleAdmin), userHandler.Activate)
+	api.Post("/users/:id/deactivate", middleware.JwtProtected(), loadUser, middleware.RequireRole(domain.RoleAdmin), userHandler.Deactivate)
+	api.Post("/users/:id/restore", middleware.JwtProtected(), loadUser, middleware.RequireRole(domain.RoleAdmin), userHandler.Restore)
+	api.Post("/users/:id/erase", middleware.JwtProtected(), middleware.RequireRecentAuth(middleware.DefaultRecentAuthMaxAge), loadUser, middleware.RequireRole(domain.RoleAdmin), privacyHandler.Erase)
+	api.Delete("/users/:id/purge", middleware.JwtProtected(), middleware.RequireRecentAuth(middleware.DefaultRecentAuthMaxAge), loadUser, middleware.RequireRole(domain.RoleAdmin), userHandler.Purge)
 
-	api.Get("/users", middleware.JwtProtected(), userHandler.All)
-	api.Get("/users/search", middleware.JwtProtected(), userHandler.Searching)
-	api.Get("/users/:id", middleware.JwtProtected(), userHandler.Detail)
+	api.Get("/groups", middleware.JwtProtected(), loadUser, groupHandler.List)
+	api.Post("/groups", middleware.JwtProtected(), loadUser, groupHandler.Create)
+	api.Get("/groups/:id", middleware.JwtProtected(), loadUser, groupHandler.Detail)
+	api.Patch("/groups/:id", middleware.JwtProtected(), middleware.RequireIfMatch(), loadUser, groupHandler.Update)
+	api.Delete("/groups/:id", middleware.JwtProtected(), loadUser, groupHandler.Delete)
+	api.Get("/groups/:id/members", middleware.JwtProtected(), loadUser, groupHandler.Members)
+	api.Put("/groups/:id/members/:userId", middleware.JwtProtected(), middleware.RequireIfMatch(), loadUser, groupHandler.SetMember)
+	api.Delete("/groups/:id/members/:userId", middleware.JwtProtected(), loadUser, groupHandler.RemoveMember)
+
+	api.Get("/jobs/:id", middleware.JwtProtected(), loadUser, jobHandler.Status)
+	api.Get("/jobs/:id/download", middleware.JwtProtected(), loadUser, jobHandler.Download)
+
+	// SCIM 2.0 provisioning, diautentikasi dengan bearer token per tenant
+	scimApi := app.Group("/scim/v2", middleware.ScimAuth())
+	scimApi.Get("/ServiceProviderConfig", scimHandler.ServiceProviderConfig)
+	scimApi.Get("/ResourceTypes", scimHandler.ResourceTypes)
+	scimApi.Get("/Users", scimHandler.ListUsers)
+	scimApi.Post("/Users", scimHandler.CreateUser)
+	scimApi.Get("/Users/:id", scimHandler.GetUser)
+	scimApi.Put("/Users/:id", scimHandler.ReplaceUser)
+	scimApi.Patch("/Users/:id", scimHandler.PatchUser)
+	scimApi.Delete("/Users/:id", scimHandler.DeleteUser)
 
 	// Example publish
 	api.Post("/publish", func(c *fiber.Ctx) error {
@@ -62,3 +189,17 @@ func SetupRoutes(app *fiber.App, db *gorm.DB, ch *amqp.Channel) {
 	})
 
 }
+
+// newSMSSender memakai LogSender (stdout atau SMS_LOG_FILE) sampai gateway SMS tersedia
+func newSMSSender() usecase.SMSSender {
+	path := config.GetSMSLogFile()
+	if path == "" {
+		return sms.NewLogSender(os.Stdout)
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		log.Fatalf("Failed to open SMS log file: %v", err)
+	}
+	return sms.NewLogSender(file)
+}